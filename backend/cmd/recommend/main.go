@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"gomall/backend/internal/config"
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/redis"
+	"gomall/backend/internal/service"
+)
+
+// 离线计算"经常一起购买"推荐，结果写入 product_recommendations
+// 服务运行时每天凌晨也会自动计算一次，这里用于首次上线或手动补算：
+//
+//	go run ./cmd/recommend -config conf/config-dev.yaml
+func main() {
+	configPath := flag.String("config", "conf/config-dev.yaml", "配置文件路径")
+	flag.Parse()
+
+	if err := config.Init(*configPath); err != nil {
+		log.Fatalf("Failed to init config: %v", err)
+	}
+	if err := logger.Init(); err != nil {
+		log.Fatalf("Failed to init logger: %v", err)
+	}
+	defer logger.Sync()
+
+	if err := database.Init(); err != nil {
+		log.Fatalf("Failed to init database: %v", err)
+	}
+	defer database.Close()
+
+	// Redis 只用于与运行中的服务互斥，不可用时直接计算
+	if err := redis.Init(); err != nil {
+		log.Printf("Redis unavailable, computing without lock: %v", err)
+	} else {
+		defer redis.Close()
+	}
+
+	result, err := service.NewRecommendService().Compute(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to compute recommendations: %v", err)
+	}
+	if result == nil {
+		log.Println("Another instance is computing recommendations, skipped")
+		return
+	}
+	log.Printf("Recommendations computed: baskets=%d pairs=%d recommendations=%d",
+		result.Baskets, result.Pairs, result.Recommendations)
+}
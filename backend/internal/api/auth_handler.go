@@ -1,7 +1,12 @@
 package api
 
 import (
+	"errors"
+	"net/http"
+
+	"gomall/backend/internal/middleware"
 	"gomall/backend/internal/repository"
+	"gomall/backend/internal/service"
 	"gomall/backend/pkg/jwt"
 	"gomall/backend/pkg/password"
 
@@ -11,13 +16,15 @@ import (
 
 // AuthHandler 认证接口处理层
 type AuthHandler struct {
-	userRepo *repository.UserRepository
+	userRepo     *repository.UserRepository
+	emailService *service.EmailService
 }
 
 // NewAuthHandler 创建认证处理器
 func NewAuthHandler() *AuthHandler {
 	return &AuthHandler{
-		userRepo: repository.NewUserRepository(),
+		userRepo:     repository.NewUserRepository(),
+		emailService: service.NewEmailService(),
 	}
 }
 
@@ -28,7 +35,7 @@ type RefreshTokenRequest struct {
 
 // RefreshToken 刷新Token
 // @Summary 刷新Token
-// @Description 使用refresh_token刷新访问令牌
+// @Description 使用refresh_token换发新的访问令牌和刷新令牌，旧的刷新令牌随即失效；已使用过的刷新令牌再次提交时，本次登录的全部刷新令牌都会被吊销
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -42,21 +49,28 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	jwtUtil := jwt.NewJWT()
-	claims, err := jwtUtil.ParseToken(req.RefreshToken)
+	tokenPair, err := service.RotateRefreshToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
-		response.Unauthorized(c, "无效的刷新令牌")
+		switch {
+		case errors.Is(err, service.ErrRefreshTokenInvalid), errors.Is(err, service.ErrRefreshTokenReused):
+			response.Unauthorized(c, err.Error())
+		case errors.Is(err, service.ErrUserDisabled):
+			response.FailWithMsg(c, response.CodeUserDisabled, err.Error())
+		case errors.Is(err, service.ErrPasswordResetRequired):
+			response.FailWithMsg(c, response.CodeUserAccountLocked, err.Error())
+		case errors.Is(err, service.ErrRefreshUnavailable):
+			response.FailWithMsg(c, response.CodeServiceUnavailable, err.Error())
+		default:
+			response.ServerError(c, "Token刷新失败")
+		}
 		return
 	}
 
-	// 生成新的Token
-	newToken, err := jwtUtil.GenerateToken(claims.UserID, claims.Username, claims.Email)
-	if err != nil {
-		response.ServerError(c, "Token生成失败")
-		return
-	}
-
-	response.OkWithData(c, gin.H{"token": newToken})
+	response.OkWithData(c, gin.H{
+		"token":         tokenPair.AccessToken,
+		"refresh_token": tokenPair.RefreshToken,
+		"expires_in":    tokenPair.ExpiresIn,
+	})
 }
 
 // ChangePasswordRequest 修改密码请求结构
@@ -67,7 +81,7 @@ type ChangePasswordRequest struct {
 
 // ChangePassword 修改密码
 // @Summary 修改密码
-// @Description 用户修改自己的密码
+// @Description 用户修改自己的密码，修改后所有设备上的 Token 失效，响应中返回当前设备的新 Token
 // @Tags 认证
 // @Accept json
 // @Produce json
@@ -114,28 +128,227 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	}
 
 	user.Password = newHashedPassword
-	if err := h.userRepo.Update(user); err != nil {
+	if err := h.userRepo.UpdatePassword(user); err != nil {
 		response.ServerError(c, "密码修改失败")
 		return
 	}
 
+	// 使所有设备上的 Token 失效，并为当前设备签发新 Token 对
+	user.TokenVersion, err = service.RevokeAllTokens(c.Request.Context(), userID)
+	if err != nil {
+		response.ServerError(c, "密码已修改，Token吊销失败，请重试")
+		return
+	}
+	// 当前设备已通过的两步验证保持有效
+	claims := middleware.GetClaims(c)
+	tokenPair, err := service.IssueTokenPair(c.Request.Context(), user, claims != nil && claims.MFA, clientInfo(c))
+	if err != nil {
+		response.ServerError(c, "Token生成失败")
+		return
+	}
+
+	response.OkWithData(c, gin.H{
+		"token":         tokenPair.AccessToken,
+		"refresh_token": tokenPair.RefreshToken,
+		"expires_in":    tokenPair.ExpiresIn,
+	})
+}
+
+// SendVerificationEmail 发送邮箱验证邮件
+// @Summary 发送邮箱验证邮件
+// @Description 向当前用户的邮箱发送验证链接，同一用户每分钟最多发送一次
+// @Tags 认证
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/auth/email/verification [post]
+func (h *AuthHandler) SendVerificationEmail(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Unauthorized(c, "未登录")
+		return
+	}
+
+	if err := h.emailService.SendVerification(c.Request.Context(), userID); err != nil {
+		h.emailFail(c, err, "验证邮件发送失败")
+		return
+	}
+
+	response.Ok(c)
+}
+
+// VerifyEmail 验证邮箱
+// @Summary 验证邮箱
+// @Description 提交邮件链接中的 token 完成邮箱验证，链接只能使用一次
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param req body service.VerifyEmailRequest true "验证Token"
+// @Success 200 {object} response.Response
+// @Router /api/auth/email/verify [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req service.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.emailService.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		h.emailFail(c, err, "邮箱验证失败")
+		return
+	}
+
+	response.Ok(c)
+}
+
+// ForgotPassword 找回密码
+// @Summary 找回密码
+// @Description 向邮箱发送重置密码链接；无论邮箱是否注册都返回成功
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param req body service.ForgotPasswordRequest true "注册邮箱"
+// @Success 200 {object} response.Response
+// @Router /api/auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req service.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "邮箱格式不正确")
+		return
+	}
+
+	h.emailService.ForgotPassword(req.Email)
 	response.Ok(c)
 }
 
+// ResetPassword 重置密码
+// @Summary 重置密码
+// @Description 提交邮件链接中的 token 和新密码，链接只能使用一次；重置后所有设备上的 Token 失效，登录锁定解除
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param req body service.ResetPasswordRequest true "重置信息"
+// @Success 200 {object} response.Response
+// @Router /api/auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req service.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.emailService.ResetPassword(c.Request.Context(), &req); err != nil {
+		h.emailFail(c, err, "密码重置失败")
+		return
+	}
+
+	response.Ok(c)
+}
+
+// emailFail 统一处理邮箱验证与找回密码接口错误
+func (h *AuthHandler) emailFail(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrActionTokenInvalid), errors.Is(err, service.ErrActionTokenExpired):
+		response.FailWithMsg(c, response.CodeUserLinkInvalid, err.Error())
+	case errors.Is(err, service.ErrEmailNotSet):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, service.ErrEmailAlreadyVerified):
+		response.FailWithMsg(c, response.CodeConflict, err.Error())
+	case errors.Is(err, service.ErrMailTooFrequent):
+		response.FailWithMsg(c, response.CodeTooManyRequests, err.Error())
+	case errors.Is(err, service.ErrMailSendFailed):
+		response.FailWithMsg(c, response.CodeUserMailFailed, err.Error())
+	case errors.Is(err, repository.ErrUserNotFound):
+		response.NotFound(c, "用户不存在")
+	default:
+		response.ServerError(c, msg)
+	}
+}
+
+// JWKS 公钥集合
+// @Summary JWT公钥集合
+// @Description 发布验签用的公钥（JWKS），其他服务按 Token 头部的 kid 选择公钥验签；使用 HS256 时返回空集合
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} jwt.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	jwks, err := jwt.PublicJWKS()
+	if err != nil {
+		response.ServerError(c, "公钥加载失败")
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
+
+// LogoutRequest 退出登录请求结构
+type LogoutRequest struct {
+	// RefreshToken 可选，传入时吊销本次登录的全部刷新令牌
+	RefreshToken string `json:"refresh_token"`
+}
+
 // Logout 退出登录
 // @Summary 退出登录
-// @Description 用户退出登录（客户端清除Token即可，服务端无需处理）
+// @Description 吊销当前访问令牌并结束当前登录会话（本次登录的刷新令牌一并失效）；请求体中的 refresh_token 兼容旧版本客户端
 // @Tags 认证
+// @Accept json
 // @Produce json
+// @Param req body LogoutRequest false "刷新令牌（可选）"
 // @Security Bearer
 // @Success 200 {object} response.Response
 // @Router /api/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
-	userID := c.GetUint("user_id")
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		response.Unauthorized(c, "未登录")
+		return
+	}
+
+	// 请求体可选，解析失败时只吊销访问令牌
+	var req LogoutRequest
+	_ = c.ShouldBindJSON(&req)
+
+	if err := service.RevokeToken(c.Request.Context(), claims); err != nil {
+		response.FailWithMsg(c, response.CodeServiceUnavailable, "退出登录失败，请稍后重试")
+		return
+	}
+	if err := service.EndSession(c.Request.Context(), claims.Family); err != nil {
+		response.FailWithMsg(c, response.CodeServiceUnavailable, "退出登录失败，请稍后重试")
+		return
+	}
+
+	// 只吊销属于当前用户的刷新令牌，无效的刷新令牌直接忽略
+	if req.RefreshToken != "" {
+		if err := service.RevokeRefreshToken(c.Request.Context(), claims.UserID, req.RefreshToken); err != nil {
+			response.FailWithMsg(c, response.CodeServiceUnavailable, "退出登录失败，请稍后重试")
+			return
+		}
+	}
+
+	response.Ok(c)
+}
+
+// LogoutAll 退出所有设备
+// @Summary 退出所有设备
+// @Description 使当前用户已签发的全部 Token（包括当前 Token）失效
+// @Tags 认证
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID := middleware.GetUserID(c)
 	if userID == 0 {
 		response.Unauthorized(c, "未登录")
 		return
 	}
 
+	if _, err := service.RevokeAllTokens(c.Request.Context(), userID); err != nil {
+		response.ServerError(c, "退出登录失败")
+		return
+	}
+
 	response.Ok(c)
 }
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"gomall/backend/internal/middleware"
+	"gomall/backend/internal/repository"
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FavoriteHandler 收藏处理器
+type FavoriteHandler struct {
+	favoriteService *service.FavoriteService
+}
+
+// NewFavoriteHandler 创建收藏处理器
+func NewFavoriteHandler() *FavoriteHandler {
+	return &FavoriteHandler{
+		favoriteService: service.NewFavoriteService(),
+	}
+}
+
+// Add 收藏商品
+// @Summary 收藏商品
+// @Description 将商品加入当前用户的收藏，重复收藏不报错
+// @Tags 用户收藏
+// @Accept json
+// @Produce json
+// @Param req body service.AddFavoriteRequest true "商品ID"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/favorites [post]
+func (h *FavoriteHandler) Add(c *gin.Context) {
+	var req service.AddFavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.favoriteService.Add(middleware.GetUserID(c), &req); err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			response.FailWithMsg(c, response.CodeProductNotFound, err.Error())
+			return
+		}
+		response.FailWithMsg(c, response.CodeFavoriteFailed, "收藏失败")
+		return
+	}
+
+	response.Ok(c)
+}
+
+// Remove 取消收藏
+// @Summary 取消收藏
+// @Description 将商品移出当前用户的收藏，未收藏时不报错
+// @Tags 用户收藏
+// @Produce json
+// @Param product_id path int true "商品ID"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/favorites/{product_id} [delete]
+func (h *FavoriteHandler) Remove(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("product_id"), 10, 64)
+	if err != nil || productID == 0 {
+		response.BadRequest(c, "商品ID错误")
+		return
+	}
+
+	if err := h.favoriteService.Remove(middleware.GetUserID(c), uint(productID)); err != nil {
+		response.FailWithMsg(c, response.CodeFavoriteFailed, "取消收藏失败")
+		return
+	}
+
+	response.Ok(c)
+}
+
+// List 获取收藏列表
+// @Summary 获取收藏列表
+// @Description 分页获取当前用户的收藏，包含商品实时价格、库存和是否已下架
+// @Tags 用户收藏
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/favorites [get]
+func (h *FavoriteHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	items, total, err := h.favoriteService.List(middleware.GetUserID(c), page, pageSize)
+	if err != nil {
+		response.FailWithMsg(c, response.CodeFavoriteFailed, "获取收藏列表失败")
+		return
+	}
+
+	response.OkWithList(c, items, total, page, pageSize)
+}
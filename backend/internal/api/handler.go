@@ -1,12 +1,14 @@
 package api
 
 import (
+	"errors"
+	"math"
 	"strconv"
 
 	"gomall/backend/internal/middleware"
+	"gomall/backend/internal/model"
 	"gomall/backend/internal/response"
 	"gomall/backend/internal/service"
-	"gomall/backend/pkg/jwt"
 
 	"github.com/gin-gonic/gin"
 )
@@ -40,28 +42,38 @@ func (h *UserHandler) Register(c *gin.Context) {
 	}
 
 	user, err := h.userService.Register(&req)
+	if errors.Is(err, service.ErrUsernameReserved) {
+		response.BadRequest(c, err.Error())
+		return
+	}
 	if err != nil {
 		response.FailWithMsg(c, response.CodeUserAlreadyExist, err.Error())
 		return
 	}
 
-	// 注册成功后生成 token
-	jwtUtil := jwt.NewJWT()
-	token, err := jwtUtil.GenerateToken(user.ID, user.Username, user.Email)
+	// 注册成功后生成 token，新用户的 Token 版本为 0
+	tokenPair, err := service.IssueTokenPair(c.Request.Context(), &model.User{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     user.Role,
+	}, false, clientInfo(c))
 	if err != nil {
 		response.FailWithMsg(c, response.CodeServerError, "Token生成失败")
 		return
 	}
 
 	response.OkWithData(c, gin.H{
-		"token": token,
-		"user":  user,
+		"token":         tokenPair.AccessToken,
+		"refresh_token": tokenPair.RefreshToken,
+		"expires_in":    tokenPair.ExpiresIn,
+		"user":          user,
 	})
 }
 
 // Login 用户登录
 // @Summary 用户登录
-// @Description 用户登录获取Token
+// @Description 用户登录获取Token；账号启用了两步验证时返回 two_factor_required 和预认证Token，需调用 /api/user/login/2fa 完成登录。同一账号或IP连续登录失败次数过多时暂时锁定，锁定期间返回 10011（账号）或 429（IP）及 Retry-After 头
 // @Tags 用户
 // @Accept json
 // @Produce json
@@ -75,16 +87,30 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, user, err := h.userService.Login(&req)
+	result, err := h.userService.Login(c.Request.Context(), &req, clientInfo(c))
 	if err != nil {
-		response.FailWithMsg(c, response.CodeUserPasswordError, err.Error())
-		return
-	}
-
-	response.OkWithData(c, gin.H{
-		"token": token,
-		"user":  user,
-	})
+		var lockedErr *service.LoginLockedError
+		if errors.As(err, &lockedErr) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+			if errors.Is(err, service.ErrAccountLocked) {
+				response.FailWithMsg(c, response.CodeUserAccountLocked, err.Error())
+			} else {
+				response.FailWithMsg(c, response.CodeTooManyRequests, err.Error())
+			}
+			return
+		}
+		switch {
+		case errors.Is(err, service.ErrUserDisabled):
+			response.FailWithMsg(c, response.CodeUserDisabled, err.Error())
+		case errors.Is(err, service.ErrPasswordResetRequired):
+			response.FailWithMsg(c, response.CodeUserAccountLocked, err.Error())
+		default:
+			response.FailWithMsg(c, response.CodeUserPasswordError, err.Error())
+		}
+		return
+	}
+
+	loginResponse(c, result, nil)
 }
 
 // GetProfile 获取当前用户信息
@@ -113,13 +139,19 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 
 // ProductHandler 商品接口处理层
 type ProductHandler struct {
-	productService *service.ProductService
+	productService   *service.ProductService
+	favoriteService  *service.FavoriteService
+	historyService   *service.HistoryService
+	recommendService *service.RecommendService
 }
 
 // NewProductHandler 创建商品处理器
 func NewProductHandler() *ProductHandler {
 	return &ProductHandler{
-		productService: service.NewProductService(),
+		productService:   service.NewProductService(),
+		favoriteService:  service.NewFavoriteService(),
+		historyService:   service.NewHistoryService(),
+		recommendService: service.NewRecommendService(),
 	}
 }
 
@@ -151,18 +183,20 @@ func (h *ProductHandler) Create(c *gin.Context) {
 
 // List 获取商品列表
 // @Summary 获取商品列表
-// @Description 获取商品列表，支持分页和分类筛选
+// @Description 获取商品列表，支持分页、分类和店铺筛选
 // @Tags 商品
 // @Produce json
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(10)
 // @Param category query string false "商品分类"
+// @Param shop_id query int false "店铺ID"
 // @Success 200 {object} response.Response
 // @Router /api/product [get]
 func (h *ProductHandler) List(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 	category := c.Query("category")
+	shopID, _ := strconv.ParseUint(c.Query("shop_id"), 10, 64)
 
 	if page < 1 {
 		page = 1
@@ -171,14 +205,14 @@ func (h *ProductHandler) List(c *gin.Context) {
 		pageSize = 10
 	}
 
-	products, total := h.productService.GetList(page, pageSize, category)
+	products, total := h.productService.GetList(page, pageSize, category, uint(shopID))
 
 	response.OkWithList(c, products, int64(total), page, pageSize)
 }
 
 // Get 获取商品详情
 // @Summary 获取商品详情
-// @Description 根据ID获取商品详情
+// @Description 根据ID获取商品详情，登录用户额外返回是否已收藏并记录浏览记录
 // @Tags 商品
 // @Produce json
 // @Param id path int true "商品ID"
@@ -187,12 +221,26 @@ func (h *ProductHandler) List(c *gin.Context) {
 func (h *ProductHandler) Get(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 
+	// 布隆过滤器拦截不存在的商品ID，不查缓存和数据库
+	if !h.productService.MightExist(c.Request.Context(), uint(id)) {
+		response.FailWithMsg(c, response.CodeProductNotFound, "商品不存在")
+		return
+	}
+
 	product, err := h.productService.GetByID(uint(id))
 	if err != nil {
 		response.FailWithMsg(c, response.CodeProductNotFound, err.Error())
 		return
 	}
 
+	// 可选认证：登录用户返回收藏状态并写入浏览记录，游客只计入浏览量
+	userID := middleware.GetUserID(c)
+	if userID != 0 {
+		isFavorite := h.favoriteService.IsFavorite(userID, product.ID)
+		product.IsFavorite = &isFavorite
+	}
+	h.historyService.RecordView(c.Request.Context(), userID, product.ID, product.Category)
+
 	response.OkWithData(c, product)
 }
 
@@ -216,7 +264,7 @@ func (h *ProductHandler) Update(c *gin.Context) {
 		return
 	}
 
-	if err := h.productService.Update(uint(id), &req); err != nil {
+	if err := h.productService.Update(uint(id), middleware.GetUserID(c), &req); err != nil {
 		response.FailWithMsg(c, response.CodeProductUpdateFailed, err.Error())
 		return
 	}
@@ -290,7 +338,7 @@ func (h *OrderHandler) Create(c *gin.Context) {
 
 // List 获取订单列表
 // @Summary 获取订单列表
-// @Description 获取当前用户的订单列表
+// @Description 获取当前用户的订单列表，购物车结算的店铺子订单在合并订单详情中返回
 // @Tags 订单
 // @Produce json
 // @Param page query int false "页码" default(1)
@@ -322,7 +370,7 @@ func (h *OrderHandler) List(c *gin.Context) {
 
 // Get 获取订单详情
 // @Summary 获取订单详情
-// @Description 根据订单号获取订单详情
+// @Description 根据订单号获取订单详情；合并订单返回 children 子订单，店铺子订单返回 items 商品明细和 parent_order_no
 // @Tags 订单
 // @Produce json
 // @Param order_no path string true "订单号"
@@ -343,7 +391,7 @@ func (h *OrderHandler) Get(c *gin.Context) {
 
 // Pay 支付订单
 // @Summary 支付订单
-// @Description 模拟支付订单
+// @Description 模拟支付订单；合并订单支付后店铺子订单一起变为已支付，子订单不能单独支付
 // @Tags 订单
 // @Produce json
 // @Param order_no path string true "订单号"
@@ -363,7 +411,7 @@ func (h *OrderHandler) Pay(c *gin.Context) {
 
 // Cancel 取消订单
 // @Summary 取消订单
-// @Description 取消未支付的订单
+// @Description 取消未支付的订单；合并订单取消时店铺子订单一起取消，子订单不能单独取消
 // @Tags 订单
 // @Produce json
 // @Param order_no path string true "订单号"
@@ -383,7 +431,7 @@ func (h *OrderHandler) Cancel(c *gin.Context) {
 
 // Checkout 购物车结算
 // @Summary 购物车结算
-// @Description 将购物车中的商品结算为订单
+// @Description 将购物车中的商品结算为订单：返回用于支付的合并订单，children 为按店铺拆分的子订单（含商品明细）
 // @Tags 订单
 // @Produce json
 // @Security Bearer
@@ -396,13 +444,13 @@ func (h *OrderHandler) Checkout(c *gin.Context) {
 		return
 	}
 
-	orders, err := h.orderService.Checkout(userID)
+	order, err := h.orderService.Checkout(userID)
 	if err != nil {
 		response.FailWithMsg(c, response.CodeOrderCreateFailed, err.Error())
 		return
 	}
 
-	response.OkWithData(c, orders)
+	response.OkWithData(c, order)
 }
 
 // CartHandler 购物车接口处理层
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"strconv"
+
+	"gomall/backend/internal/middleware"
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HistoryHandler 浏览记录处理器
+type HistoryHandler struct {
+	historyService *service.HistoryService
+}
+
+// NewHistoryHandler 创建浏览记录处理器
+func NewHistoryHandler() *HistoryHandler {
+	return &HistoryHandler{
+		historyService: service.NewHistoryService(),
+	}
+}
+
+// List 获取浏览记录
+// @Summary 获取浏览记录
+// @Description 分页获取当前用户最近浏览的商品（按浏览时间倒序），包含商品实时价格和库存
+// @Tags 浏览记录
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/history [get]
+func (h *HistoryHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	items, total, err := h.historyService.List(c.Request.Context(), middleware.GetUserID(c), page, pageSize)
+	if err != nil {
+		response.FailWithMsg(c, response.CodeHistoryFailed, "获取浏览记录失败")
+		return
+	}
+
+	response.OkWithList(c, items, total, page, pageSize)
+}
+
+// Clear 清空浏览记录
+// @Summary 清空浏览记录
+// @Description 清空当前用户的浏览记录；传入 product_id 时只删除该商品
+// @Tags 浏览记录
+// @Produce json
+// @Param product_id query int false "商品ID"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/history [delete]
+func (h *HistoryHandler) Clear(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+
+	var err error
+	if productIDStr := c.Query("product_id"); productIDStr != "" {
+		productID, parseErr := strconv.ParseUint(productIDStr, 10, 64)
+		if parseErr != nil || productID == 0 {
+			response.BadRequest(c, "商品ID错误")
+			return
+		}
+		err = h.historyService.Remove(c.Request.Context(), userID, uint(productID))
+	} else {
+		err = h.historyService.Clear(c.Request.Context(), userID)
+	}
+	if err != nil {
+		response.FailWithMsg(c, response.CodeHistoryFailed, "删除浏览记录失败")
+		return
+	}
+
+	response.Ok(c)
+}
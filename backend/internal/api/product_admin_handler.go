@@ -0,0 +1,155 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"gomall/backend/internal/middleware"
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize 导入文件大小上限（10MB）
+const maxImportFileSize = 10 << 20
+
+// RebuildBloom 重建商品布隆过滤器
+// @Summary 重建商品布隆过滤器
+// @Description 根据商品表全量重建布隆过滤器（需要管理员权限）
+// @Tags 管理后台
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/products/bloom/rebuild [post]
+func (h *ProductHandler) RebuildBloom(c *gin.Context) {
+	count, err := h.productService.RebuildBloom(c.Request.Context())
+	if err != nil {
+		response.FailWithMsg(c, response.CodeServerError, "重建布隆过滤器失败: "+err.Error())
+		return
+	}
+
+	response.OkWithData(c, gin.H{"count": count})
+}
+
+// Import 批量导入商品
+// @Summary 批量导入商品
+// @Description 上传 CSV 或 JSON 文件，按 SKU 新增或更新商品，返回逐行错误报告（需要管理员权限）
+// @Tags 管理后台
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "商品文件（.csv / .json）"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.ProductImportResult}
+// @Router /api/admin/products/import [post]
+func (h *ProductHandler) Import(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "请选择要导入的文件")
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		response.BadRequest(c, "文件大小不能超过 10MB")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.FailWithMsg(c, response.CodeProductImportFailed, "读取文件失败")
+		return
+	}
+	defer file.Close()
+
+	rows, rowErrors, err := service.ParseProductImportFile(fileHeader.Filename, file)
+	if err != nil {
+		response.FailWithMsg(c, response.CodeProductImportFailed, err.Error())
+		return
+	}
+
+	// 逐行校验，校验失败的行不参与写入
+	validate := middleware.CustomValidator()
+	validRows := make([]service.ProductImportRow, 0, len(rows))
+	for i := range rows {
+		if err := validate.Struct(&rows[i]); err != nil {
+			rowErrors = append(rowErrors, service.ProductImportError{
+				Row:     rows[i].Row,
+				SKU:     rows[i].SKU,
+				Message: middleware.FormatValidationError(err),
+			})
+			continue
+		}
+		validRows = append(validRows, rows[i])
+	}
+
+	response.OkWithData(c, h.productService.ImportProducts(middleware.GetUserID(c), validRows, rowErrors))
+}
+
+// Export 导出商品
+// @Summary 导出商品
+// @Description 按筛选条件导出商品 CSV，格式与导入文件一致（需要管理员权限）
+// @Tags 管理后台
+// @Produce text/csv
+// @Param category query string false "商品分类"
+// @Param status query int false "商品状态（1上架 0下架）"
+// @Param keyword query string false "名称或SKU关键字"
+// @Security Bearer
+// @Success 200 {file} file
+// @Router /api/admin/products/export [get]
+func (h *ProductHandler) Export(c *gin.Context) {
+	filter := service.ProductExportFilter{
+		Category: c.Query("category"),
+		Keyword:  c.Query("keyword"),
+	}
+	if v := c.Query("status"); v != "" {
+		status, err := strconv.Atoi(v)
+		if err != nil {
+			response.BadRequest(c, "status 参数错误")
+			return
+		}
+		filter.Status = &status
+	}
+
+	filename := fmt.Sprintf("products_%s.csv", time.Now().Format("20060102150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	if err := h.productService.ExportProductsCSV(c.Writer, filter); err != nil {
+		// 响应头已发送，只能中断输出并记录错误
+		_ = c.Error(err)
+		if !c.Writer.Written() {
+			response.FailWithMsg(c, response.CodeProductExportFailed, err.Error())
+		}
+	}
+}
+
+// PriceHistory 商品价格变更记录
+// @Summary 商品价格变更记录
+// @Description 分页查询商品的调价记录（需要管理员权限）
+// @Tags 管理后台
+// @Produce json
+// @Param id path int true "商品ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/products/{id}/price-history [get]
+func (h *ProductHandler) PriceHistory(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	records, total, err := h.productService.GetPriceHistory(uint(id), page, pageSize)
+	if err != nil {
+		response.FailWithMsg(c, response.CodeProductNotFound, err.Error())
+		return
+	}
+
+	response.OkWithList(c, records, total, page, pageSize)
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"gomall/backend/internal/repository"
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListImages 获取商品图集
+// @Summary 获取商品图集
+// @Description 获取商品的全部图片（按排序值升序）
+// @Tags 商品
+// @Produce json
+// @Param id path int true "商品ID"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/product/{id}/images [get]
+func (h *ProductHandler) ListImages(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	images, err := h.productService.ListImages(uint(id))
+	if err != nil {
+		response.FailWithMsg(c, response.CodeProductNotFound, err.Error())
+		return
+	}
+
+	response.OkWithData(c, images)
+}
+
+// AddImages 添加商品图片
+// @Summary 添加商品图片
+// @Description 将已上传的图片关联到商品图集（需要管理员权限）
+// @Tags 商品
+// @Accept json
+// @Produce json
+// @Param id path int true "商品ID"
+// @Param req body service.AddProductImagesRequest true "图片地址"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/product/{id}/images [post]
+func (h *ProductHandler) AddImages(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	var req service.AddProductImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	images, err := h.productService.AddImages(uint(id), &req)
+	if err != nil {
+		h.failImage(c, err)
+		return
+	}
+
+	response.OkWithData(c, images)
+}
+
+// SortImages 调整图集顺序
+// @Summary 调整图集顺序
+// @Description 按传入的图片ID顺序重排图集（需要管理员权限）
+// @Tags 商品
+// @Accept json
+// @Produce json
+// @Param id path int true "商品ID"
+// @Param req body service.SortProductImagesRequest true "图片ID顺序"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/product/{id}/images/sort [put]
+func (h *ProductHandler) SortImages(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+
+	var req service.SortProductImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	images, err := h.productService.SortImages(uint(id), &req)
+	if err != nil {
+		h.failImage(c, err)
+		return
+	}
+
+	response.OkWithData(c, images)
+}
+
+// SetMainImage 设置主图
+// @Summary 设置主图
+// @Description 设置商品主图，主图同步到商品的 image_url（需要管理员权限）
+// @Tags 商品
+// @Produce json
+// @Param id path int true "商品ID"
+// @Param image_id path int true "图片ID"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/product/{id}/images/{image_id}/main [put]
+func (h *ProductHandler) SetMainImage(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	imageID, _ := strconv.ParseUint(c.Param("image_id"), 10, 64)
+
+	if err := h.productService.SetMainImage(uint(id), uint(imageID)); err != nil {
+		h.failImage(c, err)
+		return
+	}
+
+	response.Ok(c)
+}
+
+// RemoveImage 删除商品图片
+// @Summary 删除商品图片
+// @Description 从图集中删除图片，删除主图时自动顺延下一张为主图（需要管理员权限）
+// @Tags 商品
+// @Produce json
+// @Param id path int true "商品ID"
+// @Param image_id path int true "图片ID"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/product/{id}/images/{image_id} [delete]
+func (h *ProductHandler) RemoveImage(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	imageID, _ := strconv.ParseUint(c.Param("image_id"), 10, 64)
+
+	if err := h.productService.RemoveImage(uint(id), uint(imageID)); err != nil {
+		h.failImage(c, err)
+		return
+	}
+
+	response.Ok(c)
+}
+
+// failImage 图集接口统一错误响应
+func (h *ProductHandler) failImage(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrProductNotFound):
+		response.FailWithMsg(c, response.CodeProductNotFound, err.Error())
+	case errors.Is(err, repository.ErrProductImageNotFound):
+		response.FailWithMsg(c, response.CodeProductImageNotFound, err.Error())
+	default:
+		response.FailWithMsg(c, response.CodeProductImageFailed, err.Error())
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Rank 商品排行榜
+// @Summary 商品排行榜
+// @Description 按销量或浏览量查询当天/近7天的商品排行，可按分类筛选，已删除和已下架的商品不参与排名
+// @Tags 商品
+// @Produce json
+// @Param type query string false "排行类型 sales|views" default(sales)
+// @Param category query string false "商品分类"
+// @Param period query string false "统计周期 day|week" default(day)
+// @Param limit query int false "返回条数（最多50）" default(10)
+// @Success 200 {object} response.Response{data=[]service.ProductRankResponse}
+// @Router /api/product/rank [get]
+func (h *ProductHandler) Rank(c *gin.Context) {
+	var req service.ProductRankRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	items, err := h.productService.GetRank(c.Request.Context(), &req)
+	if err != nil {
+		response.FailWithMsg(c, response.CodeServiceUnavailable, "获取排行榜失败")
+		return
+	}
+
+	response.OkWithData(c, items)
+}
+
+// RebuildRank 重建商品销量榜
+// @Summary 重建商品销量榜
+// @Description 根据订单表重建最近7天的销量榜（每天凌晨自动执行，需要管理员权限）；正在重建时返回 409
+// @Tags 管理后台
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/products/rank/rebuild [post]
+func (h *ProductHandler) RebuildRank(c *gin.Context) {
+	err := h.productService.RebuildSalesRank(c.Request.Context())
+	if errors.Is(err, service.ErrRankRebuilding) {
+		response.FailWithMsg(c, response.CodeConflict, err.Error())
+		return
+	}
+	if err != nil {
+		response.FailWithMsg(c, response.CodeServerError, "重建销量榜失败: "+err.Error())
+		return
+	}
+
+	response.Ok(c)
+}
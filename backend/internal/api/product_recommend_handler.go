@@ -0,0 +1,59 @@
+package api
+
+import (
+	"strconv"
+
+	"gomall/backend/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRecommendationLimit 推荐接口单次最多返回条数
+const maxRecommendationLimit = 20
+
+// Recommendations 经常一起购买
+// @Summary 经常一起购买
+// @Description 根据订单和购物车的共现关系推荐相关商品，已下架和无库存的商品不返回
+// @Tags 商品
+// @Produce json
+// @Param id path int true "商品ID"
+// @Param limit query int false "返回条数（最多20）" default(10)
+// @Success 200 {object} response.Response{data=[]service.ProductRecommendationResponse}
+// @Router /api/product/{id}/recommendations [get]
+func (h *ProductHandler) Recommendations(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > maxRecommendationLimit {
+		limit = 10
+	}
+
+	items, err := h.recommendService.ListForProduct(uint(id), limit)
+	if err != nil {
+		response.FailWithMsg(c, response.CodeServerError, "获取推荐失败")
+		return
+	}
+
+	response.OkWithData(c, items)
+}
+
+// RecomputeRecommendations 重新计算商品推荐
+// @Summary 重新计算商品推荐
+// @Description 根据订单和购物车重新计算"经常一起购买"（每天凌晨自动执行，需要管理员权限）
+// @Tags 管理后台
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.RecommendResult}
+// @Router /api/admin/products/recommendations/recompute [post]
+func (h *ProductHandler) RecomputeRecommendations(c *gin.Context) {
+	result, err := h.recommendService.Compute(c.Request.Context())
+	if err != nil {
+		response.FailWithMsg(c, response.CodeServerError, "计算商品推荐失败: "+err.Error())
+		return
+	}
+	if result == nil {
+		response.FailWithMsg(c, response.CodeConflict, "商品推荐正在计算中")
+		return
+	}
+
+	response.OkWithData(c, result)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+
+	"gomall/backend/internal/middleware"
+	"gomall/backend/internal/repository"
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileHandler 个人资料处理器
+type ProfileHandler struct {
+	profileService *service.ProfileService
+}
+
+// NewProfileHandler 创建个人资料处理器
+func NewProfileHandler() *ProfileHandler {
+	return &ProfileHandler{
+		profileService: service.NewProfileService(),
+	}
+}
+
+// UpdateProfile 修改个人资料
+// @Summary 修改个人资料
+// @Description 修改昵称、头像（须为上传接口返回的地址）、邮箱或手机号，未提交的字段不变；修改邮箱需要当前密码，新邮箱需重新验证；修改手机号需要发送到新手机号的验证码
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param req body service.UpdateProfileRequest true "个人资料"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/profile [put]
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	var req service.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	user, err := h.profileService.UpdateProfile(c.Request.Context(), middleware.GetUserID(c), &req)
+	if err != nil {
+		h.fail(c, err, "修改个人资料失败")
+		return
+	}
+	response.OkWithData(c, user)
+}
+
+// DeleteAccount 注销账号
+// @Summary 注销账号
+// @Description 校验当前密码（或已绑定手机号的登录验证码）以及两步验证码后注销账号；存在未完成的订单时不能注销。注销后所有设备退出登录，个人信息被清除，用户名和邮箱可以重新注册
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param req body service.DeleteAccountRequest true "身份校验"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/account [delete]
+func (h *ProfileHandler) DeleteAccount(c *gin.Context) {
+	var req service.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.profileService.DeleteAccount(c.Request.Context(), middleware.GetUserID(c), &req); err != nil {
+		h.fail(c, err, "注销账号失败")
+		return
+	}
+	response.Ok(c)
+}
+
+func (h *ProfileHandler) fail(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPassword):
+		response.FailWithMsg(c, response.CodeUserPasswordError, err.Error())
+	case errors.Is(err, service.ErrEmailTaken):
+		response.FailWithMsg(c, response.CodeUserAlreadyExist, err.Error())
+	case errors.Is(err, service.ErrAccountBusy), errors.Is(err, service.ErrShopBusy):
+		response.FailWithMsg(c, response.CodeUserAccountBusy, err.Error())
+	case errors.Is(err, service.ErrTwoFactorRequired):
+		response.FailWithMsg(c, response.CodeUserTwoFactorRequired, err.Error())
+	case errors.Is(err, service.ErrTwoFactorCodeInvalid):
+		response.FailWithMsg(c, response.CodeUserTwoFactorInvalid, err.Error())
+	case errors.Is(err, service.ErrSmsCodeInvalid), errors.Is(err, service.ErrSmsCodeExhausted):
+		response.FailWithMsg(c, response.CodeUserSmsCodeInvalid, err.Error())
+	case errors.Is(err, service.ErrPhoneBound):
+		response.FailWithMsg(c, response.CodeUserPhoneBound, err.Error())
+	case errors.Is(err, service.ErrAvatarInvalid), errors.Is(err, service.ErrPasswordRequired),
+		errors.Is(err, service.ErrInvalidPhone):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, service.ErrSmsUnavailable), errors.Is(err, service.ErrRevokeUnavailable):
+		response.FailWithMsg(c, response.CodeServiceUnavailable, err.Error())
+	case errors.Is(err, repository.ErrUserNotFound):
+		response.FailWithMsg(c, response.CodeUserNotFound, err.Error())
+	default:
+		response.ServerError(c, msg)
+	}
+}
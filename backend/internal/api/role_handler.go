@@ -0,0 +1,173 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"gomall/backend/internal/repository"
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler 角色权限处理器
+type RoleHandler struct {
+	roleService *service.RoleService
+}
+
+// NewRoleHandler 创建角色权限处理器
+func NewRoleHandler() *RoleHandler {
+	return &RoleHandler{
+		roleService: service.NewRoleService(),
+	}
+}
+
+// ListRoles 角色列表
+// @Summary 角色列表
+// @Description 获取全部角色及其权限，超级管理员的权限为 ["*"]（需要 role:manage 权限）
+// @Tags 管理后台
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]service.RoleResponse}
+// @Router /api/admin/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles()
+	if err != nil {
+		response.FailWithMsg(c, response.CodeRoleFailed, "获取角色列表失败")
+		return
+	}
+
+	response.OkWithData(c, roles)
+}
+
+// ListPermissions 权限列表
+// @Summary 权限列表
+// @Description 获取全部可分配的权限（需要 role:manage 权限）
+// @Tags 管理后台
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/permissions [get]
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.roleService.ListPermissions()
+	if err != nil {
+		response.FailWithMsg(c, response.CodeRoleFailed, "获取权限列表失败")
+		return
+	}
+
+	response.OkWithData(c, permissions)
+}
+
+// CreateRole 创建角色
+// @Summary 创建角色
+// @Description 创建后台角色并设置权限（需要 role:manage 权限）
+// @Tags 管理后台
+// @Accept json
+// @Produce json
+// @Param req body service.CreateRoleRequest true "角色信息"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.RoleResponse}
+// @Router /api/admin/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req service.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	role, err := h.roleService.CreateRole(&req)
+	if err != nil {
+		h.fail(c, err, "角色创建失败")
+		return
+	}
+
+	response.OkWithData(c, role)
+}
+
+// SetRolePermissions 设置角色权限
+// @Summary 设置角色权限
+// @Description 整体替换角色的权限，超级管理员和普通用户角色不能修改（需要 role:manage 权限）
+// @Tags 管理后台
+// @Accept json
+// @Produce json
+// @Param id path int true "角色ID"
+// @Param req body service.SetRolePermissionsRequest true "权限编码列表"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/roles/{id}/permissions [put]
+func (h *RoleHandler) SetRolePermissions(c *gin.Context) {
+	roleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || roleID == 0 {
+		response.BadRequest(c, "角色ID错误")
+		return
+	}
+
+	var req service.SetRolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.roleService.SetRolePermissions(uint(roleID), &req); err != nil {
+		h.fail(c, err, "设置角色权限失败")
+		return
+	}
+
+	response.Ok(c)
+}
+
+// AssignRole 分配用户角色
+// @Summary 分配用户角色
+// @Description 修改用户的角色，该用户已签发的 Token 立即失效；不能修改自己的角色，只有超级管理员可以授予或修改超级管理员角色（需要 role:manage 权限）
+// @Tags 管理后台
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param req body service.AssignRoleRequest true "角色ID"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/users/{id}/role [put]
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || userID == 0 {
+		response.BadRequest(c, "用户ID错误")
+		return
+	}
+
+	var req service.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.roleService.AssignRole(c.Request.Context(), adminOperator(c), uint(userID), &req); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			response.FailWithMsg(c, response.CodeUserNotFound, err.Error())
+			return
+		}
+		h.fail(c, err, "分配角色失败")
+		return
+	}
+
+	response.Ok(c)
+}
+
+// fail 统一处理角色权限接口错误
+func (h *RoleHandler) fail(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, repository.ErrRoleNotFound):
+		response.FailWithMsg(c, response.CodeRoleNotFound, err.Error())
+	case errors.Is(err, service.ErrRoleImmutable), errors.Is(err, service.ErrUnknownPermission),
+		errors.Is(err, service.ErrChangeOwnRole):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, service.ErrAdminTargetForbidden):
+		response.FailWithMsg(c, response.CodeUserNoPermission, err.Error())
+	case errors.Is(err, service.ErrUserStatusConflict):
+		response.FailWithMsg(c, response.CodeConflict, err.Error())
+	case errors.Is(err, service.ErrRoleExists):
+		response.FailWithMsg(c, response.CodeConflict, err.Error())
+	default:
+		response.FailWithMsg(c, response.CodeRoleFailed, msg)
+	}
+}
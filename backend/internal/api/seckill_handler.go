@@ -13,12 +13,14 @@ import (
 // SeckillHandler 秒杀接口处理层
 type SeckillHandler struct {
 	seckillService *service.SeckillService
+	productService *service.ProductService
 }
 
 // NewSeckillHandler 创建秒杀处理器
 func NewSeckillHandler() *SeckillHandler {
 	return &SeckillHandler{
 		seckillService: service.NewSeckillService(),
+		productService: service.NewProductService(),
 	}
 }
 
@@ -50,6 +52,12 @@ func (h *SeckillHandler) Seckill(c *gin.Context) {
 		return
 	}
 
+	// 布隆过滤器拦截不存在的商品ID
+	if !h.productService.MightExist(c.Request.Context(), req.ProductID) {
+		response.FailWithMsg(c, response.CodeProductNotFound, "商品不存在")
+		return
+	}
+
 	svcReq := &service.SeckillRequest{ProductID: req.ProductID}
 	result, err := h.seckillService.SeckillWithRedis(c.Request.Context(), userID, svcReq)
 	if err != nil {
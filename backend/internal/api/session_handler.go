@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"gomall/backend/internal/middleware"
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SessionHandler 登录会话（设备）管理处理器
+type SessionHandler struct {
+	sessionService *service.SessionService
+}
+
+// NewSessionHandler 创建登录会话处理器
+func NewSessionHandler() *SessionHandler {
+	return &SessionHandler{
+		sessionService: service.NewSessionService(),
+	}
+}
+
+// List 登录设备列表
+// @Summary 登录设备列表
+// @Description 返回当前用户未退出、未过期的登录会话（设备、IP、登录时间、最近活跃时间），current 标记当前设备
+// @Tags 用户
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/sessions [get]
+func (h *SessionHandler) List(c *gin.Context) {
+	var family string
+	if claims := middleware.GetClaims(c); claims != nil {
+		family = claims.Family
+	}
+
+	sessions, err := h.sessionService.List(middleware.GetUserID(c), family)
+	if err != nil {
+		response.ServerError(c, "获取登录设备失败")
+		return
+	}
+	response.OkWithData(c, sessions)
+}
+
+// Delete 下线登录设备
+// @Summary 下线登录设备
+// @Description 结束指定会话，该设备的访问令牌和刷新令牌立即失效；可以下线当前设备
+// @Tags 用户
+// @Produce json
+// @Param id path int true "会话ID"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/sessions/{id} [delete]
+func (h *SessionHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		response.BadRequest(c, "无效的会话ID")
+		return
+	}
+
+	if err := h.sessionService.Delete(c.Request.Context(), middleware.GetUserID(c), uint(id)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrSessionNotFound):
+			response.NotFound(c, err.Error())
+		case errors.Is(err, service.ErrRevokeUnavailable):
+			response.FailWithMsg(c, response.CodeServiceUnavailable, "下线设备失败，请稍后重试")
+		default:
+			response.ServerError(c, "下线设备失败")
+		}
+		return
+	}
+	response.Ok(c)
+}
+
+// clientInfo 读取客户端IP和 User-Agent，登录时记录到会话
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
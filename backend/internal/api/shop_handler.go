@@ -0,0 +1,467 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"gomall/backend/internal/middleware"
+	"gomall/backend/internal/repository"
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShopHandler 店铺与商家后台处理器
+type ShopHandler struct {
+	shopService *service.ShopService
+}
+
+// NewShopHandler 创建店铺处理器
+func NewShopHandler() *ShopHandler {
+	return &ShopHandler{
+		shopService: service.NewShopService(),
+	}
+}
+
+// Apply 申请开店
+// @Summary 申请开店
+// @Description 提交开店申请，每个用户最多一个店铺；平台审核通过后可使用商家后台
+// @Tags 店铺
+// @Accept json
+// @Produce json
+// @Param req body service.ApplyShopRequest true "店铺资料"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/shop/apply [post]
+func (h *ShopHandler) Apply(c *gin.Context) {
+	var req service.ApplyShopRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	shop, err := h.shopService.Apply(middleware.GetUserID(c), &req)
+	if err != nil {
+		h.fail(c, err, "提交开店申请失败")
+		return
+	}
+	response.OkWithData(c, shop)
+}
+
+// Mine 我的店铺
+// @Summary 我的店铺
+// @Description 获取当前用户的店铺及审核状态：1 待审核 2 营业中 3 已驳回 4 已停业
+// @Tags 店铺
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/shop/mine [get]
+func (h *ShopHandler) Mine(c *gin.Context) {
+	shop, err := h.shopService.Mine(middleware.GetUserID(c))
+	if err != nil {
+		h.fail(c, err, "获取店铺失败")
+		return
+	}
+	response.OkWithData(c, shop)
+}
+
+// UpdateMine 修改店铺资料
+// @Summary 修改店铺资料
+// @Description 修改店铺名称、简介、Logo；被驳回的申请修改后重新进入待审核，已停业的店铺不能修改
+// @Tags 店铺
+// @Accept json
+// @Produce json
+// @Param req body service.ApplyShopRequest true "店铺资料"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/shop/mine [put]
+func (h *ShopHandler) UpdateMine(c *gin.Context) {
+	var req service.ApplyShopRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	shop, err := h.shopService.UpdateMine(middleware.GetUserID(c), &req)
+	if err != nil {
+		h.fail(c, err, "修改店铺资料失败")
+		return
+	}
+	response.OkWithData(c, shop)
+}
+
+// Products 本店商品列表
+// @Summary 本店商品列表
+// @Description 分页查询本店商品，包含已下架和定时上架的商品（商家后台）
+// @Tags 商家后台
+// @Produce json
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/shop/products [get]
+func (h *ShopHandler) Products(c *gin.Context) {
+	page, pageSize := adminPage(c)
+	products, total, err := h.shopService.ListProducts(middleware.GetShopID(c), page, pageSize)
+	if err != nil {
+		response.ServerError(c, "获取商品列表失败")
+		return
+	}
+	response.OkWithList(c, products, total, page, pageSize)
+}
+
+// CreateProduct 发布商品
+// @Summary 发布商品
+// @Description 在本店发布商品（商家后台）
+// @Tags 商家后台
+// @Accept json
+// @Produce json
+// @Param req body service.CreateProductRequest true "商品信息"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/shop/products [post]
+func (h *ShopHandler) CreateProduct(c *gin.Context) {
+	var req service.CreateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	product, err := h.shopService.CreateProduct(middleware.GetShopID(c), &req)
+	if err != nil {
+		response.FailWithMsg(c, response.CodeProductCreateFailed, err.Error())
+		return
+	}
+	response.OkWithData(c, product)
+}
+
+// UpdateProduct 修改商品
+// @Summary 修改商品
+// @Description 修改本店商品信息，库存请使用设置库存接口（商家后台）
+// @Tags 商家后台
+// @Accept json
+// @Produce json
+// @Param id path int true "商品ID"
+// @Param req body service.UpdateProductRequest true "商品信息"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/shop/products/{id} [put]
+func (h *ShopHandler) UpdateProduct(c *gin.Context) {
+	productID, ok := shopProductID(c)
+	if !ok {
+		return
+	}
+	var req service.UpdateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	err := h.shopService.UpdateProduct(middleware.GetShopID(c), productID, middleware.GetUserID(c), &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			response.FailWithMsg(c, response.CodeProductNotFound, err.Error())
+			return
+		}
+		response.FailWithMsg(c, response.CodeProductUpdateFailed, err.Error())
+		return
+	}
+	response.Ok(c)
+}
+
+// DeleteProduct 删除商品
+// @Summary 删除商品
+// @Description 删除本店商品（软删除，商家后台）
+// @Tags 商家后台
+// @Produce json
+// @Param id path int true "商品ID"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/shop/products/{id} [delete]
+func (h *ShopHandler) DeleteProduct(c *gin.Context) {
+	productID, ok := shopProductID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.shopService.DeleteProduct(middleware.GetShopID(c), productID); err != nil {
+		if errors.Is(err, repository.ErrProductNotFound) {
+			response.FailWithMsg(c, response.CodeProductNotFound, err.Error())
+			return
+		}
+		response.FailWithMsg(c, response.CodeProductDeleteFailed, err.Error())
+		return
+	}
+	response.Ok(c)
+}
+
+// SetStock 设置库存
+// @Summary 设置库存
+// @Description 设置本店商品库存（商家后台）
+// @Tags 商家后台
+// @Accept json
+// @Produce json
+// @Param id path int true "商品ID"
+// @Param req body service.SetStockRequest true "库存"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/shop/products/{id}/stock [put]
+func (h *ShopHandler) SetStock(c *gin.Context) {
+	productID, ok := shopProductID(c)
+	if !ok {
+		return
+	}
+	var req service.SetStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.shopService.SetStock(middleware.GetShopID(c), productID, req.Stock); err != nil {
+		h.fail(c, err, "设置库存失败")
+		return
+	}
+	response.Ok(c)
+}
+
+// InitSeckill 初始化秒杀库存
+// @Summary 初始化秒杀库存
+// @Description 为本店商品初始化秒杀库存（商家后台）
+// @Tags 商家后台
+// @Produce json
+// @Param product_id query int true "商品ID"
+// @Param stock query int true "秒杀库存"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/shop/seckill/init [post]
+func (h *ShopHandler) InitSeckill(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Query("product_id"), 10, 64)
+	if err != nil || productID == 0 {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+	stock, err := strconv.Atoi(c.Query("stock"))
+	if err != nil || stock < 0 {
+		response.BadRequest(c, "参数错误")
+		return
+	}
+
+	if err := h.shopService.InitSeckillStock(c.Request.Context(), middleware.GetShopID(c), uint(productID), stock); err != nil {
+		h.fail(c, err, "初始化失败")
+		return
+	}
+	response.OkWithData(c, gin.H{
+		"product_id": productID,
+		"stock":      stock,
+	})
+}
+
+// Orders 本店订单列表
+// @Summary 本店订单列表
+// @Description 分页查询本店订单，可按状态过滤（商家后台）
+// @Tags 商家后台
+// @Produce json
+// @Param status query int false "订单状态，不传时查询全部"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/shop/orders [get]
+func (h *ShopHandler) Orders(c *gin.Context) {
+	page, pageSize := adminPage(c)
+	status := -1
+	if s, err := strconv.Atoi(c.Query("status")); err == nil {
+		status = s
+	}
+
+	orders, total, err := h.shopService.ListOrders(middleware.GetShopID(c), status, page, pageSize)
+	if err != nil {
+		response.ServerError(c, "获取订单列表失败")
+		return
+	}
+	response.OkWithList(c, orders, total, page, pageSize)
+}
+
+// Order 本店订单详情
+// @Summary 本店订单详情
+// @Description 查询本店订单详情（商家后台）
+// @Tags 商家后台
+// @Produce json
+// @Param order_no path string true "订单号"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/shop/orders/{order_no} [get]
+func (h *ShopHandler) Order(c *gin.Context) {
+	order, err := h.shopService.GetOrder(middleware.GetShopID(c), c.Param("order_no"))
+	if err != nil {
+		h.fail(c, err, "获取订单失败")
+		return
+	}
+	response.OkWithData(c, order)
+}
+
+// ShipOrder 订单发货
+// @Summary 订单发货
+// @Description 将本店已支付的订单标记为已发货（商家后台）
+// @Tags 商家后台
+// @Produce json
+// @Param order_no path string true "订单号"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/shop/orders/{order_no}/ship [post]
+func (h *ShopHandler) ShipOrder(c *gin.Context) {
+	if err := h.shopService.ShipOrder(middleware.GetShopID(c), c.Param("order_no")); err != nil {
+		h.fail(c, err, "订单发货失败")
+		return
+	}
+	response.Ok(c)
+}
+
+// AdminList 店铺列表
+// @Summary 店铺列表
+// @Description 按审核状态、店铺名称分页查询店铺（需要 shop:manage 权限）
+// @Tags 管理后台
+// @Produce json
+// @Param status query int false "店铺状态：1 待审核 2 营业中 3 已驳回 4 已停业"
+// @Param keyword query string false "店铺名称"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/shops [get]
+func (h *ShopHandler) AdminList(c *gin.Context) {
+	page, pageSize := adminPage(c)
+	status, _ := strconv.Atoi(c.Query("status"))
+
+	shops, total, err := h.shopService.ListShops(repository.ShopQuery{
+		Status:  status,
+		Keyword: strings.TrimSpace(c.Query("keyword")),
+	}, page, pageSize)
+	if err != nil {
+		response.ServerError(c, "获取店铺列表失败")
+		return
+	}
+	response.OkWithList(c, shops, total, page, pageSize)
+}
+
+// Approve 审核通过
+// @Summary 审核通过
+// @Description 通过待审核的开店申请，或恢复已停业的店铺；操作写入审计日志（需要 shop:manage 权限）
+// @Tags 管理后台
+// @Accept json
+// @Produce json
+// @Param id path int true "店铺ID"
+// @Param req body service.ShopReviewRequest false "备注"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/shops/{id}/approve [post]
+func (h *ShopHandler) Approve(c *gin.Context) {
+	shopID, req, ok := h.bindReviewRequest(c)
+	if !ok {
+		return
+	}
+	if err := h.shopService.Approve(adminOperator(c), shopID, req.Reason); err != nil {
+		h.fail(c, err, "审核店铺失败")
+		return
+	}
+	response.Ok(c)
+}
+
+// Reject 驳回申请
+// @Summary 驳回申请
+// @Description 驳回待审核的开店申请，需要填写原因；操作写入审计日志（需要 shop:manage 权限）
+// @Tags 管理后台
+// @Accept json
+// @Produce json
+// @Param id path int true "店铺ID"
+// @Param req body service.ShopReviewRequest true "驳回原因"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/shops/{id}/reject [post]
+func (h *ShopHandler) Reject(c *gin.Context) {
+	shopID, req, ok := h.bindReviewRequest(c)
+	if !ok {
+		return
+	}
+	if err := h.shopService.Reject(adminOperator(c), shopID, req.Reason); err != nil {
+		h.fail(c, err, "驳回申请失败")
+		return
+	}
+	response.Ok(c)
+}
+
+// Suspend 店铺停业
+// @Summary 店铺停业
+// @Description 停业营业中的店铺，停业后商品不再展示、不能下单，需要填写原因；操作写入审计日志（需要 shop:manage 权限）
+// @Tags 管理后台
+// @Accept json
+// @Produce json
+// @Param id path int true "店铺ID"
+// @Param req body service.ShopReviewRequest true "停业原因"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/shops/{id}/suspend [post]
+func (h *ShopHandler) Suspend(c *gin.Context) {
+	shopID, req, ok := h.bindReviewRequest(c)
+	if !ok {
+		return
+	}
+	if err := h.shopService.Suspend(adminOperator(c), shopID, req.Reason); err != nil {
+		h.fail(c, err, "店铺停业失败")
+		return
+	}
+	response.Ok(c)
+}
+
+// bindReviewRequest 解析店铺ID和可选的审核备注
+func (h *ShopHandler) bindReviewRequest(c *gin.Context) (uint, *service.ShopReviewRequest, bool) {
+	shopID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || shopID == 0 {
+		response.BadRequest(c, "店铺ID错误")
+		return 0, nil, false
+	}
+	var req service.ShopReviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "参数错误: "+err.Error())
+			return 0, nil, false
+		}
+	}
+	return uint(shopID), &req, true
+}
+
+func (h *ShopHandler) fail(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrShopNotFound), errors.Is(err, repository.ErrShopNotFound):
+		response.FailWithMsg(c, response.CodeShopNotFound, err.Error())
+	case errors.Is(err, service.ErrShopExists):
+		response.FailWithMsg(c, response.CodeShopExists, err.Error())
+	case errors.Is(err, service.ErrShopNameTaken):
+		response.FailWithMsg(c, response.CodeShopNameTaken, err.Error())
+	case errors.Is(err, service.ErrShopStatusConflict):
+		response.FailWithMsg(c, response.CodeShopStatusError, err.Error())
+	case errors.Is(err, service.ErrShopNameEmpty), errors.Is(err, service.ErrShopLogoInvalid),
+		errors.Is(err, service.ErrShopReasonRequired):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, repository.ErrProductNotFound):
+		response.FailWithMsg(c, response.CodeProductNotFound, err.Error())
+	case errors.Is(err, repository.ErrOrderNotFound):
+		response.FailWithMsg(c, response.CodeOrderNotFound, err.Error())
+	case errors.Is(err, service.ErrOrderNotShippable):
+		response.FailWithMsg(c, response.CodeOrderStatusError, err.Error())
+	default:
+		response.ServerError(c, msg)
+	}
+}
+
+// shopProductID 解析路径中的商品ID，无效时写入响应
+func shopProductID(c *gin.Context) (uint, bool) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || productID == 0 {
+		response.BadRequest(c, "商品ID错误")
+		return 0, false
+	}
+	return uint(productID), true
+}
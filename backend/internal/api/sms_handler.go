@@ -0,0 +1,161 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"gomall/backend/internal/middleware"
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SmsHandler 短信验证码登录与绑定手机处理器
+type SmsHandler struct {
+	smsService *service.SmsService
+}
+
+// NewSmsHandler 创建短信验证码处理器
+func NewSmsHandler() *SmsHandler {
+	return &SmsHandler{
+		smsService: service.NewSmsService(),
+	}
+}
+
+// SendLoginCode 发送登录验证码
+// @Summary 发送登录验证码
+// @Description 向手机号发送 6 位登录验证码；同一手机号 60 秒内只能发送一次，并按手机号每日、IP 每小时限制次数
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param req body service.SmsCodeRequest true "手机号"
+// @Success 200 {object} response.Response
+// @Router /api/user/sms/code [post]
+func (h *SmsHandler) SendLoginCode(c *gin.Context) {
+	var req service.SmsCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "手机号不能为空")
+		return
+	}
+
+	if err := h.smsService.SendCode(c.Request.Context(), service.SmsPurposeLogin, req.Phone, c.ClientIP()); err != nil {
+		h.fail(c, err, "验证码发送失败")
+		return
+	}
+
+	response.Ok(c)
+}
+
+// LoginByCode 验证码登录
+// @Summary 验证码登录
+// @Description 使用手机验证码登录，手机号未绑定账号时自动注册；返回 created 表示是否为新账号，启用了两步验证时返回预认证Token
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param req body service.PhoneCodeRequest true "手机号和验证码"
+// @Success 200 {object} response.Response
+// @Router /api/user/sms/login [post]
+func (h *SmsHandler) LoginByCode(c *gin.Context) {
+	var req service.PhoneCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	result, created, err := h.smsService.LoginByPhone(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		h.fail(c, err, "登录失败")
+		return
+	}
+
+	loginResponse(c, result, gin.H{"created": created})
+}
+
+// SendBindCode 发送绑定手机验证码
+// @Summary 发送绑定手机验证码
+// @Description 向要绑定的新手机号发送验证码，频率限制同登录验证码
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param req body service.SmsCodeRequest true "手机号"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/phone/code [post]
+func (h *SmsHandler) SendBindCode(c *gin.Context) {
+	var req service.SmsCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "手机号不能为空")
+		return
+	}
+
+	if err := h.smsService.SendCode(c.Request.Context(), service.SmsPurposeBind, req.Phone, c.ClientIP()); err != nil {
+		h.fail(c, err, "验证码发送失败")
+		return
+	}
+
+	response.Ok(c)
+}
+
+// BindPhone 绑定或更换手机号
+// @Summary 绑定或更换手机号
+// @Description 校验新手机号收到的验证码后绑定到当前账号，绑定后可使用验证码登录
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param req body service.PhoneCodeRequest true "手机号和验证码"
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.UserResponse}
+// @Router /api/user/phone [put]
+func (h *SmsHandler) BindPhone(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Unauthorized(c, "未登录")
+		return
+	}
+
+	var req service.PhoneCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	user, err := h.smsService.BindPhone(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.fail(c, err, "绑定手机号失败")
+		return
+	}
+
+	response.OkWithData(c, user)
+}
+
+// fail 统一处理短信验证码接口错误
+func (h *SmsHandler) fail(c *gin.Context, err error, msg string) {
+	var lockedErr *service.LoginLockedError
+	switch {
+	case errors.As(err, &lockedErr):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+		if errors.Is(err, service.ErrAccountLocked) {
+			response.FailWithMsg(c, response.CodeUserAccountLocked, err.Error())
+		} else {
+			response.FailWithMsg(c, response.CodeTooManyRequests, err.Error())
+		}
+	case errors.Is(err, service.ErrUserDisabled):
+		response.FailWithMsg(c, response.CodeUserDisabled, err.Error())
+	case errors.Is(err, service.ErrPasswordResetRequired):
+		response.FailWithMsg(c, response.CodeUserAccountLocked, err.Error())
+	case errors.Is(err, service.ErrInvalidPhone):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, service.ErrSmsCodeInvalid), errors.Is(err, service.ErrSmsCodeExhausted):
+		response.FailWithMsg(c, response.CodeUserSmsCodeInvalid, err.Error())
+	case errors.Is(err, service.ErrSmsTooFrequent):
+		response.FailWithMsg(c, response.CodeTooManyRequests, err.Error())
+	case errors.Is(err, service.ErrPhoneBound):
+		response.FailWithMsg(c, response.CodeUserPhoneBound, err.Error())
+	case errors.Is(err, service.ErrSmsUnavailable), errors.Is(err, service.ErrSmsSendFailed):
+		response.FailWithMsg(c, response.CodeServiceUnavailable, err.Error())
+	default:
+		response.ServerError(c, msg)
+	}
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"errors"
+
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatsHandler 经营统计处理器
+type StatsHandler struct {
+	statsService *service.StatsService
+}
+
+// NewStatsHandler 创建经营统计处理器
+func NewStatsHandler() *StatsHandler {
+	return &StatsHandler{
+		statsService: service.NewStatsService(),
+	}
+}
+
+// Overview 经营概览
+// @Summary 经营概览
+// @Description GMV、支付订单数、客单价、新增用户、订单取消率，支持按日/周/月汇总（需要管理员权限）
+// @Tags 管理后台
+// @Produce json
+// @Param start query string false "开始日期 2006-01-02，默认结束日期前29天"
+// @Param end query string false "结束日期 2006-01-02，默认今天"
+// @Param granularity query string false "汇总粒度 day|week|month" default(day)
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.StatsOverview}
+// @Router /api/admin/stats/overview [get]
+func (h *StatsHandler) Overview(c *gin.Context) {
+	var q service.StatsOverviewQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	overview, err := h.statsService.Overview(c.Request.Context(), &q)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	response.OkWithData(c, overview)
+}
+
+// TopProducts 商品销售排行
+// @Summary 商品销售排行
+// @Description 时间段内已支付订单按商品汇总的销售额/销量排行（需要管理员权限）
+// @Tags 管理后台
+// @Produce json
+// @Param start query string false "开始日期 2006-01-02"
+// @Param end query string false "结束日期 2006-01-02"
+// @Param by query string false "排序 gmv|quantity" default(gmv)
+// @Param limit query int false "返回条数（最多100）" default(10)
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/stats/top-products [get]
+func (h *StatsHandler) TopProducts(c *gin.Context) {
+	var q service.TopProductsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	products, err := h.statsService.TopProducts(c.Request.Context(), &q)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	response.OkWithData(c, products)
+}
+
+// Seckill 秒杀结果统计
+// @Summary 秒杀结果统计
+// @Description 时间段内的秒杀订单按商品汇总：订单数、支付数、取消数、成交额和剩余秒杀库存（需要管理员权限）
+// @Tags 管理后台
+// @Produce json
+// @Param start query string false "开始日期 2006-01-02"
+// @Param end query string false "结束日期 2006-01-02"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/stats/seckill [get]
+func (h *StatsHandler) Seckill(c *gin.Context) {
+	var q service.StatsRangeQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	stats, err := h.statsService.Seckill(c.Request.Context(), &q)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	response.OkWithData(c, stats)
+}
+
+// fail 统一处理统计接口错误
+func (h *StatsHandler) fail(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidStatsRange) || errors.Is(err, service.ErrStatsRangeTooLong) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	response.FailWithMsg(c, response.CodeServerError, "统计查询失败")
+}
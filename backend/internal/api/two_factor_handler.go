@@ -0,0 +1,217 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"gomall/backend/internal/middleware"
+	"gomall/backend/internal/repository"
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorHandler 两步验证处理器
+type TwoFactorHandler struct {
+	twoFactorService *service.TwoFactorService
+}
+
+// NewTwoFactorHandler 创建两步验证处理器
+func NewTwoFactorHandler() *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: service.NewTwoFactorService(),
+	}
+}
+
+// Status 获取两步验证状态
+// @Summary 两步验证状态
+// @Description 返回是否已启用、是否被强制要求启用以及剩余备用码数量
+// @Tags 认证
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/auth/2fa [get]
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	status, err := h.twoFactorService.Status(middleware.GetUserID(c))
+	if err != nil {
+		h.fail(c, err, "获取两步验证状态失败")
+		return
+	}
+	response.OkWithData(c, status)
+}
+
+// Setup 获取两步验证密钥
+// @Summary 获取两步验证密钥
+// @Description 生成新的 TOTP 密钥和 otpauth:// 链接（前端渲染为二维码），之前未完成的绑定作废；需调用启用接口提交验证码后才生效
+// @Tags 认证
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/auth/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	setup, err := h.twoFactorService.Setup(middleware.GetUserID(c))
+	if err != nil {
+		h.fail(c, err, "获取两步验证密钥失败")
+		return
+	}
+	response.OkWithData(c, setup)
+}
+
+// Enable 启用两步验证
+// @Summary 启用两步验证
+// @Description 提交验证器中的动态验证码启用两步验证，返回一次性备用码（只显示这一次）；其他设备上的 Token 失效，响应中返回当前设备的新 Token
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param req body service.TwoFactorCodeRequest true "动态验证码"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/auth/2fa/enable [post]
+func (h *TwoFactorHandler) Enable(c *gin.Context) {
+	var req service.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "验证码不能为空")
+		return
+	}
+
+	result, err := h.twoFactorService.Enable(c.Request.Context(), middleware.GetUserID(c), req.Code, clientInfo(c))
+	if err != nil {
+		h.fail(c, err, "启用两步验证失败")
+		return
+	}
+
+	response.OkWithData(c, gin.H{
+		"backup_codes":  result.BackupCodes,
+		"token":         result.TokenPair.AccessToken,
+		"refresh_token": result.TokenPair.RefreshToken,
+		"expires_in":    result.TokenPair.ExpiresIn,
+	})
+}
+
+// Disable 关闭两步验证
+// @Summary 关闭两步验证
+// @Description 校验登录密码和动态验证码（或备用码）后关闭两步验证；security.require_admin_2fa 开启时后台账号不能关闭
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param req body service.DisableTwoFactorRequest true "密码和验证码"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req service.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	if err := h.twoFactorService.Disable(middleware.GetUserID(c), &req); err != nil {
+		h.fail(c, err, "关闭两步验证失败")
+		return
+	}
+	response.Ok(c)
+}
+
+// RegenerateBackupCodes 重新生成备用码
+// @Summary 重新生成备用码
+// @Description 提交动态验证码后重新生成备用码，旧的备用码全部作废
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param req body service.TwoFactorCodeRequest true "动态验证码"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/auth/2fa/backup-codes [post]
+func (h *TwoFactorHandler) RegenerateBackupCodes(c *gin.Context) {
+	var req service.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "验证码不能为空")
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateBackupCodes(middleware.GetUserID(c), req.Code)
+	if err != nil {
+		h.fail(c, err, "备用码生成失败")
+		return
+	}
+	response.OkWithData(c, gin.H{"backup_codes": codes})
+}
+
+// VerifyLogin 两步验证登录
+// @Summary 两步验证登录
+// @Description 登录接口返回 two_factor_required 时，提交预认证Token和动态验证码（或备用码）完成登录；验证码错误计入登录失败次数
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param req body service.TwoFactorLoginRequest true "预认证Token和验证码"
+// @Success 200 {object} response.Response
+// @Router /api/user/login/2fa [post]
+func (h *TwoFactorHandler) VerifyLogin(c *gin.Context) {
+	var req service.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "参数错误: "+err.Error())
+		return
+	}
+
+	result, err := h.twoFactorService.VerifyLogin(c.Request.Context(), &req, clientInfo(c))
+	if err != nil {
+		h.fail(c, err, "登录失败")
+		return
+	}
+	loginResponse(c, result, nil)
+}
+
+func (h *TwoFactorHandler) fail(c *gin.Context, err error, msg string) {
+	var lockedErr *service.LoginLockedError
+	switch {
+	case errors.As(err, &lockedErr):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+		if errors.Is(err, service.ErrAccountLocked) {
+			response.FailWithMsg(c, response.CodeUserAccountLocked, err.Error())
+		} else {
+			response.FailWithMsg(c, response.CodeTooManyRequests, err.Error())
+		}
+	case errors.Is(err, service.ErrUserDisabled):
+		response.FailWithMsg(c, response.CodeUserDisabled, err.Error())
+	case errors.Is(err, service.ErrPasswordResetRequired):
+		response.FailWithMsg(c, response.CodeUserAccountLocked, err.Error())
+	case errors.Is(err, service.ErrTwoFactorCodeInvalid), errors.Is(err, service.ErrTwoFactorSessionInvalid):
+		response.FailWithMsg(c, response.CodeUserTwoFactorInvalid, err.Error())
+	case errors.Is(err, service.ErrTwoFactorRequired):
+		response.FailWithMsg(c, response.CodeUserTwoFactorRequired, err.Error())
+	case errors.Is(err, service.ErrInvalidPassword):
+		response.FailWithMsg(c, response.CodeUserPasswordError, err.Error())
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled), errors.Is(err, service.ErrTwoFactorNotEnabled),
+		errors.Is(err, service.ErrTwoFactorNotSetup):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, repository.ErrUserNotFound):
+		response.FailWithMsg(c, response.CodeUserNotFound, err.Error())
+	default:
+		response.ServerError(c, msg)
+	}
+}
+
+// loginResponse 输出登录结果，需要两步验证时只返回预认证Token
+func loginResponse(c *gin.Context, result *service.LoginResult, extra gin.H) {
+	var data gin.H
+	if result.TwoFactor != nil {
+		data = gin.H{
+			"two_factor_required": true,
+			"pre_auth_token":      result.TwoFactor.PreAuthToken,
+			"expires_in":          result.TwoFactor.ExpiresIn,
+		}
+	} else {
+		data = gin.H{
+			"token":         result.TokenPair.AccessToken,
+			"refresh_token": result.TokenPair.RefreshToken,
+			"expires_in":    result.TokenPair.ExpiresIn,
+			"user":          result.User,
+		}
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+	response.OkWithData(c, data)
+}
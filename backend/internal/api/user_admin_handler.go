@@ -0,0 +1,267 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"gomall/backend/internal/middleware"
+	"gomall/backend/internal/repository"
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserAdminHandler 后台用户管理处理器
+type UserAdminHandler struct {
+	userAdminService *service.UserAdminService
+}
+
+// NewUserAdminHandler 创建后台用户管理处理器
+func NewUserAdminHandler() *UserAdminHandler {
+	return &UserAdminHandler{
+		userAdminService: service.NewUserAdminService(),
+	}
+}
+
+// List 用户列表
+// @Summary 用户列表
+// @Description 按关键字（用户名、昵称、邮箱、手机号）、账号状态、角色分页查询用户（需要 user:manage 权限）
+// @Tags 管理后台
+// @Produce json
+// @Param keyword query string false "关键字"
+// @Param status query int false "账号状态：1 正常 2 已禁用 3 已锁定"
+// @Param role query int false "角色ID"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/users [get]
+func (h *UserAdminHandler) List(c *gin.Context) {
+	page, pageSize := adminPage(c)
+	status, _ := strconv.Atoi(c.Query("status"))
+	role, _ := strconv.Atoi(c.Query("role"))
+
+	users, total, err := h.userAdminService.ListUsers(repository.UserQuery{
+		Keyword: strings.TrimSpace(c.Query("keyword")),
+		Status:  status,
+		Role:    role,
+	}, page, pageSize)
+	if err != nil {
+		response.ServerError(c, "获取用户列表失败")
+		return
+	}
+	response.OkWithList(c, users, total, page, pageSize)
+}
+
+// Orders 用户订单
+// @Summary 用户订单
+// @Description 分页查询指定用户的订单（需要 user:manage 权限）
+// @Tags 管理后台
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/users/{id}/orders [get]
+func (h *UserAdminHandler) Orders(c *gin.Context) {
+	userID, ok := adminUserID(c)
+	if !ok {
+		return
+	}
+	page, pageSize := adminPage(c)
+
+	orders, total, err := h.userAdminService.UserOrders(userID, page, pageSize)
+	if err != nil {
+		h.fail(c, err, "获取用户订单失败")
+		return
+	}
+	response.OkWithList(c, orders, total, page, pageSize)
+}
+
+// Disable 禁用账号
+// @Summary 禁用账号
+// @Description 禁用用户账号，该用户立即退出所有设备且不能再登录；操作写入审计日志（需要 user:manage 权限）
+// @Tags 管理后台
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param req body service.AdminUserStatusRequest false "操作原因"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/users/{id}/disable [post]
+func (h *UserAdminHandler) Disable(c *gin.Context) {
+	userID, req, ok := h.bindStatusRequest(c)
+	if !ok {
+		return
+	}
+	if err := h.userAdminService.Disable(c.Request.Context(), adminOperator(c), userID, req.Reason); err != nil {
+		h.fail(c, err, "禁用账号失败")
+		return
+	}
+	response.Ok(c)
+}
+
+// Enable 启用账号
+// @Summary 启用账号
+// @Description 启用被禁用或被锁定的账号；操作写入审计日志（需要 user:manage 权限）
+// @Tags 管理后台
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param req body service.AdminUserStatusRequest false "操作原因"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/users/{id}/enable [post]
+func (h *UserAdminHandler) Enable(c *gin.Context) {
+	userID, req, ok := h.bindStatusRequest(c)
+	if !ok {
+		return
+	}
+	if err := h.userAdminService.Enable(c.Request.Context(), adminOperator(c), userID, req.Reason); err != nil {
+		h.fail(c, err, "启用账号失败")
+		return
+	}
+	response.Ok(c)
+}
+
+// ResetPassword 要求重置密码
+// @Summary 要求重置密码
+// @Description 锁定账号并向用户邮箱发送重置密码邮件，用户立即退出所有设备，重置密码后账号自动恢复正常；mail_sent 为 false 时（占位邮箱或发送失败）需线下联系用户。操作写入审计日志（需要 user:manage 权限）
+// @Tags 管理后台
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param req body service.AdminUserStatusRequest false "操作原因"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/users/{id}/reset-password [post]
+func (h *UserAdminHandler) ResetPassword(c *gin.Context) {
+	userID, req, ok := h.bindStatusRequest(c)
+	if !ok {
+		return
+	}
+	mailSent, err := h.userAdminService.ForcePasswordReset(c.Request.Context(), adminOperator(c), userID, req.Reason)
+	if err != nil {
+		h.fail(c, err, "重置密码失败")
+		return
+	}
+	response.OkWithData(c, gin.H{"mail_sent": mailSent})
+}
+
+// Unlock 解除登录锁定
+// @Summary 解除登录锁定
+// @Description 解除用户因连续登录失败导致的账号锁定，并清空失败次数；返回解锁前是否处于锁定状态，操作写入审计日志（需要 user:manage 权限）
+// @Tags 管理后台
+// @Produce json
+// @Param id path int true "用户ID"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/users/{id}/unlock [post]
+func (h *UserAdminHandler) Unlock(c *gin.Context) {
+	userID, ok := adminUserID(c)
+	if !ok {
+		return
+	}
+
+	wasLocked, err := h.userAdminService.Unlock(c.Request.Context(), adminOperator(c), userID)
+	if err != nil {
+		h.fail(c, err, "解除锁定失败")
+		return
+	}
+
+	response.OkWithData(c, gin.H{"was_locked": wasLocked})
+}
+
+// AuditLogs 用户管理审计日志
+// @Summary 用户管理审计日志
+// @Description 分页查询用户管理操作日志，可按操作人、用户、操作类型过滤（需要 user:manage 权限）
+// @Tags 管理后台
+// @Produce json
+// @Param operator_id query int false "操作人用户ID"
+// @Param user_id query int false "被操作的用户ID"
+// @Param action query string false "操作类型，如 user.disable"
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/admin/users/audit-logs [get]
+func (h *UserAdminHandler) AuditLogs(c *gin.Context) {
+	page, pageSize := adminPage(c)
+	operatorID, _ := strconv.ParseUint(c.Query("operator_id"), 10, 64)
+	targetID, _ := strconv.ParseUint(c.Query("user_id"), 10, 64)
+
+	logs, total, err := h.userAdminService.ListAuditLogs(repository.AuditLogQuery{
+		OperatorID: uint(operatorID),
+		TargetID:   uint(targetID),
+		Action:     c.Query("action"),
+	}, page, pageSize)
+	if err != nil {
+		response.ServerError(c, "获取审计日志失败")
+		return
+	}
+	response.OkWithList(c, logs, total, page, pageSize)
+}
+
+// bindStatusRequest 解析用户ID和可选的操作原因
+func (h *UserAdminHandler) bindStatusRequest(c *gin.Context) (uint, *service.AdminUserStatusRequest, bool) {
+	userID, ok := adminUserID(c)
+	if !ok {
+		return 0, nil, false
+	}
+	var req service.AdminUserStatusRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "参数错误: "+err.Error())
+			return 0, nil, false
+		}
+	}
+	return userID, &req, true
+}
+
+func (h *UserAdminHandler) fail(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		response.FailWithMsg(c, response.CodeUserNotFound, err.Error())
+	case errors.Is(err, service.ErrAdminSelfOperation), errors.Is(err, service.ErrAdminTargetForbidden):
+		response.FailWithMsg(c, response.CodeUserNoPermission, err.Error())
+	case errors.Is(err, service.ErrUserStatusConflict):
+		response.BadRequest(c, err.Error())
+	default:
+		response.ServerError(c, msg)
+	}
+}
+
+// adminUserID 解析路径中的用户ID，无效时写入响应
+func adminUserID(c *gin.Context) (uint, bool) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || userID == 0 {
+		response.BadRequest(c, "用户ID错误")
+		return 0, false
+	}
+	return uint(userID), true
+}
+
+// adminPage 解析分页参数，默认每页 20 条
+func adminPage(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// adminOperator 当前管理员，写入审计日志
+func adminOperator(c *gin.Context) service.AdminOperator {
+	return service.AdminOperator{
+		UserID: middleware.GetUserID(c),
+		Role:   middleware.GetRole(c),
+		IP:     c.ClientIP(),
+	}
+}
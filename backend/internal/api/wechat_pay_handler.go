@@ -7,6 +7,7 @@ import (
 	"strconv"
 
 	"gomall/backend/internal/middleware"
+	"gomall/backend/internal/model"
 	"gomall/backend/internal/response"
 	"gomall/backend/internal/service"
 
@@ -77,6 +78,12 @@ func (h *WeChatPayHandler) UnifiedOrder(c *gin.Context) {
 		return
 	}
 
+	// 合并结算的店铺子订单通过父订单支付
+	if order.ParentOrderNo != "" {
+		response.FailWithMsg(c, response.CodeOrderStatusError, service.ErrChildOrderOperation.Error())
+		return
+	}
+
 	// 检查订单状态
 	if order.Status != 1 {
 		response.FailWithMsg(c, response.CodeOrderStatusError, "订单状态不允许支付")
@@ -84,7 +91,7 @@ func (h *WeChatPayHandler) UnifiedOrder(c *gin.Context) {
 	}
 
 	// 计算支付金额（分）
-	totalFee := int(order.TotalPrice * 100)
+	totalFee := int(service.ToCents(order.TotalPrice))
 
 	// 调用微信统一下单
 	result, err := h.wechatPayService.UnifiedOrder(c.Request.Context(), req.OrderNo, totalFee, order.ProductName)
@@ -165,8 +172,14 @@ func (h *WeChatPayHandler) QueryOrder(c *gin.Context) {
 		return
 	}
 
+	// 店铺子订单的支付交易为父订单
+	tradeNo := orderNo
+	if order.ParentOrderNo != "" {
+		tradeNo = order.ParentOrderNo
+	}
+
 	// 调用微信查询订单
-	result, err := h.wechatPayService.QueryOrder(c.Request.Context(), orderNo)
+	result, err := h.wechatPayService.QueryOrder(c.Request.Context(), tradeNo)
 	if err != nil {
 		response.FailWithMsg(c, response.CodePayQueryFailed, err.Error())
 		return
@@ -231,6 +244,12 @@ func (h *WeChatPayHandler) CloseOrder(c *gin.Context) {
 		return
 	}
 
+	// 合并结算的店铺子订单通过父订单关闭
+	if order.ParentOrderNo != "" {
+		response.FailWithMsg(c, response.CodeOrderStatusError, service.ErrChildOrderOperation.Error())
+		return
+	}
+
 	// 调用微信关闭订单
 	if err := h.wechatPayService.CloseOrder(c.Request.Context(), orderNo); err != nil {
 		response.FailWithMsg(c, response.CodeOrderCancelFailed, err.Error())
@@ -298,11 +317,33 @@ func (h *WeChatPayHandler) Refund(c *gin.Context) {
 	// 生成退款单号
 	refundNo := fmt.Sprintf("REF%s%s", orderNo[3:], strconv.FormatInt(int64(userID), 10))
 
-	// 计算支付金额（分）
-	totalFee := int(order.TotalPrice * 100)
+	// 合并结算的父订单按店铺子订单分别退款
+	if order.OrderType == model.OrderTypeCombined {
+		response.FailWithMsg(c, response.CodeOrderStatusError, "合并订单请按店铺子订单申请退款")
+		return
+	}
+
+	// 退款金额不能超过订单金额，店铺子订单只退本店的金额
+	if refundFee <= 0 || refundFee > int(service.ToCents(order.TotalPrice)) {
+		response.BadRequest(c, "退款金额超出订单金额")
+		return
+	}
+
+	// 计算支付金额（分），店铺子订单的支付交易为父订单
+	tradeNo := orderNo
+	totalFee := int(service.ToCents(order.TotalPrice))
+	if order.ParentOrderNo != "" {
+		parent, err := h.orderService.GetOrderByNo(order.ParentOrderNo)
+		if err != nil {
+			response.FailWithMsg(c, response.CodeOrderNotFound, "订单不存在")
+			return
+		}
+		tradeNo = parent.OrderNo
+		totalFee = int(service.ToCents(parent.TotalPrice))
+	}
 
 	// 调用微信退款
-	if err := h.wechatPayService.Refund(c.Request.Context(), orderNo, refundNo, totalFee, refundFee); err != nil {
+	if err := h.wechatPayService.Refund(c.Request.Context(), tradeNo, refundNo, totalFee, refundFee); err != nil {
 		response.FailWithMsg(c, response.CodePayRefundFailed, err.Error())
 		return
 	}
@@ -0,0 +1,230 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"gomall/backend/internal/config"
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/metrics"
+	"gomall/backend/internal/redis"
+
+	"go.uber.org/zap"
+)
+
+// 二级缓存：进程内 LRU（L1）+ Redis（L2）
+//
+// 读：L1 -> L2 -> 由调用方回源并 Set
+// 写：Set 同时写入 L1 和 L2
+// 失效：Invalidate 删除本机 L1 和 L2，并通过 Redis Pub/Sub 通知其他实例删除各自的 L1
+//
+// L1 的 TTL 很短（秒级），即使失效广播丢失，脏数据也会很快过期。
+// Redis 不可用时只使用 L1，不影响调用方回源。
+
+// InvalidateChannel 缓存失效广播频道
+const InvalidateChannel = "gomall:cache:invalidate"
+
+// 本地缓存默认参数（config.yaml 中 cache 节未配置时使用）
+const (
+	defaultLocalCapacity = 10000
+	defaultLocalTTL      = 5 * time.Second
+)
+
+// invalidateMessage 失效广播消息
+type invalidateMessage struct {
+	Cache string   `json:"cache"`
+	Keys  []string `json:"keys"`
+}
+
+// Cache 二级缓存
+type Cache struct {
+	name     string
+	local    *LRU
+	localTTL time.Duration
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]*Cache)
+)
+
+// New 创建二级缓存并注册到失效广播
+// name 用于区分不同业务的缓存，同名缓存只会创建一次
+func New(name string, capacity int, localTTL time.Duration) *Cache {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if c, ok := registry[name]; ok {
+		return c
+	}
+	c := &Cache{
+		name:     name,
+		local:    NewLRU(capacity),
+		localTTL: localTTL,
+	}
+	registry[name] = c
+	return c
+}
+
+// NewFromConfig 使用 cache 配置节中的 local_capacity/local_ttl 创建二级缓存
+func NewFromConfig(name string) *Cache {
+	capacity, localTTL := defaultLocalCapacity, defaultLocalTTL
+	if config.Config != nil {
+		if cacheConfig := config.GetCache(); cacheConfig != nil {
+			if v := cacheConfig.GetInt("local_capacity"); v > 0 {
+				capacity = v
+			}
+			if v := cacheConfig.GetInt("local_ttl"); v > 0 {
+				localTTL = time.Duration(v) * time.Second
+			}
+		}
+	}
+	return New(name, capacity, localTTL)
+}
+
+// Get 读取缓存，依次查询 L1 和 L2
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool) {
+	if value, ok := c.local.Get(key); ok {
+		metrics.RecordCacheLookup(c.name, "local", true)
+		return value, true
+	}
+	metrics.RecordCacheLookup(c.name, "local", false)
+
+	if redis.Client == nil {
+		return nil, false
+	}
+	value, err := redis.Client.Get(ctx, key).Bytes()
+	if err != nil {
+		metrics.RecordCacheLookup(c.name, "redis", false)
+		return nil, false
+	}
+	metrics.RecordCacheLookup(c.name, "redis", true)
+	c.local.Set(key, value, c.localTTL)
+	return value, true
+}
+
+// GetMany 批量读取缓存，L1 未命中的 key 通过一次 MGET 查询 L2
+// 返回值与 keys 一一对应，未命中的位置为 nil
+func (c *Cache) GetMany(ctx context.Context, keys []string) [][]byte {
+	values := make([][]byte, len(keys))
+	missed := make([]int, 0, len(keys))
+	for i, key := range keys {
+		if value, ok := c.local.Get(key); ok {
+			metrics.RecordCacheLookup(c.name, "local", true)
+			values[i] = value
+			continue
+		}
+		metrics.RecordCacheLookup(c.name, "local", false)
+		missed = append(missed, i)
+	}
+	if len(missed) == 0 || redis.Client == nil {
+		return values
+	}
+
+	missedKeys := make([]string, len(missed))
+	for i, idx := range missed {
+		missedKeys[i] = keys[idx]
+	}
+	results, err := redis.Client.MGet(ctx, missedKeys...).Result()
+	if err != nil {
+		for range missed {
+			metrics.RecordCacheLookup(c.name, "redis", false)
+		}
+		return values
+	}
+	for i, result := range results {
+		s, ok := result.(string)
+		if !ok {
+			metrics.RecordCacheLookup(c.name, "redis", false)
+			continue
+		}
+		metrics.RecordCacheLookup(c.name, "redis", true)
+		value := []byte(s)
+		values[missed[i]] = value
+		c.local.Set(missedKeys[i], value, c.localTTL)
+	}
+	return values
+}
+
+// Set 写入缓存，ttl 为 Redis 中的过期时间，本地过期时间不超过 ttl
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	localTTL := c.localTTL
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	c.local.Set(key, value, localTTL)
+
+	if redis.Client == nil {
+		return nil
+	}
+	return redis.Client.Set(ctx, key, value, ttl).Err()
+}
+
+// Invalidate 删除缓存并广播给其他实例
+func (c *Cache) Invalidate(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, key := range keys {
+		c.local.Delete(key)
+	}
+	if redis.Client == nil {
+		return nil
+	}
+
+	if err := redis.Client.Del(ctx, keys...).Err(); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(invalidateMessage{Cache: c.name, Keys: keys})
+	if err != nil {
+		return err
+	}
+	return redis.Client.Publish(ctx, InvalidateChannel, payload).Err()
+}
+
+// PurgeLocal 清空本机 L1，不影响 L2 和其他实例
+// 用于无法列举全部 key 的场景（如 Redis 不可用时无法读取 key 集合）
+func (c *Cache) PurgeLocal() {
+	c.local.Purge()
+}
+
+// StartInvalidationListener 订阅失效广播，删除本机 L1 中对应的条目
+// 阻塞运行直到 ctx 结束，应在独立协程中调用
+func StartInvalidationListener(ctx context.Context) {
+	if redis.Client == nil {
+		return
+	}
+
+	pubsub := redis.Client.Subscribe(ctx, InvalidateChannel)
+	defer pubsub.Close()
+
+	logger.Info("缓存失效广播监听已启动", zap.String("channel", InvalidateChannel))
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var m invalidateMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil {
+				logger.Warn("缓存失效消息解析失败", zap.Error(err))
+				continue
+			}
+
+			registryMu.RLock()
+			c, exists := registry[m.Cache]
+			registryMu.RUnlock()
+			if !exists {
+				continue
+			}
+			for _, key := range m.Keys {
+				c.local.Delete(key)
+			}
+		}
+	}
+}
@@ -164,6 +164,34 @@ func GetRedis() *viper.Viper {
 	return Config.Sub("redis")
 }
 
+/**
+ * GetCache 获取缓存配置子项
+ *
+ * 返回缓存配置组（cache 节）的配置对象。
+ * 未配置时返回 nil，调用方需要自行使用默认值。
+ *
+ * 返回值：
+ *   *viper.Viper - 缓存配置对象
+ *
+ * 配置项示例（config.yaml）：
+ *   cache:
+ *     product_ttl: 600
+ *     null_ttl: 60
+ *     ttl_jitter: 0.1
+ *     local_capacity: 10000
+ *     local_ttl: 5
+ *     list_ttl: 30
+ *     bloom_bits: 16777216
+ *     bloom_hashes: 7
+ *
+ * 使用示例：
+ *   cacheConfig := config.GetCache()
+ *   ttl := cacheConfig.GetInt("product_ttl")
+ */
+func GetCache() *viper.Viper {
+	return Config.Sub("cache")
+}
+
 /**
  * GetApp 获取应用配置子项
  *
@@ -204,6 +232,10 @@ func GetApp() *viper.Viper {
  *     secret: "your-secret-key"
  *     expire_hours: 24
  *     refresh_hours: 168
+ *     signing_key_id: "2026-10"
+ *     keys:
+ *       - id: "2026-10"
+ *         private_key_path: "conf/keys/jwt-2026-10.pem"
  *
  * 使用示例：
  *   jwtConfig := config.GetJWT()
@@ -311,6 +343,81 @@ func GetRateLimit() *viper.Viper {
 	return Config.Sub("ratelimit")
 }
 
+/**
+ * GetMail 获取邮件配置子项
+ *
+ * 返回邮件配置组（mail 节）的配置对象。
+ * 包含发送方式、发件人、邮件中的链接地址和验证链接有效期等配置。
+ *
+ * 返回值：
+ *   *viper.Viper - 邮件配置对象，未配置时为 nil
+ *
+ * 配置项示例（config.yaml）：
+ *   mail:
+ *     driver: "smtp"
+ *     from: "GoMall <no-reply@example.com>"
+ *     link_base_url: "https://shop.example.com"
+ *     smtp:
+ *       host: "smtp.example.com"
+ *       port: 587
+ *
+ * 使用示例：
+ *   mailConfig := config.GetMail()
+ *   driver := mailConfig.GetString("driver")
+ */
+func GetMail() *viper.Viper {
+	return Config.Sub("mail")
+}
+
+/**
+ * GetSms 获取短信配置子项
+ *
+ * 返回短信配置组（sms 节）的配置对象。
+ * 包含发送方式、验证码有效期和发送频率限制等配置。
+ *
+ * 返回值：
+ *   *viper.Viper - 短信配置对象，未配置时为 nil
+ *
+ * 配置项示例（config.yaml）：
+ *   sms:
+ *     driver: "console"
+ *     code_ttl: 300
+ *     resend_interval: 60
+ *     phone_daily_limit: 10
+ *     ip_hourly_limit: 20
+ *     code_secret: ""
+ *
+ * 使用示例：
+ *   smsConfig := config.GetSms()
+ *   driver := smsConfig.GetString("driver")
+ */
+func GetSms() *viper.Viper {
+	return Config.Sub("sms")
+}
+
+/**
+ * GetSecurity 获取安全配置子项
+ *
+ * 返回安全配置组（security 节）的配置对象。
+ * 包含登录失败次数限制和账号锁定时长等配置。
+ *
+ * 返回值：
+ *   *viper.Viper - 安全配置对象，未配置时为 nil
+ *
+ * 配置项示例（config.yaml）：
+ *   security:
+ *     login_max_attempts: 5
+ *     ip_max_attempts: 20
+ *     lockout_duration: 900
+ *
+ * 使用示例：
+ *   securityConfig := config.GetSecurity()
+ *   maxAttempts := securityConfig.GetInt("login_max_attempts")
+ */
+func GetSecurity() *viper.Viper {
+	return Config.Sub("security")
+}
+
 /**
  * GetLogger 获取日志配置子项
  *
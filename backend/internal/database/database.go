@@ -66,10 +66,15 @@ func Init() error {
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
 	// 自动迁移数据库表结构
-	if err := DB.AutoMigrate(&model.User{}, &model.Product{}, &model.Order{}, &model.Stock{}, &model.Cart{}); err != nil {
+	if err := DB.AutoMigrate(&model.User{}, &model.Product{}, &model.Order{}, &model.Stock{}, &model.Cart{}, &model.ProductImage{}, &model.ProductPriceHistory{}, &model.Favorite{}, &model.ProductRecommendation{}, &model.DailyStat{}, &model.Role{}, &model.Permission{}, &model.RolePermission{}, &model.UserTOTP{}, &model.UserBackupCode{}, &model.UserSession{}, &model.AdminAuditLog{}, &model.Shop{}, &model.OrderItem{}); err != nil {
 		return fmt.Errorf("数据库迁移失败: %w", err)
 	}
 
+	// 初始化内置角色和权限
+	if err := seedRBAC(DB); err != nil {
+		return fmt.Errorf("角色权限初始化失败: %w", err)
+	}
+
 	return nil
 }
 
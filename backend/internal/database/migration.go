@@ -75,6 +75,20 @@ func (r *MigrationRunner) autoMigrate() error {
 		&model.Order{},
 		&model.Cart{},
 		&model.Stock{},
+		&model.ProductImage{},
+		&model.ProductPriceHistory{},
+		&model.Favorite{},
+		&model.ProductRecommendation{},
+		&model.DailyStat{},
+		&model.Role{},
+		&model.Permission{},
+		&model.RolePermission{},
+		&model.UserTOTP{},
+		&model.UserBackupCode{},
+		&model.UserSession{},
+		&model.AdminAuditLog{},
+		&model.Shop{},
+		&model.OrderItem{},
 	)
 }
 
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gomall/backend/internal/config"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 内置角色，ID 与 model.RoleXXX 常量一致
+var defaultRoles = []model.Role{
+	{ID: model.RoleUser, Code: "user", Name: "普通用户", Description: "前台购物用户，不能访问管理后台"},
+	{ID: model.RoleAdmin, Code: "admin", Name: "超级管理员", Description: "拥有全部后台权限"},
+	{ID: model.RoleOperator, Code: "operator", Name: "运营", Description: "查看经营统计、管理秒杀、执行维护任务、审核店铺"},
+	{ID: model.RoleCustomerService, Code: "customer_service", Name: "客服", Description: "查看商品后台数据、处理用户账号问题"},
+	{ID: model.RoleMerchandiser, Code: "merchandiser", Name: "商品专员", Description: "管理商品、图集和导入导出"},
+}
+
+// 内置权限
+var defaultPermissions = []model.Permission{
+	{Code: model.PermProductRead, Name: "查看商品后台数据"},
+	{Code: model.PermProductWrite, Name: "商品管理"},
+	{Code: model.PermSeckillManage, Name: "秒杀库存管理"},
+	{Code: model.PermStatsRead, Name: "查看经营统计"},
+	{Code: model.PermSystemMaintain, Name: "维护任务"},
+	{Code: model.PermRoleManage, Name: "角色与权限管理"},
+	{Code: model.PermUserManage, Name: "用户账号管理"},
+	{Code: model.PermShopManage, Name: "店铺审核"},
+	{Code: model.PermShopOperate, Name: "商家后台"},
+}
+
+// 商家角色，店铺审核通过时授予、停业时收回
+var merchantRole = model.Role{Code: model.RoleCodeMerchant, Name: "商家", Description: "店铺审核通过的用户，使用商家后台管理本店"}
+
+// 内置角色的默认权限，超级管理员不受权限表限制，无需配置
+var defaultRolePermissions = map[uint][]string{
+	model.RoleOperator:        {model.PermProductRead, model.PermSeckillManage, model.PermStatsRead, model.PermSystemMaintain, model.PermShopManage},
+	model.RoleCustomerService: {model.PermProductRead, model.PermUserManage},
+	model.RoleMerchandiser:    {model.PermProductRead, model.PermProductWrite},
+}
+
+// seedRBAC 初始化内置角色和权限
+// 已存在的角色和权限不会被覆盖；每个默认的（角色, 权限）关联逐条补齐，新版本增加的默认权限也会写入已部署的库
+// 只在首次初始化时执行的步骤，之后的角色调整通过后台接口完成，重启不会覆盖：
+// - 库中还没有超级管理员时，把 app.admin_ids 中的用户设为超级管理员
+// - 商家角色首次创建时，营业中店铺的普通用户店主补授商家角色
+func seedRBAC(db *gorm.DB) error {
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&defaultRoles).Error; err != nil {
+		return err
+	}
+	merchant := merchantRole
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&merchant)
+	if result.Error != nil {
+		return result.Error
+	}
+	merchantCreated := result.RowsAffected > 0
+	if err := db.Where("code = ?", model.RoleCodeMerchant).First(&merchant).Error; err != nil {
+		return err
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&defaultPermissions).Error; err != nil {
+		return err
+	}
+
+	var permissions []model.Permission
+	if err := db.Find(&permissions).Error; err != nil {
+		return err
+	}
+	permissionIDs := make(map[string]uint, len(permissions))
+	for _, p := range permissions {
+		permissionIDs[p.Code] = p.ID
+	}
+
+	rolePermissionSeeds := map[uint][]string{merchant.ID: {model.PermShopOperate}}
+	for roleID, codes := range defaultRolePermissions {
+		rolePermissionSeeds[roleID] = codes
+	}
+	// (role_id, permission_id) 有唯一索引，已存在的关联跳过
+	var rolePermissions []model.RolePermission
+	for roleID, codes := range rolePermissionSeeds {
+		for _, code := range codes {
+			rolePermissions = append(rolePermissions, model.RolePermission{RoleID: roleID, PermissionID: permissionIDs[code]})
+		}
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rolePermissions).Error; err != nil {
+		return err
+	}
+
+	if merchantCreated {
+		err := db.Model(&model.User{}).
+			Where("role = ? AND id IN (?)", model.RoleUser,
+				db.Model(&model.Shop{}).Select("owner_id").Where("status = ?", model.ShopStatusApproved)).
+			Update("role", merchant.ID).Error
+		if err != nil {
+			return fmt.Errorf("初始化商家角色失败: %w", err)
+		}
+	}
+
+	// app.admin_ids 只用于初始化第一批超级管理员，已有超级管理员时不再处理，后台降级不会在重启后被撤销
+	var adminCount int64
+	if err := db.Model(&model.User{}).Where("role = ?", model.RoleAdmin).Count(&adminCount).Error; err != nil {
+		return err
+	}
+	var adminIDs []uint
+	for _, idStr := range strings.Split(config.GetApp().GetString("admin_ids"), ",") {
+		if id, err := strconv.ParseUint(strings.TrimSpace(idStr), 10, 32); err == nil {
+			adminIDs = append(adminIDs, uint(id))
+		}
+	}
+	if adminCount == 0 && len(adminIDs) > 0 {
+		err := db.Model(&model.User{}).
+			Where("id IN ? AND role = ?", adminIDs, model.RoleUser).
+			Update("role", model.RoleAdmin).Error
+		if err != nil {
+			return fmt.Errorf("初始化管理员失败: %w", err)
+		}
+	}
+	return nil
+}
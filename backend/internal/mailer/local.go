@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"gomall/backend/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// FileMailer 将邮件写入本地目录，每封邮件一个 .eml 文件，便于本地开发查看
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer 创建文件邮件发送器
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Send 写入 <时间>_<收件人>.eml
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("创建邮件目录失败: %w", err)
+	}
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	if err := os.WriteFile(filepath.Join(m.dir, name), buildMessage(m.from, msg), 0o600); err != nil {
+		return fmt.Errorf("写入邮件失败: %w", err)
+	}
+	logger.Info("邮件已写入文件", zap.String("to", msg.To), zap.String("file", name))
+	return nil
+}
+
+// LogMailer 只把邮件内容写入日志，不实际发送
+type LogMailer struct{}
+
+// NewLogMailer 创建日志邮件发送器
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send 记录邮件内容
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	logger.Info("邮件（未实际发送）", zap.String("to", msg.To), zap.String("subject", msg.Subject), zap.String("body", msg.Body))
+	return nil
+}
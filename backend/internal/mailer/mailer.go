@@ -0,0 +1,76 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"gomall/backend/internal/config"
+)
+
+// Mailer 邮件发送接口
+// 内置三种实现：SMTPMailer（生产）、FileMailer（写入本地 .eml 文件）、LogMailer（写入日志），
+// 本地开发和测试无需邮件服务器
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Message 邮件内容，Body 为纯文本
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// 发送方式，对应 mail.driver
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+// Default 全局邮件发送器，Init 之前为 LogMailer
+var Default Mailer = NewLogMailer()
+
+// Init 按 mail.driver 初始化全局邮件发送器，未配置时使用 LogMailer
+// 发件人和 SMTP 配置支持 "${ENV}" 占位符，从环境变量读取
+func Init() error {
+	mailConfig := config.GetMail()
+	if mailConfig == nil {
+		Default = NewLogMailer()
+		return nil
+	}
+
+	from := os.ExpandEnv(mailConfig.GetString("from"))
+	switch driver := strings.ToLower(mailConfig.GetString("driver")); driver {
+	case DriverSMTP:
+		smtpConfig := mailConfig.Sub("smtp")
+		if smtpConfig == nil || os.ExpandEnv(smtpConfig.GetString("host")) == "" {
+			return fmt.Errorf("mail.smtp.host 未配置")
+		}
+		Default = NewSMTPMailer(SMTPConfig{
+			Host:     os.ExpandEnv(smtpConfig.GetString("host")),
+			Port:     smtpConfig.GetInt("port"),
+			Username: os.ExpandEnv(smtpConfig.GetString("username")),
+			Password: os.ExpandEnv(smtpConfig.GetString("password")),
+			From:     from,
+		})
+	case DriverFile:
+		dir := mailConfig.GetString("file_dir")
+		if dir == "" {
+			dir = "logs/mail"
+		}
+		Default = NewFileMailer(dir, from)
+	case "", DriverLog:
+		Default = NewLogMailer()
+	default:
+		return fmt.Errorf("不支持的邮件发送方式: %s", driver)
+	}
+	return nil
+}
+
+// Send 使用全局邮件发送器发送邮件
+func Send(ctx context.Context, msg *Message) error {
+	return Default.Send(ctx, msg)
+}
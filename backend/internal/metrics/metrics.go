@@ -133,6 +133,15 @@ var (
 		[]string{"queue", "operation"},
 	)
 
+	// 缓存指标
+	CacheRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gomall_cache_requests_total",
+			Help: "Total number of two-level cache lookups",
+		},
+		[]string{"cache", "level", "result"},
+	)
+
 	// 用户指标
 	UserLoginsTotal = promauto.NewCounter(
 		prometheus.CounterOpts{
@@ -148,6 +157,29 @@ var (
 		},
 	)
 
+	LoginFailuresTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "gomall_login_failures_total",
+			Help: "Total number of failed login attempts",
+		},
+	)
+
+	LoginLockoutsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gomall_login_lockouts_total",
+			Help: "Total number of login lockouts triggered by repeated failures",
+		},
+		[]string{"scope"},
+	)
+
+	LoginBlockedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gomall_login_blocked_total",
+			Help: "Total number of login attempts rejected while locked",
+		},
+		[]string{"scope"},
+	)
+
 	ActiveUsers = promauto.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "gomall_active_users",
@@ -186,6 +218,21 @@ func RecordUserLogin() {
 	UserLoginsTotal.Inc()
 }
 
+// RecordLoginFailure 记录登录失败
+func RecordLoginFailure() {
+	LoginFailuresTotal.Inc()
+}
+
+// RecordLoginLockout 记录触发登录锁定（scope: account/ip）
+func RecordLoginLockout(scope string) {
+	LoginLockoutsTotal.WithLabelValues(scope).Inc()
+}
+
+// RecordLoginBlocked 记录锁定期间被拒绝的登录（scope: account/ip）
+func RecordLoginBlocked(scope string) {
+	LoginBlockedTotal.WithLabelValues(scope).Inc()
+}
+
 // RecordUserRegister 记录用户注册
 func RecordUserRegister() {
 	UserRegistrationsTotal.Inc()
@@ -200,6 +247,15 @@ func RecordRedisOperation(operation string, success bool) {
 	RedisOperationsTotal.WithLabelValues(operation, status).Inc()
 }
 
+// RecordCacheLookup 记录缓存查询（level: local/redis）
+func RecordCacheLookup(cache, level string, hit bool) {
+	result := "hit"
+	if !hit {
+		result = "miss"
+	}
+	CacheRequestsTotal.WithLabelValues(cache, level, result).Inc()
+}
+
 // RecordRabbitMQMessagePublished 记录消息发布
 func RecordRabbitMQMessagePublished(queue string) {
 	RabbitMQMessagesPublished.WithLabelValues(queue).Inc()
@@ -1,13 +1,18 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"gomall/backend/internal/config"
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/response"
+	"gomall/backend/internal/service"
 	"gomall/backend/pkg/jwt"
+
+	"go.uber.org/zap"
 )
 
 // AuthMiddleware JWT认证中间件
@@ -50,10 +55,41 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 检查 Token 是否已被吊销（退出登录、退出所有设备、修改密码）
+		if !ensureTokenActive(c, claims) {
+			return
+		}
+
 		// 将用户信息存入上下文
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
+		c.Set("claims", claims)
+
+		// 更新登录会话的最近活跃时间（节流）
+		service.TouchSession(c.Request.Context(), claims)
+
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware 可选认证中间件
+// 用于登录与否都可访问、登录后返回个性化信息的接口（如商品详情中的收藏状态）
+// 携带有效 Token 时写入用户信息，未携带或无效时按游客处理，不会中断请求
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			jwtUtil := jwt.NewJWT()
+			if claims, err := jwtUtil.ParseToken(parts[1]); err == nil && service.CheckToken(c.Request.Context(), claims) == nil {
+				c.Set("user_id", claims.UserID)
+				c.Set("username", claims.Username)
+				c.Set("email", claims.Email)
+				c.Set("role", claims.Role)
+				c.Set("claims", claims)
+			}
+		}
 
 		c.Next()
 	}
@@ -77,8 +113,83 @@ func GetUsername(c *gin.Context) string {
 	return username.(string)
 }
 
+// GetClaims 从上下文中获取当前请求的 Token 载荷，未登录时返回 nil
+func GetClaims(c *gin.Context) *jwt.Claims {
+	claims, exists := c.Get("claims")
+	if !exists {
+		return nil
+	}
+	return claims.(*jwt.Claims)
+}
+
+// GetRole 从上下文中获取当前用户角色ID
+func GetRole(c *gin.Context) int {
+	return c.GetInt("role")
+}
+
+// ensureTokenActive 校验 Token 未被吊销、账号状态正常，否则写入响应并中断请求
+func ensureTokenActive(c *gin.Context, claims *jwt.Claims) bool {
+	err := service.CheckToken(c.Request.Context(), claims)
+	if err == nil {
+		return true
+	}
+
+	switch {
+	case errors.Is(err, service.ErrUserDisabled):
+		c.JSON(http.StatusForbidden, gin.H{
+			"code": response.CodeUserDisabled,
+			"msg":  err.Error(),
+		})
+	case errors.Is(err, service.ErrPasswordResetRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code": response.CodeUserAccountLocked,
+			"msg":  err.Error(),
+		})
+	case errors.Is(err, service.ErrTokenRevoked):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code": 401,
+			"msg":  err.Error(),
+		})
+	default:
+		logger.Error("Token校验失败", zap.Uint("user_id", claims.UserID), zap.Error(err))
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"code": 503,
+			"msg":  "服务暂时不可用",
+		})
+	}
+	c.Abort()
+	return false
+}
+
+// RequireVerifiedEmail 邮箱验证校验中间件
+// 需在 AuthMiddleware 之后使用，用于下单、支付等敏感操作；mail.require_verified_email 关闭时不校验
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := service.EnsureEmailVerified(GetUserID(c))
+		if err == nil {
+			c.Next()
+			return
+		}
+
+		if errors.Is(err, service.ErrEmailNotVerified) || errors.Is(err, service.ErrEmailNotSet) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": response.CodeUserEmailNotVerified,
+				"msg":  err.Error(),
+			})
+		} else {
+			logger.Error("邮箱验证状态读取失败", zap.Uint("user_id", GetUserID(c)), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": 500,
+				"msg":  "邮箱验证状态读取失败",
+			})
+		}
+		c.Abort()
+	}
+}
+
 // AdminAuthMiddleware 管理员权限认证中间件
-// 用于保护需要管理员权限才能访问的接口
+// 用于保护管理后台接口，普通用户以外的角色均可通过，具体权限由 RequirePermission 校验
+// security.require_admin_2fa 开启时还要求 Token 带两步验证标记
 func AdminAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 先执行登录认证
@@ -115,42 +226,63 @@ func AdminAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 检查 Token 是否已被吊销（退出登录、退出所有设备、修改密码）
+		if !ensureTokenActive(c, claims) {
+			return
+		}
+
 		// 将用户信息存入上下文
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
+		c.Set("claims", claims)
 
-		// 从配置获取管理员ID列表，支持多个管理员
-		appConfig := config.GetApp()
-		adminIDsStr := appConfig.GetString("admin_ids")
-		var adminIDs []uint
-		if adminIDsStr != "" {
-			// 支持逗号分隔的多个管理员ID
-			for _, idStr := range strings.Split(adminIDsStr, ",") {
-				if id, err := strconv.ParseUint(strings.TrimSpace(idStr), 10, 32); err == nil {
-					adminIDs = append(adminIDs, uint(id))
-				}
-			}
+		// 普通用户不能访问管理后台
+		if claims.Role == 0 || claims.Role == model.RoleUser {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": 403,
+				"msg":  "权限不足，需要管理员权限",
+			})
+			c.Abort()
+			return
 		}
 
-		// 默认管理员ID为1（向后兼容）
-		if len(adminIDs) == 0 {
-			adminIDs = []uint{1}
+		// security.require_admin_2fa 开启时，后台账号必须通过两步验证登录
+		if !claims.MFA && service.RequiresTwoFactor(claims.Role) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": response.CodeUserTwoFactorRequired,
+				"msg":  "管理后台需要两步验证，请启用两步验证后重新登录",
+			})
+			c.Abort()
+			return
 		}
 
-		// 检查当前用户是否为管理员
-		isAdmin := false
-		for _, adminID := range adminIDs {
-			if claims.UserID == adminID {
-				isAdmin = true
-				break
-			}
+		service.TouchSession(c.Request.Context(), claims)
+
+		c.Next()
+	}
+}
+
+// RequirePermission 权限校验中间件
+// 需在 AdminAuthMiddleware 之后使用，当前角色没有 permission 权限时返回 403
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, err := service.HasPermission(GetRole(c), permission)
+		if err != nil {
+			logger.Error("权限校验失败", zap.String("permission", permission), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": 500,
+				"msg":  "权限校验失败",
+			})
+			c.Abort()
+			return
 		}
 
-		if !isAdmin {
+		if !ok {
 			c.JSON(http.StatusForbidden, gin.H{
 				"code": 403,
-				"msg":  "权限不足，需要管理员权限",
+				"msg":  "权限不足，需要 " + permission + " 权限",
 			})
 			c.Abort()
 			return
@@ -159,3 +291,66 @@ func AdminAuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// MerchantMiddleware 商家后台中间件
+// 需在 AuthMiddleware 之后使用，当前角色必须拥有 shop:operate 权限，
+// 且当前用户拥有审核通过且未停业的店铺，店铺ID存入上下文
+func MerchantMiddleware() gin.HandlerFunc {
+	shopService := service.NewShopService()
+	return func(c *gin.Context) {
+		ok, err := service.HasPermission(GetRole(c), model.PermShopOperate)
+		if err != nil {
+			logger.Error("权限校验失败", zap.String("permission", model.PermShopOperate), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": 500,
+				"msg":  "权限校验失败",
+			})
+			c.Abort()
+			return
+		}
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": 403,
+				"msg":  "权限不足，需要商家权限",
+			})
+			c.Abort()
+			return
+		}
+
+		shopID, err := shopService.OpenShopID(GetUserID(c))
+		if err == nil {
+			c.Set("shop_id", shopID)
+			c.Next()
+			return
+		}
+
+		switch {
+		case errors.Is(err, service.ErrShopNotFound):
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": response.CodeShopNotFound,
+				"msg":  "尚未开店",
+			})
+		case errors.Is(err, service.ErrShopNotOpen):
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": response.CodeShopNotOpen,
+				"msg":  err.Error(),
+			})
+		default:
+			logger.Error("店铺状态读取失败", zap.Uint("user_id", GetUserID(c)), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": 500,
+				"msg":  "店铺状态读取失败",
+			})
+		}
+		c.Abort()
+	}
+}
+
+// GetShopID 获取商家后台当前店铺ID
+func GetShopID(c *gin.Context) uint {
+	shopID, exists := c.Get("shop_id")
+	if !exists {
+		return 0
+	}
+	return shopID.(uint)
+}
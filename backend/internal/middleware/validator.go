@@ -240,6 +240,14 @@ func formatValidationErrors(errs validator.ValidationErrors) string {
 	return strings.Join(errMsgs, "; ")
 }
 
+// FormatValidationError 格式化验证错误，供不经过绑定中间件的场景使用（如批量导入逐行校验）
+func FormatValidationError(err error) string {
+	if errs, ok := err.(validator.ValidationErrors); ok {
+		return formatValidationErrors(errs)
+	}
+	return err.Error()
+}
+
 // getFieldName 获取字段的中文名称
 func getFieldName(field, structField string) string {
 	// 如果 structField 存在，返回结构体字段名
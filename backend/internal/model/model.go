@@ -19,6 +19,20 @@ package model
  * - orders: 订单表
  * - stocks: 库存表
  * - carts: 购物车表
+ * - product_images: 商品图片表
+ * - product_price_history: 商品价格变更记录表
+ * - favorites: 用户收藏表
+ * - product_recommendations: 商品关联推荐表
+ * - daily_stats: 每日经营统计快照表
+ * - roles: 角色表
+ * - permissions: 权限表
+ * - role_permissions: 角色权限关联表
+ * - user_totps: 两步验证密钥表
+ * - user_backup_codes: 两步验证备用码表
+ * - user_sessions: 登录会话表
+ * - admin_audit_logs: 后台操作审计日志表
+ * - shops: 店铺表
+ * - order_items: 订单商品明细表
  */
 
 import (
@@ -30,11 +44,13 @@ import (
 /**
  * User 用户模型
  *
- * 存储用户账号信息，支持普通用户和管理员两种角色。
+ * 存储用户账号信息，Role 为 roles 表的角色ID。
  *
  * 角色说明：
  * - Role = 1: 普通用户，可以浏览商品、下单购买
- * - Role = 2: 管理员，可以管理商品、查看统计数据
+ * - Role = 2: 超级管理员，拥有全部后台权限
+ * - Role = 3/4/5: 运营、客服、商品专员，后台权限由 role_permissions 决定
+ * - 管理员新建的角色和商家角色（merchant）从 6 开始，商家角色按编码 RoleCodeMerchant 查找
  *
  * 密码安全：
  * - Password 字段使用 bcrypt 加密存储
@@ -44,6 +60,8 @@ import (
  * - 使用 gorm.DeletedAt 实现软删除
  * - 删除用户时不会物理删除数据，而是设置 deleted_at 字段
  * - 查询时会自动过滤已删除的记录
+ * - 用户注销账号时先清除用户名、邮箱、手机号等个人信息再软删除，
+ *   用户名和邮箱可以被重新注册（见 repository/user_profile.go）
  */
 type User struct {
 	// ID 用户唯一标识，自增主键
@@ -53,6 +71,12 @@ type User struct {
 	// 用于登录和显示
 	Username string `gorm:"column:username;uniqueIndex;size:50" json:"username"`
 
+	// Nickname 昵称，长度50，为空时前端显示用户名
+	Nickname string `gorm:"column:nickname;size:50" json:"nickname"`
+
+	// Avatar 头像URL，只能使用上传服务返回的地址
+	Avatar string `gorm:"column:avatar;size:500" json:"avatar"`
+
 	// Password 加密后的密码
 	// 使用 bcrypt 算法加密，长度255
 	// json 标签 "-" 确保不会序列化到 JSON
@@ -63,13 +87,29 @@ type User struct {
 	Email string `gorm:"column:email;uniqueIndex;size:100" json:"email"`
 
 	// Phone 用户手机号，长度20
-	// 可选字段，用于接收短信通知
-	Phone string `gorm:"column:phone;size:20" json:"phone"`
+	// 可选字段，用于接收短信通知；普通索引，绑定时加锁查询已验证的记录
+	Phone string `gorm:"column:phone;size:20;index" json:"phone"`
+
+	// PhoneVerifiedAt 手机号验证时间，未验证为 NULL
+	// 只有通过短信验证码绑定的手机号才能用于验证码登录
+	PhoneVerifiedAt *time.Time `gorm:"column:phone_verified_at" json:"phone_verified_at"`
 
-	// Role 用户角色，默认普通用户(1)
-	// 1: 普通用户, 2: 管理员
+	// Role 用户角色ID，默认普通用户(1)
+	// 对应 roles 表，登录时写入 JWT
 	Role int `gorm:"column:role;default:1" json:"role"`
 
+	// EmailVerifiedAt 邮箱验证时间，未验证为 NULL
+	// 修改邮箱后需要重新验证
+	EmailVerifiedAt *time.Time `gorm:"column:email_verified_at" json:"email_verified_at"`
+
+	// Status 账号状态，默认正常(1)
+	// 禁用、锁定的账号不能登录，已签发的 Token 随状态变更一并失效
+	Status int `gorm:"column:status;not null;default:1;index" json:"status"`
+
+	// TokenVersion Token 版本，签发时写入 JWT
+	// 修改密码、退出所有设备等操作递增版本，使该用户已签发的 Token 全部失效
+	TokenVersion int64 `gorm:"column:token_version;not null;default:0" json:"-"`
+
 	// CreatedAt 创建时间
 	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
 
@@ -86,8 +126,24 @@ type User struct {
  * 用户角色常量定义
  */
 const (
-	RoleUser  = 1 // 普通用户，可以进行正常的购物操作
-	RoleAdmin = 2 // 管理员，拥有管理商品的权限
+	RoleUser            = 1 // 普通用户，可以进行正常的购物操作
+	RoleAdmin           = 2 // 超级管理员，拥有全部后台权限
+	RoleOperator        = 3 // 运营，查看统计、管理秒杀和维护任务
+	RoleCustomerService = 4 // 客服，查看商品后台数据
+	RoleMerchandiser    = 5 // 商品专员，管理商品
+)
+
+// RoleCodeMerchant 商家角色编码
+// 商家角色在已有自定义角色的库中补建，不占用固定ID，按编码查找
+const RoleCodeMerchant = "merchant"
+
+/**
+ * 用户账号状态常量定义
+ */
+const (
+	UserStatusActive   = 1 // 正常
+	UserStatusDisabled = 2 // 已禁用，由管理员启用后才能登录
+	UserStatusLocked   = 3 // 已锁定，管理员要求重置密码，用户通过找回密码邮件重置后恢复正常
 )
 
 /**
@@ -112,6 +168,15 @@ func (User) TableName() string {
  * - Status = 1: 上架状态，用户可以看到并购买
  * - Status = 0: 下架状态，用户无法购买
  *
+ * 所属店铺：
+ * - ShopID 为 0 表示平台自营商品，由后台商品管理维护
+ * - 其他商品由店铺商家在商家后台维护，店铺未通过审核或已停业时不展示、不能下单
+ *
+ * 定时上下架：
+ * - PublishAt 到期后自动上架，UnpublishAt 到期后自动下架
+ * - 定时任务执行后清空已生效的时间字段
+ * - 定时任务延迟时，通过 EffectiveStatus 按时间计算实际状态
+ *
  * 价格精度：
  * - 使用 DECIMAL(10,2) 类型存储价格
  * - 精度为10位，小数点后2位
@@ -120,6 +185,13 @@ type Product struct {
 	// ID 商品唯一标识，自增主键
 	ID uint `gorm:"column:id;primarykey" json:"id"`
 
+	// ShopID 所属店铺ID，0 表示平台自营
+	ShopID uint `gorm:"column:shop_id;not null;default:0;index" json:"shop_id"`
+
+	// SKU 外部商品编码，批量导入时按此字段更新已有商品
+	// 使用指针类型，未设置时为 NULL，唯一索引允许多个 NULL
+	SKU *string `gorm:"column:sku;size:64;uniqueIndex" json:"sku,omitempty"`
+
 	// Name 商品名称，必填，长度200
 	Name string `gorm:"column:name;size:200;not null" json:"name"`
 
@@ -127,6 +199,10 @@ type Product struct {
 	// 使用 TEXT 类型，支持长文本
 	Description string `gorm:"column:description;type:text" json:"description"`
 
+	// Detail 商品详情（富文本/HTML）
+	// 保存前经过白名单清洗，防止 XSS
+	Detail string `gorm:"column:detail;type:longtext" json:"detail"`
+
 	// Price 商品价格，必填
 	// DECIMAL(10,2) 存储，确保金额精度
 	Price float64 `gorm:"column:price;not null;precision:10;scale:2" json:"price"`
@@ -140,13 +216,22 @@ type Product struct {
 	Category string `gorm:"column:category;size:50" json:"category"`
 
 	// ImageURL 商品图片URL，长度500
-	// 存储商品主图的访问地址
+	// 存储商品主图的访问地址，与图集中的主图保持同步
 	ImageURL string `gorm:"column:image_url;size:500" json:"image_url"`
 
 	// Status 商品状态，默认上架(1)
 	// 1: 上架, 0: 下架
 	Status int `gorm:"column:status;default:1" json:"status"`
 
+	// PublishAt 定时上架时间，为空表示没有待执行的上架计划
+	PublishAt *time.Time `gorm:"column:publish_at;index" json:"publish_at"`
+
+	// UnpublishAt 定时下架时间，为空表示没有待执行的下架计划
+	UnpublishAt *time.Time `gorm:"column:unpublish_at;index" json:"unpublish_at"`
+
+	// FavoriteCount 收藏人数，收藏/取消收藏时与收藏记录在同一事务中增减
+	FavoriteCount int `gorm:"column:favorite_count;not null;default:0" json:"favorite_count"`
+
 	// CreatedAt 创建时间
 	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
 
@@ -164,6 +249,71 @@ func (Product) TableName() string {
 	return "products"
 }
 
+/**
+ * EffectiveStatus 按定时计划计算商品在 now 时刻的实际状态
+ *
+ * 定时任务可能延迟执行，展示和下单都以此方法为准：
+ * - 下架时间已到：下架
+ * - 上架时间已到：上架
+ * - 否则：Status 字段
+ */
+func (p *Product) EffectiveStatus(now time.Time) int {
+	if p.UnpublishAt != nil && !now.Before(*p.UnpublishAt) {
+		return 0
+	}
+	if p.PublishAt != nil && !now.Before(*p.PublishAt) {
+		return 1
+	}
+	return p.Status
+}
+
+/**
+ * IsOnShelf 商品在 now 时刻是否处于上架状态
+ */
+func (p *Product) IsOnShelf(now time.Time) bool {
+	return p.EffectiveStatus(now) == 1
+}
+
+/**
+ * ProductImage 商品图片模型
+ *
+ * 存储商品图集，一个商品可以有多张图片。
+ *
+ * 设计特点：
+ * - Sort 字段决定图集展示顺序（升序）
+ * - IsMain 标记主图，每个商品最多一张主图
+ * - 主图 URL 会同步写回 Product.ImageURL，订单快照沿用该字段
+ */
+type ProductImage struct {
+	// ID 图片记录唯一标识，自增主键
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// ProductID 所属商品ID，索引
+	ProductID uint `gorm:"column:product_id;index;not null" json:"product_id"`
+
+	// URL 图片访问地址，通常来自 /api/upload 的返回值
+	URL string `gorm:"column:url;size:500;not null" json:"url"`
+
+	// Sort 排序值，越小越靠前
+	Sort int `gorm:"column:sort;not null;default:0" json:"sort"`
+
+	// IsMain 是否为主图
+	IsMain bool `gorm:"column:is_main;not null;default:false" json:"is_main"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+
+	// UpdatedAt 最后更新时间
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+/**
+ * TableName 指定 ProductImage 结构体对应的数据库表名
+ */
+func (ProductImage) TableName() string {
+	return "product_images"
+}
+
 /**
  * Order 订单模型
  *
@@ -184,6 +334,11 @@ func (Product) TableName() string {
  * 订单号生成规则：
  * - 格式：ORD + 时间戳(YYYYMMDDHHmmss) + 4位随机数
  * - 例如：ORD202401011200001234
+ *
+ * 购物车结算（拆单）：
+ * - 父订单（OrderType = 3）用于支付，TotalPrice 为实付总额，ProductID 为 0
+ * - 每个店铺一张子订单（ParentID 指向父订单），商品明细在 order_items，ProductID 为 0
+ * - 父订单支付或取消时，子订单随之更新；发货、退款按子订单进行
  */
 type Order struct {
 	// ID 订单唯一标识，自增主键
@@ -196,9 +351,18 @@ type Order struct {
 	// UserID 下单用户ID，外键关联 users 表
 	UserID uint `gorm:"column:user_id;index;not null" json:"user_id"`
 
+	// ParentID 父订单ID，0 表示没有父订单
+	// 购物车结算生成的子订单指向用于支付的父订单
+	ParentID uint `gorm:"column:parent_id;not null;default:0;index" json:"parent_id"`
+
 	// ProductID 购买商品ID，外键关联 products 表
+	// 父订单和子订单为 0，商品明细见 order_items
 	ProductID uint `gorm:"column:product_id;index;not null" json:"product_id"`
 
+	// ShopID 商品所属店铺ID（下单时的快照），0 表示平台自营
+	// 商家后台按此字段查询和发货
+	ShopID uint `gorm:"column:shop_id;not null;default:0;index" json:"shop_id"`
+
 	// ProductName 下单时的商品名称（冗余存储）
 	// 商品信息变更时不影响历史订单
 	ProductName string `gorm:"column:product_name;size:200" json:"product_name"`
@@ -222,6 +386,14 @@ type Order struct {
 	// 1: 支付宝, 2: 微信
 	PayType int `gorm:"column:pay_type;default:1" json:"pay_type"`
 
+	// PaidAt 支付时间，未支付时为空
+	// 销量排行榜按支付时间统计
+	PaidAt *time.Time `gorm:"column:paid_at;index" json:"paid_at"`
+
+	// OrderType 订单类型，默认普通订单(1)
+	// 1: 普通订单, 2: 秒杀订单, 3: 合并支付父订单
+	OrderType int `gorm:"column:order_type;not null;default:1;index" json:"order_type"`
+
 	// CreatedAt 创建时间
 	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
 
@@ -232,6 +404,15 @@ type Order struct {
 	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index" json:"-"`
 }
 
+/**
+ * 订单类型常量定义
+ */
+const (
+	OrderTypeNormal   = 1 // 普通订单
+	OrderTypeSeckill  = 2 // 秒杀订单
+	OrderTypeCombined = 3 // 合并支付父订单（购物车结算）
+)
+
 /**
  * TableName 指定 Order 结构体对应的数据库表名
  */
@@ -330,3 +511,563 @@ type Cart struct {
 func (Cart) TableName() string {
 	return "carts"
 }
+
+/**
+ * ProductPriceHistory 商品价格变更记录模型
+ *
+ * 每次商品价格变化时写入一条记录，与商品更新在同一事务中完成。
+ * 用于价格争议追溯，以及计算"近30天最低价"。
+ *
+ * 设计特点：
+ * - 只增不改，不支持软删除
+ * - (product_id, created_at) 联合索引，支持按时间窗口查询
+ */
+type ProductPriceHistory struct {
+	// ID 记录唯一标识，自增主键
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// ProductID 商品ID
+	ProductID uint `gorm:"column:product_id;not null;index:idx_price_history_product_time,priority:1" json:"product_id"`
+
+	// OldPrice 变更前价格
+	OldPrice float64 `gorm:"column:old_price;not null;precision:10;scale:2" json:"old_price"`
+
+	// NewPrice 变更后价格
+	NewPrice float64 `gorm:"column:new_price;not null;precision:10;scale:2" json:"new_price"`
+
+	// OperatorID 操作人用户ID
+	OperatorID uint `gorm:"column:operator_id;not null;default:0" json:"operator_id"`
+
+	// Reason 调价原因
+	Reason string `gorm:"column:reason;size:255" json:"reason"`
+
+	// CreatedAt 变更时间
+	CreatedAt time.Time `gorm:"column:created_at;index:idx_price_history_product_time,priority:2" json:"created_at"`
+}
+
+/**
+ * TableName 指定 ProductPriceHistory 结构体对应的数据库表名
+ */
+func (ProductPriceHistory) TableName() string {
+	return "product_price_history"
+}
+
+/**
+ * Favorite 用户收藏模型
+ *
+ * 记录用户收藏的商品，收藏列表展示商品的实时价格和库存。
+ *
+ * 设计特点：
+ * - (user_id, product_id) 联合唯一索引，重复收藏是幂等操作
+ * - 取消收藏直接物理删除，不支持软删除
+ * - 商品的收藏人数冗余在 Product.FavoriteCount，避免每次 COUNT
+ */
+type Favorite struct {
+	// ID 收藏记录唯一标识，自增主键
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// UserID 用户ID，与 ProductID 组合成联合唯一索引
+	UserID uint `gorm:"column:user_id;not null;uniqueIndex:idx_favorite_user_product,priority:1" json:"user_id"`
+
+	// ProductID 商品ID
+	ProductID uint `gorm:"column:product_id;not null;index;uniqueIndex:idx_favorite_user_product,priority:2" json:"product_id"`
+
+	// CreatedAt 收藏时间
+	CreatedAt time.Time `gorm:"column:created_at;index" json:"created_at"`
+}
+
+/**
+ * TableName 指定 Favorite 结构体对应的数据库表名
+ */
+func (Favorite) TableName() string {
+	return "favorites"
+}
+
+/**
+ * ProductRecommendation 商品关联推荐模型（"经常一起购买"）
+ *
+ * 由离线任务根据订单和购物车计算商品共现关系后整体重建，接口只读。
+ *
+ * 字段说明：
+ * - CoCount: 两个商品出现在同一购物篮中的次数
+ * - Score: 关联度（共现次数按两者各自出现次数做余弦归一化），越大越相关
+ *
+ * 设计特点：
+ * - (product_id, recommended_id) 联合唯一索引
+ * - (product_id, score) 联合索引，按关联度读取前 N 个推荐
+ */
+type ProductRecommendation struct {
+	// ID 记录唯一标识，自增主键
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// ProductID 商品ID
+	ProductID uint `gorm:"column:product_id;not null;uniqueIndex:idx_recommendation_pair,priority:1;index:idx_recommendation_score,priority:1" json:"product_id"`
+
+	// RecommendedID 推荐的商品ID
+	RecommendedID uint `gorm:"column:recommended_id;not null;uniqueIndex:idx_recommendation_pair,priority:2" json:"recommended_id"`
+
+	// CoCount 共现次数
+	CoCount int `gorm:"column:co_count;not null;default:0" json:"co_count"`
+
+	// Score 关联度
+	Score float64 `gorm:"column:score;not null;default:0;index:idx_recommendation_score,priority:2" json:"score"`
+
+	// CreatedAt 计算时间
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+/**
+ * TableName 指定 ProductRecommendation 结构体对应的数据库表名
+ */
+func (ProductRecommendation) TableName() string {
+	return "product_recommendations"
+}
+
+/**
+ * DailyStat 每日经营统计快照模型
+ *
+ * 每天凌晨由定时任务汇总前几天的订单和用户数据写入，统计接口查询历史日期时直接读取，
+ * 避免每次都扫描订单表。当天的数据实时计算，不写快照。
+ *
+ * 统计口径：
+ * - GMV / PaidOrders: 当天支付的订单（已支付、已发货、已完成）
+ * - CreatedOrders / CancelledOrders: 当天创建的订单及其中已取消的订单
+ * - NewUsers: 当天注册的用户
+ */
+type DailyStat struct {
+	// ID 记录唯一标识，自增主键
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// StatDate 统计日期，格式 2006-01-02，唯一索引
+	StatDate string `gorm:"column:stat_date;size:10;not null;uniqueIndex" json:"stat_date"`
+
+	// GMV 成交总额
+	GMV float64 `gorm:"column:gmv;not null;default:0;precision:14;scale:2" json:"gmv"`
+
+	// PaidOrders 支付订单数
+	PaidOrders int64 `gorm:"column:paid_orders;not null;default:0" json:"paid_orders"`
+
+	// CreatedOrders 创建订单数
+	CreatedOrders int64 `gorm:"column:created_orders;not null;default:0" json:"created_orders"`
+
+	// CancelledOrders 当天创建且已取消的订单数
+	CancelledOrders int64 `gorm:"column:cancelled_orders;not null;default:0" json:"cancelled_orders"`
+
+	// NewUsers 新注册用户数
+	NewUsers int64 `gorm:"column:new_users;not null;default:0" json:"new_users"`
+
+	// CreatedAt 快照创建时间
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+
+	// UpdatedAt 快照最后刷新时间
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+/**
+ * TableName 指定 DailyStat 结构体对应的数据库表名
+ */
+func (DailyStat) TableName() string {
+	return "daily_stats"
+}
+
+/**
+ * Role 角色模型
+ *
+ * 后台角色，User.Role 存储角色ID。
+ * 内置角色（ID 1-5）在启动时自动创建，管理员也可以新建角色。
+ *
+ * 权限说明：
+ * - 角色拥有的权限记录在 role_permissions 表中
+ * - 超级管理员（RoleAdmin）拥有全部权限，不受 role_permissions 限制
+ * - 普通用户（RoleUser）不能访问管理后台
+ */
+type Role struct {
+	// ID 角色唯一标识，与 User.Role 对应
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// Code 角色编码，唯一，如 admin、operator
+	Code string `gorm:"column:code;size:50;uniqueIndex;not null" json:"code"`
+
+	// Name 角色名称
+	Name string `gorm:"column:name;size:50;not null" json:"name"`
+
+	// Description 角色说明
+	Description string `gorm:"column:description;size:200" json:"description"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+
+	// UpdatedAt 最后更新时间
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+/**
+ * TableName 指定 Role 结构体对应的数据库表名
+ */
+func (Role) TableName() string {
+	return "roles"
+}
+
+/**
+ * Permission 权限模型
+ *
+ * 权限编码格式为 "资源:操作"，如 product:write。
+ * 内置权限在启动时自动创建，接口通过 middleware.RequirePermission 按编码校验。
+ */
+type Permission struct {
+	// ID 权限唯一标识
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// Code 权限编码，唯一
+	Code string `gorm:"column:code;size:50;uniqueIndex;not null" json:"code"`
+
+	// Name 权限名称
+	Name string `gorm:"column:name;size:50;not null" json:"name"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+/**
+ * 内置权限编码
+ */
+const (
+	PermProductRead    = "product:read"    // 查看商品后台数据（图集、价格记录、导出）
+	PermProductWrite   = "product:write"   // 商品管理（创建、修改、删除、图集、导入）
+	PermSeckillManage  = "seckill:manage"  // 秒杀库存管理
+	PermStatsRead      = "stats:read"      // 查看经营统计
+	PermSystemMaintain = "system:maintain" // 重建布隆过滤器、排行榜、推荐等维护任务
+	PermRoleManage     = "role:manage"     // 角色与权限管理
+	PermUserManage     = "user:manage"     // 用户账号管理（查询、禁用、重置密码、解除登录锁定）
+	PermShopManage     = "shop:manage"     // 店铺审核与停业
+	PermShopOperate    = "shop:operate"    // 商家后台（管理本店商品、库存、订单）
+)
+
+/**
+ * TableName 指定 Permission 结构体对应的数据库表名
+ */
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+/**
+ * RolePermission 角色权限关联模型
+ *
+ * 角色与权限的多对多关系，(role_id, permission_id) 唯一。
+ */
+type RolePermission struct {
+	// ID 关联记录唯一标识
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// RoleID 角色ID
+	RoleID uint `gorm:"column:role_id;not null;uniqueIndex:idx_role_permission" json:"role_id"`
+
+	// PermissionID 权限ID
+	PermissionID uint `gorm:"column:permission_id;not null;uniqueIndex:idx_role_permission;index" json:"permission_id"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+/**
+ * TableName 指定 RolePermission 结构体对应的数据库表名
+ */
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+/**
+ * UserTOTP 两步验证（TOTP）模型
+ *
+ * 每个用户最多一条记录。开始绑定时生成密钥，EnabledAt 为空表示尚未完成绑定；
+ * 用户用验证器扫码并提交一次验证码后才会启用。
+ *
+ * 防重放：
+ * - LastUsedStep 记录最近一次通过校验的时间步
+ * - 同一时间步的验证码只能使用一次
+ */
+type UserTOTP struct {
+	// ID 记录唯一标识
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// UserID 用户ID，唯一
+	UserID uint `gorm:"column:user_id;uniqueIndex;not null" json:"user_id"`
+
+	// Secret TOTP 密钥（Base32），不会返回给前端
+	Secret string `gorm:"column:secret;size:64;not null" json:"-"`
+
+	// EnabledAt 启用时间，为空表示尚未完成绑定
+	EnabledAt *time.Time `gorm:"column:enabled_at" json:"enabled_at"`
+
+	// LastUsedStep 最近一次使用的时间步
+	LastUsedStep int64 `gorm:"column:last_used_step;not null;default:0" json:"-"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+
+	// UpdatedAt 最后更新时间
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+/**
+ * TableName 指定 UserTOTP 结构体对应的数据库表名
+ */
+func (UserTOTP) TableName() string {
+	return "user_totps"
+}
+
+/**
+ * UserBackupCode 两步验证备用码模型
+ *
+ * 无法使用验证器时用备用码代替动态验证码，每个备用码只能使用一次。
+ * 只保存哈希值，明文只在生成时返回一次；重新生成时删除旧的备用码。
+ */
+type UserBackupCode struct {
+	// ID 记录唯一标识
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// UserID 用户ID
+	UserID uint `gorm:"column:user_id;index;not null" json:"user_id"`
+
+	// CodeHash 备用码的 SHA-256 哈希
+	CodeHash string `gorm:"column:code_hash;size:64;not null" json:"-"`
+
+	// UsedAt 使用时间，为空表示未使用
+	UsedAt *time.Time `gorm:"column:used_at" json:"used_at"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+/**
+ * TableName 指定 UserBackupCode 结构体对应的数据库表名
+ */
+func (UserBackupCode) TableName() string {
+	return "user_backup_codes"
+}
+
+/**
+ * UserSession 登录会话模型
+ *
+ * 每次登录创建一条记录，与该次登录的刷新Token轮换家族一一对应（Family），
+ * 同一次登录签发的访问Token和刷新Token都带有该家族ID。
+ *
+ * 会话状态：
+ * - RevokedAt 非空：已退出登录或被删除
+ * - ExpiresAt 之前未刷新：刷新Token过期，会话自然结束
+ */
+type UserSession struct {
+	// ID 会话唯一标识
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// UserID 用户ID
+	UserID uint `gorm:"column:user_id;index;not null" json:"user_id"`
+
+	// Family 刷新Token轮换家族ID，不返回给前端
+	Family string `gorm:"column:family;size:32;uniqueIndex;not null" json:"-"`
+
+	// Device 设备描述，根据 User-Agent 识别（如 "iPhone · Safari"）
+	Device string `gorm:"column:device;size:100" json:"device"`
+
+	// UserAgent 登录时的 User-Agent
+	UserAgent string `gorm:"column:user_agent;size:255" json:"user_agent"`
+
+	// IP 登录IP
+	IP string `gorm:"column:ip;size:64" json:"ip"`
+
+	// LastSeenAt 最近活跃时间，按 security.session_touch_interval 节流更新
+	LastSeenAt time.Time `gorm:"column:last_seen_at" json:"last_seen_at"`
+
+	// ExpiresAt 会话过期时间，每次刷新Token时顺延
+	ExpiresAt time.Time `gorm:"column:expires_at;index" json:"expires_at"`
+
+	// RevokedAt 吊销时间，为空表示会话有效
+	RevokedAt *time.Time `gorm:"column:revoked_at" json:"revoked_at"`
+
+	// CreatedAt 登录时间
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+/**
+ * TableName 指定 UserSession 结构体对应的数据库表名
+ */
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+/**
+ * AdminAuditLog 后台操作审计日志模型
+ *
+ * 记录管理员对用户账号的操作（禁用、启用、重置密码、解除锁定）和店铺审核操作，
+ * 状态变更与日志写入在同一事务中完成。
+ *
+ * 设计特点：
+ * - 只增不改，不支持软删除
+ * - 按操作人、操作对象分别建索引，支持按人或按对象追溯
+ */
+type AdminAuditLog struct {
+	// ID 日志唯一标识，自增主键
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// OperatorID 操作人用户ID
+	OperatorID uint `gorm:"column:operator_id;not null;index" json:"operator_id"`
+
+	// Action 操作类型，见 AuditAction 常量
+	Action string `gorm:"column:action;size:50;not null" json:"action"`
+
+	// TargetType 操作对象类型，如 user
+	TargetType string `gorm:"column:target_type;size:30;not null;index:idx_audit_target,priority:1" json:"target_type"`
+
+	// TargetID 操作对象ID
+	TargetID uint `gorm:"column:target_id;not null;index:idx_audit_target,priority:2" json:"target_id"`
+
+	// Detail 操作说明（原因、变更前后的状态等）
+	Detail string `gorm:"column:detail;size:500" json:"detail"`
+
+	// IP 操作人IP
+	IP string `gorm:"column:ip;size:64" json:"ip"`
+
+	// CreatedAt 操作时间
+	CreatedAt time.Time `gorm:"column:created_at;index" json:"created_at"`
+}
+
+/**
+ * 审计日志操作类型
+ */
+const (
+	AuditTargetUser = "user"
+
+	AuditActionUserDisable       = "user.disable"        // 禁用账号
+	AuditActionUserEnable        = "user.enable"         // 启用账号
+	AuditActionUserResetPassword = "user.reset_password" // 要求重置密码
+	AuditActionUserUnlock        = "user.unlock"         // 解除登录锁定
+	AuditActionUserRole          = "user.role"           // 修改用户角色
+
+	AuditTargetShop = "shop"
+
+	AuditActionShopApprove = "shop.approve" // 审核通过店铺
+	AuditActionShopReject  = "shop.reject"  // 驳回开店申请
+	AuditActionShopSuspend = "shop.suspend" // 店铺停业
+)
+
+/**
+ * TableName 指定 AdminAuditLog 结构体对应的数据库表名
+ */
+func (AdminAuditLog) TableName() string {
+	return "admin_audit_logs"
+}
+
+/**
+ * Shop 店铺模型
+ *
+ * 用户提交开店申请后创建，平台审核通过后成为商家，可以在商家后台（/api/shop）管理
+ * 本店的商品、库存、订单和秒杀库存。
+ *
+ * 设计特点：
+ * - 每个用户最多拥有一个店铺（OwnerID 唯一），商家身份由店铺归属决定，不占用后台角色
+ * - 店铺名称唯一
+ * - 被驳回的申请修改资料后重新进入待审核状态
+ *
+ * 状态说明（Status）：
+ * - 1: 待审核
+ * - 2: 营业中（审核通过）
+ * - 3: 已驳回
+ * - 4: 已停业，商品不再展示，不能下单
+ */
+type Shop struct {
+	// ID 店铺唯一标识，自增主键
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// OwnerID 店主用户ID，唯一
+	OwnerID uint `gorm:"column:owner_id;not null;uniqueIndex" json:"owner_id"`
+
+	// Name 店铺名称，唯一
+	Name string `gorm:"column:name;size:100;not null;uniqueIndex" json:"name"`
+
+	// Description 店铺简介
+	Description string `gorm:"column:description;size:500" json:"description"`
+
+	// Logo 店铺Logo，上传服务返回的地址
+	Logo string `gorm:"column:logo;size:500" json:"logo"`
+
+	// Status 店铺状态，默认待审核(1)
+	Status int `gorm:"column:status;not null;default:1;index" json:"status"`
+
+	// ReviewReason 审核驳回或停业原因
+	ReviewReason string `gorm:"column:review_reason;size:255" json:"review_reason"`
+
+	// ReviewedBy 最近一次审核的管理员ID
+	ReviewedBy uint `gorm:"column:reviewed_by;not null;default:0" json:"reviewed_by"`
+
+	// ReviewedAt 最近一次审核时间
+	ReviewedAt *time.Time `gorm:"column:reviewed_at" json:"reviewed_at"`
+
+	// CreatedAt 申请时间
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+
+	// UpdatedAt 最后更新时间
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+
+	// DeletedAt 软删除时间
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index" json:"-"`
+}
+
+/**
+ * 店铺状态常量定义
+ */
+const (
+	ShopStatusPending   = 1 // 待审核
+	ShopStatusApproved  = 2 // 营业中
+	ShopStatusRejected  = 3 // 已驳回
+	ShopStatusSuspended = 4 // 已停业
+)
+
+/**
+ * TableName 指定 Shop 结构体对应的数据库表名
+ */
+func (Shop) TableName() string {
+	return "shops"
+}
+
+/**
+ * OrderItem 订单商品明细模型
+ *
+ * 购物车结算时，每张店铺子订单的每个商品一行。
+ * 商品名称、图片、单价为下单时的快照；
+ * 各行 PayAmount 之和等于子订单 TotalPrice，商品销量和销售额统计按此计算。
+ */
+type OrderItem struct {
+	// ID 明细唯一标识，自增主键
+	ID uint `gorm:"column:id;primarykey" json:"id"`
+
+	// OrderID 所属子订单ID
+	OrderID uint `gorm:"column:order_id;not null;index" json:"order_id"`
+
+	// ProductID 商品ID
+	ProductID uint `gorm:"column:product_id;not null;index" json:"product_id"`
+
+	// ProductName 下单时的商品名称
+	ProductName string `gorm:"column:product_name;size:200" json:"product_name"`
+
+	// ProductImage 下单时的商品图片
+	ProductImage string `gorm:"column:product_image;size:500" json:"product_image"`
+
+	// Price 下单时的商品单价
+	Price float64 `gorm:"column:price;precision:10;scale:2" json:"price"`
+
+	// Quantity 购买数量
+	Quantity int `gorm:"column:quantity;not null;default:1" json:"quantity"`
+
+	// PayAmount 实付金额 = Price × Quantity
+	PayAmount float64 `gorm:"column:pay_amount;precision:10;scale:2" json:"pay_amount"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+}
+
+/**
+ * TableName 指定 OrderItem 结构体对应的数据库表名
+ */
+func (OrderItem) TableName() string {
+	return "order_items"
+}
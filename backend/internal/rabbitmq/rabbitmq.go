@@ -24,6 +24,8 @@ const (
 	SeckillQueue = "seckill_queue" // 秒杀队列
 	PayQueue     = "pay_queue"     // 支付队列
 	DelayQueue   = "delay_queue"   // 延迟队列（用于订单超时取消）
+
+	ProductEventQueue = "product_event_queue" // 商品变更事件队列（上下架等）
 )
 
 // Init 初始化RabbitMQ连接
@@ -51,7 +53,7 @@ func Init() error {
 	}
 
 	// 声明队列
-	queues := []string{OrderQueue, SeckillQueue, PayQueue, DelayQueue}
+	queues := []string{OrderQueue, SeckillQueue, PayQueue, DelayQueue, ProductEventQueue}
 	for _, queue := range queues {
 		_, err = Channel.QueueDeclare(
 			queue, // 队列名称
@@ -245,6 +247,51 @@ func PublishSeckillMessage(ctx context.Context, msg *SeckillMessage) error {
 	return nil
 }
 
+// 商品事件类型
+const (
+	ProductEventPublished   = "published"   // 上架
+	ProductEventUnpublished = "unpublished" // 下架
+)
+
+// ProductEventMessage 商品变更事件
+// 供搜索索引、推荐等下游服务订阅
+type ProductEventMessage struct {
+	ProductID  uint      `json:"product_id"`
+	Event      string    `json:"event"`
+	Status     int       `json:"status"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// PublishProductEvent 发布商品变更事件
+func PublishProductEvent(ctx context.Context, msg *ProductEventMessage) error {
+	if Channel == nil {
+		return fmt.Errorf("RabbitMQ未初始化")
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("消息序列化失败: %w", err)
+	}
+
+	err = Channel.PublishWithContext(
+		ctx,
+		"",                // 默认交换机
+		ProductEventQueue, // 商品事件队列
+		false,             // 强制
+		false,             // 立即
+		amqp.Publishing{
+			DeliveryMode: amqp.Persistent,
+			ContentType:  "application/json",
+			Body:         body,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("商品事件发布失败: %w", err)
+	}
+
+	return nil
+}
+
 // ConsumeOrderMessage 消费订单消息
 func ConsumeOrderMessage(handler func(msg *OrderMessage) error) {
 	msgs, err := Channel.Consume(
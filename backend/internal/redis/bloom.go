@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// maxBloomBits Redis 位图的最大长度（512MB = 2^32 位）
+const maxBloomBits = uint64(1) << 32
+
+// BloomFilter 基于 Redis Bitmap 的布隆过滤器
+// 判定为"不存在"的元素一定不存在；判定为"存在"的元素有一定误判率，
+// 误判率由位图大小 bits 和哈希函数个数 hashes 决定
+type BloomFilter struct {
+	key    string
+	bits   uint64
+	hashes uint
+}
+
+// NewBloomFilter 创建布隆过滤器
+// key: 位图在 Redis 中的 key
+// bits: 位图大小（位），上限 2^32
+// hashes: 哈希函数个数
+func NewBloomFilter(key string, bits uint64, hashes uint) *BloomFilter {
+	if bits == 0 || bits > maxBloomBits {
+		bits = maxBloomBits
+	}
+	if hashes == 0 {
+		hashes = 1
+	}
+	return &BloomFilter{key: key, bits: bits, hashes: hashes}
+}
+
+// Key 位图 key
+func (b *BloomFilter) Key() string {
+	return b.key
+}
+
+// locations 计算元素对应的位偏移
+// 使用双重哈希 h1 + i*h2 模拟 k 个独立哈希函数
+func (b *BloomFilter) locations(item string) []int64 {
+	h := fnv.New64a()
+	h.Write([]byte(item))
+	h1 := h.Sum64()
+
+	h = fnv.New64()
+	h.Write([]byte(item))
+	h2 := h.Sum64() | 1 // 保证为奇数，避免退化为同一个位置
+
+	locs := make([]int64, b.hashes)
+	for i := uint(0); i < b.hashes; i++ {
+		locs[i] = int64((h1 + uint64(i)*h2) % b.bits)
+	}
+	return locs
+}
+
+// Add 添加元素（一次 Pipeline 完成所有 SETBIT）
+func (b *BloomFilter) Add(ctx context.Context, items ...string) error {
+	if len(items) == 0 {
+		return nil
+	}
+	pipe := Client.Pipeline()
+	for _, item := range items {
+		for _, loc := range b.locations(item) {
+			pipe.SetBit(ctx, b.key, loc, 1)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// MightContain 判断元素是否可能存在
+// 位图尚未构建（key 不存在）时返回 true，避免误拦截正常请求
+func (b *BloomFilter) MightContain(ctx context.Context, item string) (bool, error) {
+	pipe := Client.Pipeline()
+	exists := pipe.Exists(ctx, b.key)
+	locs := b.locations(item)
+	bits := make([]*redis.IntCmd, len(locs))
+	for i, loc := range locs {
+		bits[i] = pipe.GetBit(ctx, b.key, loc)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return true, err
+	}
+
+	if exists.Val() == 0 {
+		return true, nil
+	}
+	for _, bit := range bits {
+		if bit.Val() == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Rebuild 重建位图
+// 先写入临时 key，写完后 RENAME 覆盖正式 key，重建期间查询不受影响。
+// fill 负责通过传入的 add 函数写入全部元素
+func (b *BloomFilter) Rebuild(ctx context.Context, fill func(add func(items ...string) error) error) error {
+	tmp := &BloomFilter{
+		key:    fmt.Sprintf("%s:tmp:%d", b.key, time.Now().UnixNano()),
+		bits:   b.bits,
+		hashes: b.hashes,
+	}
+
+	// 预分配位图，保证即使没有元素 key 也存在
+	if err := Client.SetBit(ctx, tmp.key, int64(b.bits-1), 0).Err(); err != nil {
+		return err
+	}
+	if err := fill(func(items ...string) error { return tmp.Add(ctx, items...) }); err != nil {
+		Client.Del(ctx, tmp.key)
+		return err
+	}
+	return Client.Rename(ctx, tmp.key, b.key).Err()
+}
@@ -0,0 +1,79 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 浏览记录与浏览量统计
+// 浏览记录：每个用户一个 ZSET，member 为商品ID，score 为最近浏览时间（毫秒），
+// 重复浏览只更新时间，超过上限时淘汰最早的记录
+// 浏览量：按天计数的排行榜 ZSET（见 rank.go），前缀为 ProductViewsPrefix
+
+const (
+	HistoryCachePrefix = "history"
+	ProductViewsPrefix = "views"
+)
+
+// HistoryKey 用户浏览记录 key
+func HistoryKey(userID uint) string {
+	return CacheKey(HistoryCachePrefix, userID)
+}
+
+// AddHistory 写入浏览记录（一次 Pipeline 完成写入、截断和续期）
+// maxSize: 每个用户保留的最大条数
+// ttl: 浏览记录过期时间，每次浏览都会续期
+func AddHistory(ctx context.Context, userID, productID uint, viewedAt time.Time, maxSize int64, ttl time.Duration) error {
+	key := HistoryKey(userID)
+	pipe := Client.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(viewedAt.UnixMilli()), Member: productID})
+	pipe.ZRemRangeByRank(ctx, key, 0, -maxSize-1)
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ListHistory 按浏览时间倒序分页读取浏览记录
+// 返回商品ID、对应的浏览时间和总条数
+func ListHistory(ctx context.Context, userID uint, offset, limit int64) ([]uint, []time.Time, int64, error) {
+	key := HistoryKey(userID)
+	pipe := Client.Pipeline()
+	card := pipe.ZCard(ctx, key)
+	members := pipe.ZRevRangeWithScores(ctx, key, offset, offset+limit-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, nil, 0, err
+	}
+
+	ids := make([]uint, 0, len(members.Val()))
+	viewedAt := make([]time.Time, 0, len(members.Val()))
+	for _, z := range members.Val() {
+		member, _ := z.Member.(string)
+		id, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint(id))
+		viewedAt = append(viewedAt, time.UnixMilli(int64(z.Score)))
+	}
+	return ids, viewedAt, card.Val(), nil
+}
+
+// RemoveHistory 删除指定商品的浏览记录
+func RemoveHistory(ctx context.Context, userID uint, productIDs ...uint) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+	members := make([]interface{}, len(productIDs))
+	for i, id := range productIDs {
+		members[i] = id
+	}
+	return Client.ZRem(ctx, HistoryKey(userID), members...).Err()
+}
+
+// ClearHistory 清空用户浏览记录
+func ClearHistory(ctx context.Context, userID uint) error {
+	return Client.Del(ctx, HistoryKey(userID)).Err()
+}
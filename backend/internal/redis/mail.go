@@ -0,0 +1,21 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// 邮件发送冷却，同一用户同类邮件在冷却时间内只发送一次
+
+const MailCooldownPrefix = "mail_cooldown"
+
+// MailCooldownKey 邮件发送冷却 key，kind 为邮件类型
+func MailCooldownKey(kind string, userID uint) string {
+	return CacheKey(MailCooldownPrefix, fmt.Sprintf("%s:%d", kind, userID))
+}
+
+// AcquireMailCooldown 进入冷却，返回 false 表示仍在冷却中
+func AcquireMailCooldown(ctx context.Context, kind string, userID uint, cooldown time.Duration) (bool, error) {
+	return Client.SetNX(ctx, MailCooldownKey(kind, userID), 1, cooldown).Result()
+}
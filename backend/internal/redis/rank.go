@@ -0,0 +1,125 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 商品排行榜
+// 每个指标（销量、浏览量）按天一个 ZSET，另按分类各一个 ZSET：
+//   gomall:{指标}:{yyyymmdd}            全站
+//   gomall:{指标}:{yyyymmdd}:{分类}     分类
+// member 为商品ID，score 为当天累计值。多天的排行通过 ZUNIONSTORE 合并后短暂缓存。
+
+const (
+	ProductSalesPrefix = "sales"
+	rankDayLayout      = "20060102"
+)
+
+// RankItem 排行榜条目
+type RankItem struct {
+	ProductID uint
+	Score     float64
+}
+
+// RankKey 某指标某一天的排行榜 key，category 为空表示全站
+func RankKey(prefix string, day time.Time, category string) string {
+	key := CacheKey(prefix, day.Format(rankDayLayout))
+	if category != "" {
+		key += ":" + category
+	}
+	return key
+}
+
+// IncrRank 累加商品当天的指标值，同时更新全站和分类排行榜
+// ttl: 每日排行榜保留时间，需覆盖最长统计周期
+func IncrRank(ctx context.Context, prefix string, day time.Time, category string, productID uint, delta float64, ttl time.Duration) error {
+	member := strconv.FormatUint(uint64(productID), 10)
+	keys := []string{RankKey(prefix, day, "")}
+	if category != "" {
+		keys = append(keys, RankKey(prefix, day, category))
+	}
+
+	pipe := Client.Pipeline()
+	for _, key := range keys {
+		pipe.ZIncrBy(ctx, key, delta, member)
+		pipe.Expire(ctx, key, ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// TopRank 读取多个排行榜合并后的前 limit 名
+// 只有一个 key 时直接读取；多个 key 时合并到 unionKey 并缓存 unionTTL，期间重复请求不再合并
+func TopRank(ctx context.Context, keys []string, unionKey string, unionTTL time.Duration, limit int64) ([]RankItem, error) {
+	if len(keys) == 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	key := keys[0]
+	if len(keys) > 1 {
+		key = unionKey
+		exists, err := Client.Exists(ctx, unionKey).Result()
+		if err != nil {
+			return nil, err
+		}
+		if exists == 0 {
+			pipe := Client.TxPipeline()
+			pipe.ZUnionStore(ctx, unionKey, &redis.ZStore{Keys: keys})
+			pipe.Expire(ctx, unionKey, unionTTL)
+			if _, err := pipe.Exec(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	members, err := Client.ZRevRangeWithScores(ctx, key, 0, limit-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]RankItem, 0, len(members))
+	for _, z := range members {
+		member, _ := z.Member.(string)
+		id, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		items = append(items, RankItem{ProductID: uint(id), Score: z.Score})
+	}
+	return items, nil
+}
+
+// RankCategoryKeys 列出某指标某一天已有的分类排行榜 key
+func RankCategoryKeys(ctx context.Context, prefix string, day time.Time) ([]string, error) {
+	var keys []string
+	iter := Client.Scan(ctx, 0, RankKey(prefix, day, "")+":*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
+// ReplaceRank 用 scores 整体替换排行榜
+// 先写入临时 key 再 RENAME，替换过程中读取不受影响；scores 为空时删除排行榜
+func ReplaceRank(ctx context.Context, key string, scores map[uint]float64, ttl time.Duration) error {
+	if len(scores) == 0 {
+		return Client.Del(ctx, key).Err()
+	}
+
+	members := make([]redis.Z, 0, len(scores))
+	for id, score := range scores {
+		members = append(members, redis.Z{Score: score, Member: strconv.FormatUint(uint64(id), 10)})
+	}
+
+	tmp := fmt.Sprintf("%s:tmp:%d", key, time.Now().UnixNano())
+	pipe := Client.TxPipeline()
+	pipe.ZAdd(ctx, tmp, members...)
+	pipe.Expire(ctx, tmp, ttl)
+	pipe.Rename(ctx, tmp, key)
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -120,6 +120,9 @@ func DeleteProductCache(ctx context.Context, productID uint) error {
 	return Client.Del(ctx, key).Err()
 }
 
+// ProductNullCache 商品不存在时写入的占位值（json.Marshal(nil) 的结果）
+const ProductNullCache = "null"
+
 // SetStockCache 设置库存缓存（用于秒杀）
 func SetStockCache(ctx context.Context, productID uint, stock int) error {
 	key := CacheKey(StockCachePrefix, productID)
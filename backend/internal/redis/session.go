@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+// 登录会话
+// 删除会话时写入吊销标记，过期时间与访问Token有效期一致，该会话已签发的访问Token随之失效；
+// 最近活跃时间通过 SETNX 节流，间隔内只有第一个请求写数据库
+
+const (
+	SessionRevokedPrefix = "session_revoked"
+	SessionSeenPrefix    = "session_seen"
+)
+
+// SessionRevokedKey 会话吊销标记 key
+func SessionRevokedKey(family string) string {
+	return CacheKey(SessionRevokedPrefix, family)
+}
+
+// SessionSeenKey 会话活跃时间节流 key
+func SessionSeenKey(family string) string {
+	return CacheKey(SessionSeenPrefix, family)
+}
+
+// RevokeSession 写入会话吊销标记
+func RevokeSession(ctx context.Context, family string, expiration time.Duration) error {
+	return Client.Set(ctx, SessionRevokedKey(family), "1", expiration).Err()
+}
+
+// IsSessionRevoked 会话是否已吊销
+func IsSessionRevoked(ctx context.Context, family string) (bool, error) {
+	exists, err := Client.Exists(ctx, SessionRevokedKey(family)).Result()
+	if err != nil {
+		return false, err
+	}
+	return exists > 0, nil
+}
+
+// AcquireSessionTouch 获取本周期更新活跃时间的机会，返回 false 表示 interval 内已更新过
+func AcquireSessionTouch(ctx context.Context, family string, interval time.Duration) (bool, error) {
+	return Client.SetNX(ctx, SessionSeenKey(family), "1", interval).Result()
+}
@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 短信验证码
+// 验证码只保存哈希值（hash 字段）和错误次数（attempts 字段），过期自动删除；
+// 校验成功或错误次数达到上限时删除，验证码只能使用一次
+
+const (
+	SmsCodePrefix     = "sms_code"
+	SmsCooldownPrefix = "sms_cooldown"
+	SmsQuotaPrefix    = "sms_quota"
+)
+
+// SmsCodeKey 验证码 key，purpose 为用途（登录、绑定手机）
+func SmsCodeKey(purpose, phone string) string {
+	return CacheKey(SmsCodePrefix+":"+purpose, phone)
+}
+
+// SaveSmsCode 保存验证码哈希，覆盖之前发送的验证码
+func SaveSmsCode(ctx context.Context, purpose, phone, codeHash string, ttl time.Duration) error {
+	key := SmsCodeKey(purpose, phone)
+	_, err := Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, "hash", codeHash, "attempts", 0)
+		pipe.PExpire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+// LuaScriptVerifySmsCode Lua脚本：原子校验验证码
+// ARGV[1] 验证码哈希，ARGV[2] 最大错误次数
+// 返回值：1 校验通过，0 验证码不存在或已过期，-1 验证码错误，-2 错误次数过多，验证码已作废
+const LuaScriptVerifySmsCode = `
+local hash = redis.call('HGET', KEYS[1], 'hash')
+if not hash then
+    return 0
+end
+if hash == ARGV[1] then
+    redis.call('DEL', KEYS[1])
+    return 1
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+if attempts >= tonumber(ARGV[2]) then
+    redis.call('DEL', KEYS[1])
+    return -2
+end
+return -1
+`
+
+var verifySmsCodeScript = redis.NewScript(LuaScriptVerifySmsCode)
+
+// VerifySmsCode 校验验证码，返回值含义见 LuaScriptVerifySmsCode
+func VerifySmsCode(ctx context.Context, purpose, phone, codeHash string, maxAttempts int) (int, error) {
+	return verifySmsCodeScript.Run(ctx, Client, []string{SmsCodeKey(purpose, phone)}, codeHash, maxAttempts).Int()
+}
+
+// AcquireSmsCooldown 进入发送冷却，返回 false 表示仍在冷却中
+func AcquireSmsCooldown(ctx context.Context, phone string, cooldown time.Duration) (bool, error) {
+	return Client.SetNX(ctx, CacheKey(SmsCooldownPrefix, phone), 1, cooldown).Result()
+}
+
+// LuaScriptIncrWindow Lua脚本：固定窗口计数，首次计数时设置过期时间
+const LuaScriptIncrWindow = `
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+    redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`
+
+var incrWindowScript = redis.NewScript(LuaScriptIncrWindow)
+
+// IncrSmsQuota 计入一次发送，返回窗口内的发送次数；scope 为 phone / ip
+func IncrSmsQuota(ctx context.Context, scope, subject string, window time.Duration) (int64, error) {
+	key := CacheKey(SmsQuotaPrefix+":"+scope, subject)
+	return incrWindowScript.Run(ctx, Client, []string{key}, window.Milliseconds()).Int64()
+}
@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Token 吊销
+// 单个 Token：退出登录时以 jti 为 key 写入黑名单（SetTokenCache），过期时间与 Token 一致
+// 用户全部 Token：缓存用户当前的 Token 版本（数据库为准），版本递增后旧 Token 校验失败
+
+const TokenVersionPrefix = "token_version"
+
+// TokenVersionKey 用户 Token 版本 key
+func TokenVersionKey(userID uint) string {
+	return CacheKey(TokenVersionPrefix, userID)
+}
+
+// GetTokenVersion 读取缓存的 Token 版本，未缓存时返回 redis.Nil
+func GetTokenVersion(ctx context.Context, userID uint) (int64, error) {
+	return Client.Get(ctx, TokenVersionKey(userID)).Int64()
+}
+
+// SetTokenVersion 写入递增后的 Token 版本，覆盖旧值
+func SetTokenVersion(ctx context.Context, userID uint, version int64, expiration time.Duration) error {
+	return Client.Set(ctx, TokenVersionKey(userID), version, expiration).Err()
+}
+
+// CacheTokenVersion 缓存从数据库读到的 Token 版本
+// 使用 SETNX，避免读到的旧版本覆盖并发递增时写入的新版本
+func CacheTokenVersion(ctx context.Context, userID uint, version int64, expiration time.Duration) error {
+	return Client.SetNX(ctx, TokenVersionKey(userID), version, expiration).Err()
+}
+
+// 刷新Token轮换
+// 每次登录生成一个家族，家族 key 保存当前唯一有效的刷新Token jti。
+// 刷新时原子地比较并替换为新 jti；提交的 jti 不是当前值说明已轮换过的 Token 被再次使用，
+// 删除家族 key，该家族的所有刷新Token（包括攻击者或用户手中最新的一个）全部失效
+
+const RefreshFamilyPrefix = "refresh_family"
+
+// RefreshFamilyKey 刷新Token家族 key
+func RefreshFamilyKey(family string) string {
+	return CacheKey(RefreshFamilyPrefix, family)
+}
+
+// LuaScriptRotateRefresh Lua脚本：原子轮换刷新Token
+// 返回值：1 轮换成功，0 家族不存在（已吊销或过期），-1 重复使用，家族已吊销
+const LuaScriptRotateRefresh = `
+local current = redis.call('GET', KEYS[1])
+if current == false then
+    return 0
+end
+if current ~= ARGV[1] then
+    redis.call('DEL', KEYS[1])
+    return -1
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`
+
+var rotateRefreshScript = redis.NewScript(LuaScriptRotateRefresh)
+
+// SetRefreshFamily 登录时创建刷新Token家族
+func SetRefreshFamily(ctx context.Context, family, jti string, expiration time.Duration) error {
+	return Client.Set(ctx, RefreshFamilyKey(family), jti, expiration).Err()
+}
+
+// RotateRefreshFamily 将家族的当前刷新Token从 oldJTI 轮换为 newJTI，返回值含义见 LuaScriptRotateRefresh
+func RotateRefreshFamily(ctx context.Context, family, oldJTI, newJTI string, expiration time.Duration) (int, error) {
+	return rotateRefreshScript.Run(ctx, Client, []string{RefreshFamilyKey(family)},
+		oldJTI, newJTI, expiration.Milliseconds()).Int()
+}
+
+// DeleteRefreshFamily 吊销刷新Token家族
+func DeleteRefreshFamily(ctx context.Context, family string) error {
+	return Client.Del(ctx, RefreshFamilyKey(family)).Err()
+}
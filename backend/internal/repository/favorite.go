@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/**
+ * ==================== FavoriteRepository 收藏数据访问层 ====================
+ *
+ * 负责用户收藏记录的增删查操作，并维护商品的收藏人数。
+ *
+ * 提供的方法：
+ * - Add: 添加收藏（幂等），新增时商品收藏人数加一
+ * - Remove: 取消收藏，删除成功时商品收藏人数减一
+ * - ListByUserID: 分页获取用户的收藏（按收藏时间倒序）
+ * - FavoritedProductIDs: 查询用户收藏了给定商品中的哪些
+ */
+
+/**
+ * FavoriteRepository 收藏仓储结构体
+ */
+type FavoriteRepository struct{}
+
+/**
+ * NewFavoriteRepository 创建收藏仓库实例
+ */
+func NewFavoriteRepository() *FavoriteRepository {
+	return &FavoriteRepository{}
+}
+
+/**
+ * Add 添加收藏（带事务）
+ *
+ * 依赖 (user_id, product_id) 唯一索引实现幂等：已收藏时不插入，收藏人数也不变。
+ *
+ * 参数：
+ *   userID uint - 用户ID
+ *   productID uint - 商品ID
+ *
+ * 返回值：
+ *   bool - 是否新增了收藏（false 表示之前已收藏）
+ *   error - 失败时返回错误
+ */
+func (r *FavoriteRepository) Add(userID, productID uint) (bool, error) {
+	added := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.Favorite{
+			UserID:    userID,
+			ProductID: productID,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		added = true
+		return tx.Model(&model.Product{}).
+			Where("id = ?", productID).
+			UpdateColumn("favorite_count", gorm.Expr("favorite_count + 1")).Error
+	})
+	return added, err
+}
+
+/**
+ * Remove 取消收藏（带事务）
+ *
+ * 参数：
+ *   userID uint - 用户ID
+ *   productID uint - 商品ID
+ *
+ * 返回值：
+ *   bool - 是否删除了收藏（false 表示本来就未收藏）
+ *   error - 失败时返回错误
+ */
+func (r *FavoriteRepository) Remove(userID, productID uint) (bool, error) {
+	removed := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND product_id = ?", userID, productID).Delete(&model.Favorite{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		removed = true
+		// 商品可能已被软删除，使用 Unscoped 保证计数仍然正确
+		return tx.Unscoped().Model(&model.Product{}).
+			Where("id = ?", productID).
+			UpdateColumn("favorite_count", gorm.Expr("GREATEST(favorite_count - 1, 0)")).Error
+	})
+	return removed, err
+}
+
+/**
+ * ListByUserID 分页获取用户的收藏（按收藏时间倒序）
+ *
+ * 返回值：
+ *   []model.Favorite - 收藏记录
+ *   int64 - 总记录数
+ *   error - 查询失败时返回错误
+ */
+func (r *FavoriteRepository) ListByUserID(userID uint, page, pageSize int) ([]model.Favorite, int64, error) {
+	var favorites []model.Favorite
+	var total int64
+
+	query := database.DB.Model(&model.Favorite{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&favorites).Error
+	return favorites, total, err
+}
+
+/**
+ * FavoritedProductIDs 查询用户收藏了给定商品中的哪些
+ *
+ * 返回值：
+ *   map[uint]bool - 已收藏的商品ID集合
+ *   error - 查询失败时返回错误
+ */
+func (r *FavoriteRepository) FavoritedProductIDs(userID uint, productIDs []uint) (map[uint]bool, error) {
+	favorited := make(map[uint]bool, len(productIDs))
+	if len(productIDs) == 0 {
+		return favorited, nil
+	}
+
+	var ids []uint
+	err := database.DB.Model(&model.Favorite{}).
+		Where("user_id = ? AND product_id IN ?", userID, productIDs).
+		Pluck("product_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		favorited[id] = true
+	}
+	return favorited, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"time"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * ==================== 订单支付与销量统计 ====================
+ *
+ * 提供的方法：
+ * - MarkPaid: 待支付订单标记为已支付（条件更新，重复回调不会重复计数）
+ * - SalesBetween: 按商品汇总时间段内已支付订单的销量
+ */
+
+/**
+ * paidOrderStatuses 计入销量的订单状态：已支付、已发货、已完成
+ */
+var paidOrderStatuses = []int{2, 3, 4}
+
+/**
+ * ProductSales 商品销量汇总
+ */
+type ProductSales struct {
+	ProductID uint   // 商品ID
+	Category  string // 商品当前分类
+	Quantity  int64  // 销量
+}
+
+/**
+ * MarkPaid 将待支付订单标记为已支付
+ *
+ * 只更新 status = 1 的订单，并发支付或支付回调重复通知时只有一次更新成功，
+ * 调用方据此决定是否执行支付后的处理（如累计销量）。
+ * 合并支付父订单的待支付子订单在同一事务中一起标记为已支付。
+ *
+ * 参数：
+ *   order *model.Order - 订单对象，成功时同步更新 Status、PayType、PaidAt
+ *   payType int - 支付类型
+ *   paidAt time.Time - 支付时间
+ *
+ * 返回值：
+ *   bool - 是否由本次调用完成支付
+ *   error - 更新失败时返回错误
+ */
+func (r *OrderRepository) MarkPaid(order *model.Order, payType int, paidAt time.Time) (bool, error) {
+	paid := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Order{}).
+			Where("id = ? AND status = ?", order.ID, 1).
+			Updates(map[string]interface{}{
+				"status":   2,
+				"pay_type": payType,
+				"paid_at":  paidAt,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		paid = true
+
+		if order.OrderType != model.OrderTypeCombined {
+			return nil
+		}
+		return tx.Model(&model.Order{}).
+			Where("parent_id = ? AND status = ?", order.ID, 1).
+			Updates(map[string]interface{}{
+				"status":   2,
+				"pay_type": payType,
+				"paid_at":  paidAt,
+			}).Error
+	})
+	if err != nil || !paid {
+		return false, err
+	}
+
+	order.Status = 2
+	order.PayType = payType
+	order.PaidAt = &paidAt
+	return true, nil
+}
+
+/**
+ * SalesBetween 按商品汇总 [start, end) 内支付的订单销量
+ *
+ * 历史订单没有支付时间，按下单时间统计；已删除的商品不参与汇总；结算子订单按商品明细统计。
+ *
+ * 返回值：
+ *   []ProductSales - 每个商品的销量
+ *   error - 查询失败时返回错误
+ */
+func (r *OrderRepository) SalesBetween(start, end time.Time) ([]ProductSales, error) {
+	var sales []ProductSales
+	err := database.DB.Table("(?) AS o", orderLines()).
+		Select("o.product_id AS product_id, p.category AS category, SUM(o.quantity) AS quantity").
+		Joins("JOIN products AS p ON p.id = o.product_id AND p.deleted_at IS NULL").
+		Where("o.status IN ?", paidOrderStatuses).
+		Where("COALESCE(o.paid_at, o.created_at) >= ? AND COALESCE(o.paid_at, o.created_at) < ?", start, end).
+		Group("o.product_id, p.category").
+		Scan(&sales).Error
+	return sales, err
+}
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"sort"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * ==================== 购物车结算拆单 ====================
+ *
+ * 购物车结算生成一张用于支付的父订单和每个店铺一张子订单，子订单的商品明细存放在 order_items。
+ * 父订单、子订单、明细、库存扣减和购物车清理在同一事务中完成。
+ *
+ * 提供的方法：
+ * - OrderRepository.CreateCombined: 创建父订单、子订单和明细，扣减库存并删除已结算的购物车记录
+ * - OrderRepository.CancelCombined: 取消待支付的父订单及其子订单
+ * - OrderRepository.ListChildren: 父订单的子订单
+ * - OrderRepository.ListItems: 订单的商品明细
+ * - OrderRepository.ListItemsByParent: 父订单下所有子订单的商品明细
+ */
+
+/**
+ * ShopOrder 一个店铺的子订单及其商品明细
+ */
+type ShopOrder struct {
+	Order *model.Order
+	Items []model.OrderItem
+}
+
+/**
+ * CreateCombined 创建父订单和店铺子订单（带事务）
+ *
+ * 先按商品ID升序逐个扣减库存：UPDATE ... WHERE stock >= ? 条件更新，由行锁保证并发结算不会超卖，
+ * 固定加锁顺序避免商品相同但顺序不同的两次结算互相死锁。任一商品库存不足时整体回滚，
+ * 成功时删除本次结算的购物车记录，不影响结算期间新加入购物车的商品。
+ *
+ * 参数：
+ *   parent *model.Order - 父订单，成功时回填 ID
+ *   children []ShopOrder - 店铺子订单，成功时回填 ID、ParentID 和明细的 OrderID
+ *   cartIDs []uint - 本次结算的购物车记录ID
+ *
+ * 返回值：
+ *   error - 库存不足返回 ErrInsufficientStock，商品不存在返回 gorm.ErrRecordNotFound
+ */
+func (r *OrderRepository) CreateCombined(parent *model.Order, children []ShopOrder, cartIDs []uint) error {
+	quantities := make(map[uint]int)
+	for _, child := range children {
+		for _, item := range child.Items {
+			quantities[item.ProductID] += item.Quantity
+		}
+	}
+	productIDs := make([]uint, 0, len(quantities))
+	for id := range quantities {
+		productIDs = append(productIDs, id)
+	}
+	sort.Slice(productIDs, func(i, j int) bool { return productIDs[i] < productIDs[j] })
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, id := range productIDs {
+			if err := deductStock(tx, id, quantities[id]); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(parent).Error; err != nil {
+			return err
+		}
+		for _, child := range children {
+			child.Order.ParentID = parent.ID
+			if err := tx.Create(child.Order).Error; err != nil {
+				return err
+			}
+			for i := range child.Items {
+				child.Items[i].OrderID = child.Order.ID
+			}
+			if err := tx.Create(&child.Items).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("user_id = ? AND id IN ?", parent.UserID, cartIDs).Delete(&model.Cart{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// 事务提交后删除商品缓存，详情页库存及时刷新
+	productRepo := NewProductRepository()
+	for _, id := range productIDs {
+		productRepo.InvalidateCache(id)
+	}
+	return nil
+}
+
+/**
+ * deductStock 条件扣减库存，未更新到记录时区分商品不存在和库存不足
+ */
+func deductStock(tx *gorm.DB, productID uint, quantity int) error {
+	result := tx.Model(&model.Product{}).
+		Where("id = ? AND stock >= ?", productID, quantity).
+		Update("stock", gorm.Expr("stock - ?", quantity))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&model.Product{}).Where("id = ?", productID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ErrInsufficientStock
+}
+
+/**
+ * CancelCombined 取消待支付的父订单，子订单随之取消
+ *
+ * 只更新 status = 1 的订单，与支付并发时只有一方成功。
+ *
+ * 返回值：
+ *   bool - 是否由本次调用取消
+ *   error - 更新失败时返回错误
+ */
+func (r *OrderRepository) CancelCombined(order *model.Order) (bool, error) {
+	cancelled := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Order{}).
+			Where("id = ? AND status = ?", order.ID, 1).
+			Update("status", 5)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		cancelled = true
+		return tx.Model(&model.Order{}).
+			Where("parent_id = ? AND status = ?", order.ID, 1).
+			Update("status", 5).Error
+	})
+	if err != nil || !cancelled {
+		return false, err
+	}
+
+	order.Status = 5
+	return true, nil
+}
+
+/**
+ * ListChildren 父订单的子订单，按ID顺序
+ */
+func (r *OrderRepository) ListChildren(parentID uint) ([]model.Order, error) {
+	var orders []model.Order
+	err := database.DB.Where("parent_id = ?", parentID).Order("id").Find(&orders).Error
+	return orders, err
+}
+
+/**
+ * ListItems 订单的商品明细
+ */
+func (r *OrderRepository) ListItems(orderID uint) ([]model.OrderItem, error) {
+	var items []model.OrderItem
+	err := database.DB.Where("order_id = ?", orderID).Order("id").Find(&items).Error
+	return items, err
+}
+
+/**
+ * ListItemsByParent 父订单下所有子订单的商品明细
+ */
+func (r *OrderRepository) ListItemsByParent(parentID uint) ([]model.OrderItem, error) {
+	var items []model.OrderItem
+	err := database.DB.Table("order_items AS i").
+		Select("i.*").
+		Joins("JOIN orders AS o ON o.id = i.order_id AND o.deleted_at IS NULL").
+		Where("o.parent_id = ?", parentID).
+		Order("i.id").
+		Scan(&items).Error
+	return items, err
+}
+
+/**
+ * orderLines 按商品展开的订单行，供销量、销售额统计使用
+ *
+ * 单商品订单（普通、秒杀）取订单本身；结算子订单取 order_items，金额为分摊优惠后的实付金额；
+ * 父订单和子订单的 product_id 为 0，不会重复计入。
+ * 列：order_id, user_id, product_id, product_name, quantity, total_price, status, order_type, paid_at, created_at
+ */
+func orderLines() *gorm.DB {
+	single := database.DB.Table("orders").
+		Select("id AS order_id, user_id, product_id, product_name, quantity, total_price, status, order_type, paid_at, created_at").
+		Where("deleted_at IS NULL AND product_id > 0")
+	items := database.DB.Table("order_items AS i").
+		Select("o.id AS order_id, o.user_id, i.product_id, i.product_name, i.quantity, i.pay_amount AS total_price, o.status, o.order_type, o.paid_at, o.created_at").
+		Joins("JOIN orders AS o ON o.id = i.order_id AND o.deleted_at IS NULL")
+	return database.DB.Raw("? UNION ALL ?", single, items)
+}
@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"gomall/backend/internal/cache"
+	"gomall/backend/internal/config"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/redis"
+
+	"golang.org/x/sync/singleflight"
+)
+
+/**
+ * ==================== 商品缓存（Cache-Aside） ====================
+ *
+ * 读路径：本地 LRU -> Redis -> 数据库 -> 回写缓存（二级缓存见 internal/cache）
+ * 写路径：先更新数据库，再删除缓存（由 Service 层调用 InvalidateCache），
+ *         删除操作会通过 Pub/Sub 广播给其他实例
+ *
+ * 三类常见问题的处理：
+ * - 缓存穿透：商品不存在时写入短期空值占位（redis.ProductNullCache）
+ * - 缓存击穿：同一商品的并发未命中通过 singleflight 合并为一次数据库查询
+ * - 缓存雪崩：过期时间叠加随机抖动，避免大量 key 同时失效
+ *
+ * Redis 不可用时直接降级为查询数据库，不影响主流程。
+ */
+
+/**
+ * 缓存默认参数（config.yaml 中 cache 节未配置时使用）
+ */
+const (
+	defaultProductCacheTTL  = 600 * time.Second
+	defaultProductNullTTL   = 60 * time.Second
+	defaultProductTTLJitter = 0.1
+
+	// productCacheTimeout 单次缓存读写超时，避免 Redis 抖动拖慢接口
+	productCacheTimeout = 200 * time.Millisecond
+)
+
+/**
+ * productLoadGroup 合并同一商品的并发回源请求
+ */
+var productLoadGroup singleflight.Group
+
+var (
+	productCacheOnce sync.Once
+	productCache     *cache.Cache
+)
+
+/**
+ * getProductCache 获取商品二级缓存（首次使用时按配置创建）
+ */
+func getProductCache() *cache.Cache {
+	productCacheOnce.Do(func() {
+		productCache = cache.NewFromConfig(redis.ProductCachePrefix)
+	})
+	return productCache
+}
+
+/**
+ * productCacheKey 商品缓存 key，与 redis.SetProductCache 保持一致
+ */
+func productCacheKey(id uint) string {
+	return redis.CacheKey(redis.ProductCachePrefix, id)
+}
+
+/**
+ * GetByIDWithCache 根据商品ID获取商品（带缓存）
+ *
+ * 参数：
+ *   id uint - 商品ID
+ *
+ * 返回值：
+ *   *model.Product - 商品对象（调用方可安全修改，不与其他请求共享）
+ *   error - 未找到返回 ErrProductNotFound
+ */
+func (r *ProductRepository) GetByIDWithCache(id uint) (*model.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), productCacheTimeout)
+	defer cancel()
+
+	if data, ok := getProductCache().Get(ctx, productCacheKey(id)); ok {
+		if string(data) == redis.ProductNullCache {
+			return nil, ErrProductNotFound
+		}
+		var product model.Product
+		if err := json.Unmarshal(data, &product); err == nil {
+			return &product, nil
+		}
+	}
+
+	v, err, _ := productLoadGroup.Do(strconv.FormatUint(uint64(id), 10), func() (interface{}, error) {
+		product, err := r.GetByID(id)
+		if err != nil {
+			if errors.Is(err, ErrProductNotFound) {
+				r.setCache(id, nil)
+			}
+			return nil, err
+		}
+		r.setCache(id, product)
+		return product, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// singleflight 的结果由所有等待者共享，复制一份再返回
+	product := *v.(*model.Product)
+	return &product, nil
+}
+
+/**
+ * GetByIDsWithCache 批量获取商品（带缓存）
+ *
+ * 流程：
+ * 1. 先查本地缓存，其余 key 一次 MGET 读取 Redis
+ * 2. 未命中的 ID 一次 IN 查询回源
+ * 3. 查到的回写缓存，查不到的写入空值占位
+ *
+ * 参数：
+ *   ids []uint - 商品ID列表
+ *
+ * 返回值：
+ *   []model.Product - 找到的商品列表，按 ids 的顺序返回，不存在的商品被跳过
+ *   error - 查询失败时返回错误
+ */
+func (r *ProductRepository) GetByIDsWithCache(ids []uint) ([]model.Product, error) {
+	if len(ids) == 0 {
+		return r.GetByIDs(ids)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), productCacheTimeout)
+	defer cancel()
+
+	found := make(map[uint]model.Product, len(ids))
+	missed := make([]uint, 0, len(ids))
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = productCacheKey(id)
+	}
+	for i, data := range getProductCache().GetMany(ctx, keys) {
+		if data == nil {
+			missed = append(missed, ids[i])
+			continue
+		}
+		if string(data) == redis.ProductNullCache {
+			continue
+		}
+		var product model.Product
+		if err := json.Unmarshal(data, &product); err != nil {
+			missed = append(missed, ids[i])
+			continue
+		}
+		found[ids[i]] = product
+	}
+
+	if len(missed) > 0 {
+		products, err := r.GetByIDs(missed)
+		if err != nil {
+			return nil, err
+		}
+		for i := range products {
+			found[products[i].ID] = products[i]
+			r.setCache(products[i].ID, &products[i])
+		}
+		for _, id := range missed {
+			if _, ok := found[id]; !ok {
+				r.setCache(id, nil)
+			}
+		}
+	}
+
+	result := make([]model.Product, 0, len(found))
+	seen := make(map[uint]bool, len(found))
+	for _, id := range ids {
+		if product, ok := found[id]; ok && !seen[id] {
+			result = append(result, product)
+			seen[id] = true
+		}
+	}
+	return result, nil
+}
+
+/**
+ * InvalidateCache 删除商品缓存和商品详情缓存
+ *
+ * 在商品更新、删除、库存变化、图集变化之后调用。
+ * 删除失败只会导致短暂的脏读，缓存过期后自动恢复，因此忽略错误。
+ */
+func (r *ProductRepository) InvalidateCache(id uint) {
+	ctx, cancel := context.WithTimeout(context.Background(), productCacheTimeout)
+	defer cancel()
+	_ = getProductCache().Invalidate(ctx, productCacheKey(id), productDetailCacheKey(id))
+}
+
+/**
+ * setCache 写入商品缓存
+ *
+ * product 为 nil 时写入空值占位，使用较短的过期时间。
+ */
+func (r *ProductRepository) setCache(id uint, product *model.Product) {
+	ctx, cancel := context.WithTimeout(context.Background(), productCacheTimeout)
+	defer cancel()
+
+	ttl, nullTTL, jitter := productCacheSettings()
+	if product == nil {
+		_ = getProductCache().Set(ctx, productCacheKey(id), []byte(redis.ProductNullCache), nullTTL)
+		return
+	}
+	data, err := json.Marshal(product)
+	if err != nil {
+		return
+	}
+	_ = getProductCache().Set(ctx, productCacheKey(id), data, withJitter(ttl, jitter))
+}
+
+/**
+ * productCacheSettings 读取缓存配置
+ */
+func productCacheSettings() (ttl, nullTTL time.Duration, jitter float64) {
+	ttl, nullTTL, jitter = defaultProductCacheTTL, defaultProductNullTTL, defaultProductTTLJitter
+	if config.Config == nil {
+		return
+	}
+	cacheConfig := config.GetCache()
+	if cacheConfig == nil {
+		return
+	}
+	if v := cacheConfig.GetInt("product_ttl"); v > 0 {
+		ttl = time.Duration(v) * time.Second
+	}
+	if v := cacheConfig.GetInt("null_ttl"); v > 0 {
+		nullTTL = time.Duration(v) * time.Second
+	}
+	if cacheConfig.IsSet("ttl_jitter") {
+		jitter = cacheConfig.GetFloat64("ttl_jitter")
+	}
+	return
+}
+
+/**
+ * withJitter 为过期时间叠加 [0, ratio) 的随机抖动
+ */
+func withJitter(ttl time.Duration, ratio float64) time.Duration {
+	if ratio <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Float64()*ratio*float64(ttl))
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/redis"
+
+	"golang.org/x/sync/singleflight"
+)
+
+/**
+ * ==================== 商品详情缓存 ====================
+ *
+ * 详情接口除商品本身外还需要图集和近期最低价，三者组装为 ProductDetail 整体缓存，
+ * 热门商品的详情请求不再访问 MySQL。
+ *
+ * 详情与商品存放在同一个二级缓存中，key 同样按商品ID区分，
+ * InvalidateCache 同时删除两者，因此商品、图集、价格的写路径只需调用 InvalidateCache。
+ * 最低价的统计窗口随时间滑动，由缓存过期时间兜底。
+ *
+ * 提供的方法：
+ * - ProductRepository.GetDetailWithCache: 商品详情（带缓存）
+ */
+
+/**
+ * ProductDetail 商品详情缓存内容
+ */
+type ProductDetail struct {
+	Product model.Product        `json:"product"`
+	Images  []model.ProductImage `json:"images"`
+	// LowestPrice 窗口内变更记录中的最低价，没有变更记录时为 nil
+	LowestPrice *float64 `json:"lowest_price"`
+}
+
+/**
+ * productDetailLoadGroup 合并同一商品详情的并发回源请求
+ */
+var productDetailLoadGroup singleflight.Group
+
+/**
+ * productDetailCacheKey 商品详情缓存 key
+ */
+func productDetailCacheKey(id uint) string {
+	return redis.CacheKey(redis.ProductCachePrefix, "detail:"+strconv.FormatUint(uint64(id), 10))
+}
+
+/**
+ * GetDetailWithCache 获取商品详情（带缓存）
+ *
+ * 参数：
+ *   id uint - 商品ID
+ *   priceWindow time.Duration - 最低价统计窗口
+ *
+ * 返回值：
+ *   *ProductDetail - 商品详情（调用方可安全修改，不与其他请求共享）
+ *   error - 未找到返回 ErrProductNotFound
+ */
+func (r *ProductRepository) GetDetailWithCache(id uint, priceWindow time.Duration) (*ProductDetail, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), productCacheTimeout)
+	defer cancel()
+
+	if data, ok := getProductCache().Get(ctx, productDetailCacheKey(id)); ok {
+		var detail ProductDetail
+		if err := json.Unmarshal(data, &detail); err == nil {
+			return &detail, nil
+		}
+	}
+
+	v, err, _ := productDetailLoadGroup.Do(strconv.FormatUint(uint64(id), 10), func() (interface{}, error) {
+		// 商品不存在时由商品缓存写入空值占位
+		product, err := r.GetByIDWithCache(id)
+		if err != nil {
+			return nil, err
+		}
+		images, err := NewProductImageRepository().ListByProductID(id)
+		if err != nil {
+			return nil, err
+		}
+
+		detail := &ProductDetail{Product: *product, Images: images}
+		lowest, ok, err := r.LowestPriceSince(id, time.Now().Add(-priceWindow))
+		if err != nil {
+			// 最低价查询失败时详情照常返回，但不写入缓存，避免缓存错误的最低价
+			return detail, nil
+		}
+		if ok {
+			detail.LowestPrice = &lowest
+		}
+		r.setDetailCache(id, detail)
+		return detail, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// singleflight 的结果由所有等待者共享，复制一份再返回
+	shared := v.(*ProductDetail)
+	detail := *shared
+	detail.Images = make([]model.ProductImage, len(shared.Images))
+	copy(detail.Images, shared.Images)
+	return &detail, nil
+}
+
+/**
+ * setDetailCache 写入商品详情缓存，过期时间与商品缓存相同
+ */
+func (r *ProductRepository) setDetailCache(id uint, detail *ProductDetail) {
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), productCacheTimeout)
+	defer cancel()
+
+	ttl, _, jitter := productCacheSettings()
+	_ = getProductCache().Set(ctx, productDetailCacheKey(id), data, withJitter(ttl, jitter))
+}
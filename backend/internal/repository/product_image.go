@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"errors"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * ErrProductImageNotFound 商品图片不存在错误
+ */
+var ErrProductImageNotFound = errors.New("商品图片不存在")
+
+/**
+ * ==================== ProductImageRepository 商品图片数据访问层 ====================
+ *
+ * 负责商品图集的增删改查操作。
+ *
+ * 提供的方法：
+ * - CreateBatch: 批量添加图片
+ * - GetByID: 根据ID获取图片
+ * - ListByProductID: 获取商品的图集（按排序值升序）
+ * - MaxSort: 获取当前最大排序值
+ * - UpdateSort: 批量更新排序
+ * - SetMain: 设置主图（同步商品主图字段）
+ * - Delete: 删除图片
+ */
+
+/**
+ * ProductImageRepository 商品图片仓储结构体
+ */
+type ProductImageRepository struct{}
+
+/**
+ * NewProductImageRepository 创建商品图片仓库实例
+ */
+func NewProductImageRepository() *ProductImageRepository {
+	return &ProductImageRepository{}
+}
+
+/**
+ * CreateBatch 批量添加图片
+ *
+ * 参数：
+ *   images []model.ProductImage - 要添加的图片列表
+ *
+ * 返回值：
+ *   error - 添加失败时返回错误
+ */
+func (r *ProductImageRepository) CreateBatch(images []model.ProductImage) error {
+	if len(images) == 0 {
+		return nil
+	}
+	return database.DB.Create(&images).Error
+}
+
+/**
+ * GetByID 根据ID获取图片
+ *
+ * 返回值：
+ *   *model.ProductImage - 图片记录
+ *   error - 未找到返回 ErrProductImageNotFound
+ */
+func (r *ProductImageRepository) GetByID(id uint) (*model.ProductImage, error) {
+	var image model.ProductImage
+	if err := database.DB.First(&image, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductImageNotFound
+		}
+		return nil, err
+	}
+	return &image, nil
+}
+
+/**
+ * ListByProductID 获取商品的图集
+ *
+ * 排序规则：sort 升序，sort 相同时按 id 升序（先添加的在前）
+ */
+func (r *ProductImageRepository) ListByProductID(productID uint) ([]model.ProductImage, error) {
+	var images []model.ProductImage
+	if err := database.DB.Where("product_id = ?", productID).Order("sort ASC, id ASC").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+/**
+ * MaxSort 获取商品当前最大排序值
+ *
+ * 新增图片默认追加到图集末尾。
+ */
+func (r *ProductImageRepository) MaxSort(productID uint) (int, error) {
+	var maxSort int
+	err := database.DB.Model(&model.ProductImage{}).
+		Where("product_id = ?", productID).
+		Select("COALESCE(MAX(sort), 0)").
+		Scan(&maxSort).Error
+	return maxSort, err
+}
+
+/**
+ * UpdateSort 批量更新排序（带事务）
+ *
+ * 参数：
+ *   productID uint - 商品ID，防止越权修改其他商品的图片
+ *   imageIDs []uint - 按新顺序排列的图片ID
+ *
+ * 返回值：
+ *   error - 图片不属于该商品或更新失败时返回错误
+ */
+func (r *ProductImageRepository) UpdateSort(productID uint, imageIDs []uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for i, id := range imageIDs {
+			result := tx.Model(&model.ProductImage{}).
+				Where("id = ? AND product_id = ?", id, productID).
+				Update("sort", i+1)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return ErrProductImageNotFound
+			}
+		}
+		return nil
+	})
+}
+
+/**
+ * SetMain 设置主图（带事务）
+ *
+ * 事务内完成三件事：
+ * 1. 清除该商品其他图片的主图标记
+ * 2. 标记指定图片为主图
+ * 3. 同步 products.image_url，订单快照继续读取该字段
+ *
+ * 参数：
+ *   image *model.ProductImage - 要设为主图的图片
+ */
+func (r *ProductImageRepository) SetMain(image *model.ProductImage) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.ProductImage{}).
+			Where("product_id = ? AND id <> ?", image.ProductID, image.ID).
+			Update("is_main", false).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(image).Update("is_main", true).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Product{}).
+			Where("id = ?", image.ProductID).
+			Update("image_url", image.URL).Error
+	})
+}
+
+/**
+ * Delete 删除图片（带事务）
+ *
+ * 如果删除的是主图，则把排序最靠前的剩余图片提升为主图；
+ * 图集被删空时清空商品主图字段。
+ */
+func (r *ProductImageRepository) Delete(image *model.ProductImage) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.ProductImage{}, image.ID).Error; err != nil {
+			return err
+		}
+		if !image.IsMain {
+			return nil
+		}
+
+		var next model.ProductImage
+		err := tx.Where("product_id = ?", image.ProductID).Order("sort ASC, id ASC").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Model(&model.Product{}).Where("id = ?", image.ProductID).Update("image_url", "").Error
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&next).Update("is_main", true).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Product{}).Where("id = ?", image.ProductID).Update("image_url", next.URL).Error
+	})
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * ==================== 商品批量导入导出 ====================
+ *
+ * 提供的方法：
+ * - UpsertBySKU: 按 SKU 批量新增或更新商品（单个事务）
+ * - ExportInBatches: 按筛选条件分批读取商品，用于流式导出
+ */
+
+/**
+ * ProductExportFilter 商品导出筛选条件
+ *
+ * 零值字段表示不过滤。
+ */
+type ProductExportFilter struct {
+	Category string // 分类
+	Status   *int   // 上下架状态
+	Keyword  string // 名称或 SKU 模糊匹配
+}
+
+/**
+ * ProductUpsertItem 待导入的商品
+ *
+ * StatusSet 为 false 表示导入数据没有指定上下架状态：
+ * 新建商品使用 Product.Status，已有商品保持原状态。
+ */
+type ProductUpsertItem struct {
+	Product   *model.Product
+	StatusSet bool
+}
+
+/**
+ * UpsertBySKU 按 SKU 批量新增或更新商品（带事务）
+ *
+ * - SKU 已存在：覆盖目录字段（名称、描述、详情、价格、库存、分类、主图，指定了状态时包括状态）
+ * - SKU 已被软删除：恢复该商品并覆盖字段，避免唯一索引冲突
+ * - SKU 不存在：新建商品
+ *
+ * 整批在同一事务中执行，任意一行失败整批回滚。
+ * 已有商品价格变化时写入价格变更记录。
+ *
+ * 参数：
+ *   items []ProductUpsertItem - 待导入的商品，SKU 不能为空且批内不重复
+ *   operatorID uint - 操作人用户ID
+ *
+ * 返回值：
+ *   createdIDs []uint - 新建的商品ID
+ *   updatedIDs []uint - 被更新的商品ID
+ *   error - 失败时返回错误
+ */
+func (r *ProductRepository) UpsertBySKU(items []ProductUpsertItem, operatorID uint) (createdIDs, updatedIDs []uint, err error) {
+	if len(items) == 0 {
+		return nil, nil, nil
+	}
+
+	skus := make([]string, 0, len(items))
+	for _, item := range items {
+		skus = append(skus, *item.Product.SKU)
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		createdIDs, updatedIDs = nil, nil
+
+		// 包含已软删除的记录，SKU 唯一索引对它们同样生效
+		var existing []model.Product
+		if err := tx.Unscoped().Where("sku IN ?", skus).Find(&existing).Error; err != nil {
+			return err
+		}
+		existingBySKU := make(map[string]*model.Product, len(existing))
+		for i := range existing {
+			existingBySKU[*existing[i].SKU] = &existing[i]
+		}
+
+		for _, item := range items {
+			p := item.Product
+			if old, ok := existingBySKU[*p.SKU]; ok {
+				updates := map[string]interface{}{
+					"name":        p.Name,
+					"description": p.Description,
+					"detail":      p.Detail,
+					"price":       p.Price,
+					"stock":       p.Stock,
+					"category":    p.Category,
+					"image_url":   p.ImageURL,
+					"deleted_at":  nil,
+				}
+				if item.StatusSet {
+					updates["status"] = p.Status
+				}
+				if err := tx.Unscoped().Model(&model.Product{}).Where("id = ?", old.ID).Updates(updates).Error; err != nil {
+					return err
+				}
+				if old.Price != p.Price {
+					if err := tx.Create(&model.ProductPriceHistory{
+						ProductID:  old.ID,
+						OldPrice:   old.Price,
+						NewPrice:   p.Price,
+						OperatorID: operatorID,
+						Reason:     "批量导入",
+					}).Error; err != nil {
+						return err
+					}
+				}
+				p.ID = old.ID
+				updatedIDs = append(updatedIDs, old.ID)
+				continue
+			}
+
+			status := p.Status
+			if err := tx.Create(p).Error; err != nil {
+				return err
+			}
+			// status 列带 default:1，零值不会被写入，下架商品需要单独更新
+			if status == 0 {
+				if err := tx.Model(p).Update("status", 0).Error; err != nil {
+					return err
+				}
+			}
+			createdIDs = append(createdIDs, p.ID)
+		}
+		return nil
+	})
+	return createdIDs, updatedIDs, err
+}
+
+/**
+ * ExportInBatches 按筛选条件分批读取商品
+ *
+ * 使用 FindInBatches 按主键分批，每批处理完再读下一批，内存占用与总量无关。
+ *
+ * 参数：
+ *   filter ProductExportFilter - 筛选条件
+ *   batchSize int - 每批数量
+ *   fn func([]model.Product) error - 每批回调，返回错误时终止
+ */
+func (r *ProductRepository) ExportInBatches(filter ProductExportFilter, batchSize int, fn func(products []model.Product) error) error {
+	query := database.DB.Model(&model.Product{})
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.Keyword != "" {
+		like := "%" + filter.Keyword + "%"
+		query = query.Where("name LIKE ? OR sku LIKE ?", like, like)
+	}
+
+	var products []model.Product
+	return query.FindInBatches(&products, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(products)
+	}).Error
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"gomall/backend/internal/cache"
+	"gomall/backend/internal/config"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/redis"
+
+	"golang.org/x/sync/singleflight"
+)
+
+/**
+ * ==================== 商品列表缓存 ====================
+ *
+ * 商品列表（按分类、店铺筛选）走二级缓存，过期时间较短（cache.list_ttl，默认 30 秒）。
+ * 列表的筛选组合很多，写入时把 key 记录到集合 ProductListKeysKey，
+ * 商品创建、修改、删除、定时上下架和店铺状态变化后调用 InvalidateListCache 一次删除全部列表缓存。
+ * 库存、收藏数变化不清除列表缓存，由过期时间兜底。
+ *
+ * 只缓存前 maxCachedListPage 页且结果不为空的列表，避免任意分类名、深翻页写入大量缓存。
+ *
+ * 提供的方法：
+ * - ProductRepository.GetListWithCache: 商品列表（带缓存）
+ * - ProductRepository.InvalidateListCache: 删除全部商品列表缓存
+ */
+
+const (
+	// ProductListCachePrefix 商品列表缓存 key 前缀
+	ProductListCachePrefix = "product_list"
+	// ProductListKeysKey 记录已写入的商品列表缓存 key 的集合
+	ProductListKeysKey = "gomall:product_list:keys"
+
+	defaultProductListTTL = 30 * time.Second
+	maxCachedListPage     = 5
+)
+
+/**
+ * productListEntry 列表缓存内容
+ */
+type productListEntry struct {
+	Products []model.Product `json:"products"`
+	Total    int64           `json:"total"`
+}
+
+var productListLoadGroup singleflight.Group
+
+var (
+	productListCacheOnce sync.Once
+	productListCache     *cache.Cache
+)
+
+/**
+ * getProductListCache 获取商品列表二级缓存（首次使用时按配置创建）
+ */
+func getProductListCache() *cache.Cache {
+	productListCacheOnce.Do(func() {
+		productListCache = cache.NewFromConfig(ProductListCachePrefix)
+	})
+	return productListCache
+}
+
+/**
+ * productListCacheKey 商品列表缓存 key
+ */
+func productListCacheKey(page, pageSize int, category string, shopID uint) string {
+	return redis.CacheKey(ProductListCachePrefix, fmt.Sprintf("%d:%d:%d:%s", shopID, page, pageSize, category))
+}
+
+/**
+ * GetListWithCache 获取商品列表（带缓存）
+ *
+ * 参数和返回值与 GetList 相同，返回的切片不与其他请求共享。
+ */
+func (r *ProductRepository) GetListWithCache(page, pageSize int, category string, shopID uint) ([]model.Product, int64) {
+	if page > maxCachedListPage {
+		return r.GetList(page, pageSize, category, shopID)
+	}
+
+	key := productListCacheKey(page, pageSize, category, shopID)
+	ctx, cancel := context.WithTimeout(context.Background(), productCacheTimeout)
+	defer cancel()
+
+	if data, ok := getProductListCache().Get(ctx, key); ok {
+		var entry productListEntry
+		if err := json.Unmarshal(data, &entry); err == nil {
+			return entry.Products, entry.Total
+		}
+	}
+
+	v, _, _ := productListLoadGroup.Do(key, func() (interface{}, error) {
+		products, total := r.GetList(page, pageSize, category, shopID)
+		entry := &productListEntry{Products: products, Total: total}
+		if total > 0 {
+			r.setListCache(key, entry)
+		}
+		return entry, nil
+	})
+
+	// singleflight 的结果由所有等待者共享，复制一份再返回
+	entry := v.(*productListEntry)
+	products := make([]model.Product, len(entry.Products))
+	copy(products, entry.Products)
+	return products, entry.Total
+}
+
+/**
+ * InvalidateListCache 删除全部商品列表缓存
+ *
+ * 先清空本机的本地缓存，Redis 不可用时也能立即生效；
+ * Redis 可用时再按 ProductListKeysKey 删除 Redis 中的列表并广播给其他实例。
+ * 删除失败只会导致短暂的旧列表，缓存过期后自动恢复，因此忽略错误。
+ */
+func (r *ProductRepository) InvalidateListCache() {
+	getProductListCache().PurgeLocal()
+	if redis.Client == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), productCacheTimeout)
+	defer cancel()
+
+	keys, err := redis.Client.SMembers(ctx, ProductListKeysKey).Result()
+	if err != nil || len(keys) == 0 {
+		return
+	}
+	if err := getProductListCache().Invalidate(ctx, keys...); err != nil {
+		return
+	}
+	_ = redis.Client.SRem(ctx, ProductListKeysKey, keys).Err()
+}
+
+/**
+ * setListCache 写入商品列表缓存，并把 key 记录到 ProductListKeysKey
+ */
+func (r *ProductRepository) setListCache(key string, entry *productListEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), productCacheTimeout)
+	defer cancel()
+
+	ttl := productListTTL()
+	if redis.Client != nil {
+		// 先记录 key 再写缓存，失效时不会漏掉刚写入的列表
+		if err := redis.Client.SAdd(ctx, ProductListKeysKey, key).Err(); err != nil {
+			return
+		}
+		_ = redis.Client.Expire(ctx, ProductListKeysKey, 2*ttl).Err()
+	}
+	_ = getProductListCache().Set(ctx, key, data, ttl)
+}
+
+/**
+ * productListTTL 读取列表缓存过期时间（cache.list_ttl，秒）
+ */
+func productListTTL() time.Duration {
+	if config.Config == nil {
+		return defaultProductListTTL
+	}
+	if cacheConfig := config.GetCache(); cacheConfig != nil {
+		if v := cacheConfig.GetInt("list_ttl"); v > 0 {
+			return time.Duration(v) * time.Second
+		}
+	}
+	return defaultProductListTTL
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"time"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * ==================== 商品价格记录 ====================
+ *
+ * 提供的方法：
+ * - UpdateWithPriceHistory: 更新商品并记录价格变更（同一事务）
+ * - ListPriceHistory: 分页查询价格变更记录
+ * - LowestPriceSince: 查询时间窗口内出现过的最低价
+ */
+
+/**
+ * UpdateWithPriceHistory 更新商品并写入价格变更记录（带事务）
+ *
+ * 参数：
+ *   product *model.Product - 要更新的商品对象
+ *   history *model.ProductPriceHistory - 价格变更记录，为 nil 时只更新商品
+ */
+func (r *ProductRepository) UpdateWithPriceHistory(product *model.Product, history *model.ProductPriceHistory) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		// 收藏人数由收藏记录维护，不随商品编辑覆盖
+		if err := tx.Omit("favorite_count").Save(product).Error; err != nil {
+			return err
+		}
+		if history == nil {
+			return nil
+		}
+		history.ProductID = product.ID
+		return tx.Create(history).Error
+	})
+}
+
+/**
+ * ListPriceHistory 分页查询商品价格变更记录（按时间倒序）
+ *
+ * 返回值：
+ *   []model.ProductPriceHistory - 变更记录
+ *   int64 - 总记录数
+ *   error - 查询失败时返回错误
+ */
+func (r *ProductRepository) ListPriceHistory(productID uint, page, pageSize int) ([]model.ProductPriceHistory, int64, error) {
+	var records []model.ProductPriceHistory
+	var total int64
+
+	query := database.DB.Model(&model.ProductPriceHistory{}).Where("product_id = ?", productID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&records).Error
+	return records, total, err
+}
+
+/**
+ * LowestPriceSince 查询 since 之后变更记录中出现过的最低价
+ *
+ * 窗口内每条记录的旧价格和新价格都曾在窗口内生效过，
+ * 因此两者的最小值就是窗口内的历史最低价（不含当前价格，由调用方比较）。
+ *
+ * 返回值：
+ *   float64 - 最低价
+ *   bool - 窗口内是否有变更记录
+ *   error - 查询失败时返回错误
+ */
+func (r *ProductRepository) LowestPriceSince(productID uint, since time.Time) (float64, bool, error) {
+	var result struct {
+		MinOld *float64
+		MinNew *float64
+	}
+	err := database.DB.Model(&model.ProductPriceHistory{}).
+		Select("MIN(old_price) AS min_old, MIN(new_price) AS min_new").
+		Where("product_id = ? AND created_at >= ?", productID, since).
+		Scan(&result).Error
+	if err != nil || result.MinOld == nil || result.MinNew == nil {
+		return 0, false, err
+	}
+
+	lowest := *result.MinOld
+	if *result.MinNew < lowest {
+		lowest = *result.MinNew
+	}
+	return lowest, true, nil
+}
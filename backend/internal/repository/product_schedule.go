@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"time"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+)
+
+/**
+ * ==================== 商品定时上下架 ====================
+ *
+ * 提供的方法：
+ * - FindDueSchedules: 查询到期的上下架计划
+ * - ApplyPublish: 执行定时上架
+ * - ApplyUnpublish: 执行定时下架
+ *
+ * 执行方法使用条件更新（WHERE 带上原计划时间），
+ * 计划在执行前被管理员修改或被其他实例执行过时不会重复生效。
+ */
+
+/**
+ * FindDueSchedules 查询到期的上下架计划
+ *
+ * 参数：
+ *   now time.Time - 当前时间
+ *   limit int - 单次最多处理的数量
+ */
+func (r *ProductRepository) FindDueSchedules(now time.Time, limit int) ([]model.Product, error) {
+	var products []model.Product
+	err := database.DB.
+		Where("(publish_at IS NOT NULL AND publish_at <= ?) OR (unpublish_at IS NOT NULL AND unpublish_at <= ?)", now, now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&products).Error
+	return products, err
+}
+
+/**
+ * ApplyPublish 执行定时上架：status 置为 1 并清空上架时间
+ *
+ * 返回值：
+ *   bool - 是否实际更新（计划已变更时为 false）
+ */
+func (r *ProductRepository) ApplyPublish(id uint, publishAt time.Time) (bool, error) {
+	result := database.DB.Model(&model.Product{}).
+		Where("id = ? AND publish_at = ?", id, publishAt).
+		Updates(map[string]interface{}{"status": 1, "publish_at": nil})
+	return result.RowsAffected > 0, result.Error
+}
+
+/**
+ * ApplyUnpublish 执行定时下架：status 置为 0 并清空上下架时间
+ *
+ * 下架时间总是晚于上架时间，到期的上架计划一并作废。
+ *
+ * 返回值：
+ *   bool - 是否实际更新（计划已变更时为 false）
+ */
+func (r *ProductRepository) ApplyUnpublish(id uint, unpublishAt time.Time) (bool, error) {
+	result := database.DB.Model(&model.Product{}).
+		Where("id = ? AND unpublish_at = ?", id, unpublishAt).
+		Updates(map[string]interface{}{"status": 0, "publish_at": nil, "unpublish_at": nil})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"time"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * ==================== RecommendationRepository 商品推荐数据访问层 ====================
+ *
+ * 负责"经常一起购买"推荐结果的读写，以及离线计算所需的购物篮数据读取。
+ *
+ * 提供的方法：
+ * - ReplaceAll: 用新的计算结果整体替换推荐表（单个事务）
+ * - ListByProductID: 按关联度获取商品的推荐
+ * - OrderRepository.ScanPaidItems: 按用户、时间顺序流式读取已支付订单
+ * - CartRepository.ScanItems: 按用户顺序流式读取购物车
+ */
+
+/**
+ * recommendationInsertBatch 写入推荐结果的批大小
+ */
+const recommendationInsertBatch = 500
+
+/**
+ * RecommendationRepository 商品推荐仓储结构体
+ */
+type RecommendationRepository struct{}
+
+/**
+ * NewRecommendationRepository 创建商品推荐仓库实例
+ */
+func NewRecommendationRepository() *RecommendationRepository {
+	return &RecommendationRepository{}
+}
+
+/**
+ * ReplaceAll 用新的计算结果整体替换推荐表（带事务）
+ *
+ * 删除旧结果和写入新结果在同一事务中完成，接口不会读到计算了一半的数据。
+ *
+ * 参数：
+ *   recommendations []model.ProductRecommendation - 全部推荐结果
+ */
+func (r *RecommendationRepository) ReplaceAll(recommendations []model.ProductRecommendation) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&model.ProductRecommendation{}).Error; err != nil {
+			return err
+		}
+		if len(recommendations) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(recommendations, recommendationInsertBatch).Error
+	})
+}
+
+/**
+ * ListByProductID 按关联度从高到低获取商品的推荐
+ *
+ * 参数：
+ *   productID uint - 商品ID
+ *   limit int - 最多返回条数
+ */
+func (r *RecommendationRepository) ListByProductID(productID uint, limit int) ([]model.ProductRecommendation, error) {
+	var recommendations []model.ProductRecommendation
+	err := database.DB.Where("product_id = ?", productID).
+		Order("score DESC, co_count DESC").
+		Limit(limit).
+		Find(&recommendations).Error
+	return recommendations, err
+}
+
+/**
+ * BasketItem 购物篮中的一件商品
+ */
+type BasketItem struct {
+	UserID    uint
+	ProductID uint
+	CreatedAt time.Time
+}
+
+/**
+ * ScanPaidItems 按用户、下单时间顺序流式读取 since 之后的已支付订单
+ *
+ * 使用游标逐行读取，内存占用与订单总量无关。
+ *
+ * 参数：
+ *   since time.Time - 起始时间
+ *   fn func(BasketItem) error - 每行回调，返回错误时终止
+ */
+func (r *OrderRepository) ScanPaidItems(since time.Time, fn func(item BasketItem) error) error {
+	rows, err := database.DB.Table("(?) AS o", orderLines()).
+		Select("user_id, product_id, created_at").
+		Where("status IN ? AND created_at >= ?", paidOrderStatuses, since).
+		Order("user_id, created_at").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item BasketItem
+		if err := rows.Scan(&item.UserID, &item.ProductID, &item.CreatedAt); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+/**
+ * ScanItems 按用户顺序流式读取全部购物车记录
+ *
+ * 参数：
+ *   fn func(BasketItem) error - 每行回调，返回错误时终止
+ */
+func (r *CartRepository) ScanItems(fn func(item BasketItem) error) error {
+	rows, err := database.DB.Model(&model.Cart{}).
+		Select("user_id, product_id, created_at").
+		Order("user_id, created_at").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item BasketItem
+		if err := rows.Scan(&item.UserID, &item.ProductID, &item.CreatedAt); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
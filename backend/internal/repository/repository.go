@@ -274,7 +274,8 @@ func (r *UserRepository) Update(user *model.User) error {
  * - Update: 更新商品信息
  * - Delete: 删除商品（软删除）
  * - GetByIDs: 批量获取商品
- * - GetByIDsWithCache: 批量获取商品（带缓存）
+ * - GetByIDWithCache / GetByIDsWithCache: 带缓存的查询（见 product_cache.go）
+ * - ScanIDs: 分批遍历商品ID
  */
 
 /**
@@ -299,7 +300,16 @@ func NewProductRepository() *ProductRepository {
  *   error - 创建失败时返回错误
  */
 func (r *ProductRepository) Create(product *model.Product) error {
-	return database.DB.Create(product).Error
+	status := product.Status
+	if err := database.DB.Create(product).Error; err != nil {
+		return err
+	}
+	// status 列带 default:1，零值不会被写入，定时上架的商品需要单独置为下架
+	if status == 0 {
+		product.Status = 0
+		return database.DB.Model(product).Update("status", 0).Error
+	}
+	return nil
 }
 
 /**
@@ -323,40 +333,63 @@ func (r *ProductRepository) GetByID(id uint) (*model.Product, error) {
 	return &product, nil
 }
 
+/**
+ * onShelfCondition 上架商品的查询条件，与 model.Product.EffectiveStatus 保持一致
+ *
+ * 下架时间未到，且 (status = 1 或 上架时间已到)。
+ * 两个占位符都传入当前时间。
+ */
+const onShelfCondition = "(unpublish_at IS NULL OR unpublish_at > ?) AND (status = 1 OR (publish_at IS NOT NULL AND publish_at <= ?))"
+
+/**
+ * shopOpenCondition 商品属于平台自营或营业中的店铺，占位符传入 model.ShopStatusApproved
+ */
+const shopOpenCondition = "(shop_id = 0 OR shop_id IN (SELECT id FROM shops WHERE status = ? AND deleted_at IS NULL))"
+
 /**
  * GetList 获取商品列表
  *
- * 支持分页查询和分类筛选。
+ * 支持分页查询、分类和店铺筛选。
  *
  * 参数：
  *   page int - 页码，从1开始
  *   pageSize int - 每页数量
  *   category string - 分类筛选条件，空字符串表示不过滤
+ *   shopID uint - 店铺筛选条件，0 表示不过滤
  *
  * 返回值：
  *   []model.Product - 商品列表
  *   int64 - 总记录数（用于计算总页数）
  *
  * 查询说明：
- * - .Where("status = ?", 1) 只查询上架商品
+ * - 只查询上架商品（按定时上下架时间计算实际状态，见 onShelfCondition）
+ * - 只查询平台自营和营业中店铺的商品（见 shopOpenCondition）
  * - .Count() 统计总数（不含分页）
  * - .Offset() 跳过前面 N 条
  * - .Limit() 限制返回数量
  * - .Order() 排序（按创建时间倒序）
  */
-func (r *ProductRepository) GetList(page, pageSize int, category string) ([]model.Product, int64) {
+func (r *ProductRepository) GetList(page, pageSize int, category string, shopID uint) ([]model.Product, int64) {
 	var products []model.Product
 	var total int64
 
 	// 构建基础查询
-	// 只查询上架的商品（status = 1）
-	query := database.DB.Model(&model.Product{}).Where("status = ?", 1)
+	// 只查询上架的商品，定时任务延迟时也按计划时间过滤
+	now := time.Now()
+	query := database.DB.Model(&model.Product{}).
+		Where(onShelfCondition, now, now).
+		Where(shopOpenCondition, model.ShopStatusApproved)
 
 	// 如果指定了分类，添加分类筛选条件
 	if category != "" {
 		query = query.Where("category = ?", category)
 	}
 
+	// 如果指定了店铺，只查询该店铺的商品
+	if shopID > 0 {
+		query = query.Where("shop_id = ?", shopID)
+	}
+
 	// 统计符合条件的总记录数
 	// 这个值不随分页参数变化
 	query.Count(&total)
@@ -384,7 +417,8 @@ func (r *ProductRepository) GetList(page, pageSize int, category string) ([]mode
  *   error - 更新失败时返回错误
  */
 func (r *ProductRepository) Update(product *model.Product) error {
-	return database.DB.Save(product).Error
+	// 收藏人数由收藏记录维护，不随商品编辑覆盖
+	return database.DB.Omit("favorite_count").Save(product).Error
 }
 
 /**
@@ -443,29 +477,38 @@ func (r *ProductRepository) GetByIDs(ids []uint) ([]model.Product, error) {
 }
 
 /**
- * GetByIDsWithCache 批量获取商品（带缓存）
+ * ScanIDs 按ID升序分批遍历商品ID
  *
- * 这是一个预留方法，目前直接调用 GetByIDs()。
- * 后续可以加入 Redis 缓存逻辑：
- * 1. 先从 Redis 获取
- * 2. 缓存中没有再查数据库
- * 3. 查到的数据写入 Redis
+ * 使用 id > lastID 的游标分页，避免大表 OFFSET 越翻越慢。
+ * 用于构建布隆过滤器等需要全量商品ID的场景。
  *
  * 参数：
- *   ids []uint - 商品ID列表
- *
- * 返回值：
- *   []model.Product - 找到的商品列表
- *   error - 查询失败时返回错误
- */
-func (r *ProductRepository) GetByIDsWithCache(ids []uint) ([]model.Product, error) {
-	// TODO: 实现带Redis缓存的批量查询
-	// 1. 从Redis批量获取
-	// 2. 找出未缓存的ID
-	// 3. 从数据库查询未缓存的
-	// 4. 写入Redis
-	// 5. 返回结果
-	return r.GetByIDs(ids)
+ *   afterID uint - 从该ID之后开始遍历（不含）
+ *   batchSize int - 每批数量
+ *   fn func([]uint) error - 每批回调，返回错误时终止遍历
+ */
+func (r *ProductRepository) ScanIDs(afterID uint, batchSize int, fn func(ids []uint) error) error {
+	lastID := afterID
+	for {
+		var ids []uint
+		if err := database.DB.Model(&model.Product{}).
+			Where("id > ?", lastID).
+			Order("id ASC").
+			Limit(batchSize).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := fn(ids); err != nil {
+			return err
+		}
+		lastID = ids[len(ids)-1]
+		if len(ids) < batchSize {
+			return nil
+		}
+	}
 }
 
 /**
@@ -480,6 +523,9 @@ func (r *ProductRepository) GetByIDsWithCache(ids []uint) ([]model.Product, erro
  * - GetByOrderNo: 根据订单号获取订单
  * - GetByUserID: 获取用户的订单列表
  * - Update: 更新订单信息
+ * - MarkPaid: 待支付订单标记为已支付（见 order_sales.go）
+ * - SalesBetween: 按商品汇总时间段内的销量（见 order_sales.go）
+ * - CreateCombined / CancelCombined: 购物车结算拆单（见 order_split.go）
  */
 
 /**
@@ -514,7 +560,7 @@ func NewOrderRepository() *OrderRepository {
 func (r *OrderRepository) Create(order *model.Order) error {
 	// database.DB.Transaction() 创建事务
 	// 传入的函数中的所有操作都在一个事务中
-	return database.DB.Transaction(func(tx *gorm.DB) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
 		// 1. 创建订单记录
 		// tx.Create() 使用传入的事务实例
 		if err := tx.Create(order).Error; err != nil {
@@ -543,6 +589,13 @@ func (r *OrderRepository) Create(order *model.Order) error {
 		// 5. 返回 nil 表示事务提交
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	// 6. 事务提交后删除商品缓存，详情页库存及时刷新
+	NewProductRepository().InvalidateCache(order.ProductID)
+	return nil
 }
 
 /**
@@ -601,8 +654,8 @@ func (r *OrderRepository) GetByUserID(userID uint, page, pageSize int) ([]model.
 	var orders []model.Order
 	var total int64
 
-	// 按用户ID查询
-	query := database.DB.Model(&model.Order{}).Where("user_id = ?", userID)
+	// 按用户ID查询，结算子订单在父订单详情中查看，不单独列出
+	query := database.DB.Model(&model.Order{}).Where("user_id = ? AND parent_id = 0", userID)
 
 	// 统计总数
 	query.Count(&total)
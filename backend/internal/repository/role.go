@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"errors"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * ==================== RoleRepository 角色权限数据访问层 ====================
+ *
+ * 负责角色、权限及角色权限关联的读写，以及用户角色的调整。
+ *
+ * 提供的方法：
+ * - List: 获取全部角色
+ * - GetByID: 根据ID获取角色
+ * - GetByCode: 根据编码获取角色
+ * - Create: 创建角色并写入权限（单个事务）
+ * - ListPermissions: 获取全部权限
+ * - PermissionCodes: 获取每个角色拥有的权限编码
+ * - SetPermissions: 整体替换角色的权限（单个事务）
+ * - UserRepository.ChangeRole: 修改用户角色并写入审计日志（单个事务）
+ */
+
+/**
+ * ErrRoleNotFound 角色不存在错误
+ */
+var ErrRoleNotFound = errors.New("角色不存在")
+
+/**
+ * RoleRepository 角色权限仓储结构体
+ */
+type RoleRepository struct{}
+
+/**
+ * NewRoleRepository 创建角色权限仓库实例
+ */
+func NewRoleRepository() *RoleRepository {
+	return &RoleRepository{}
+}
+
+/**
+ * List 按ID顺序获取全部角色
+ */
+func (r *RoleRepository) List() ([]model.Role, error) {
+	var roles []model.Role
+	err := database.DB.Order("id").Find(&roles).Error
+	return roles, err
+}
+
+/**
+ * GetByID 根据ID获取角色
+ *
+ * 返回值：
+ *   error - 未找到返回 ErrRoleNotFound
+ */
+func (r *RoleRepository) GetByID(id uint) (*model.Role, error) {
+	var role model.Role
+	if err := database.DB.First(&role, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+/**
+ * GetByCode 根据编码获取角色
+ *
+ * 返回值：
+ *   error - 未找到返回 ErrRoleNotFound
+ */
+func (r *RoleRepository) GetByCode(code string) (*model.Role, error) {
+	var role model.Role
+	if err := database.DB.Where("code = ?", code).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+/**
+ * Create 创建角色并写入权限（带事务）
+ *
+ * 参数：
+ *   role *model.Role - 角色，创建后回填ID
+ *   permissionIDs []uint - 角色拥有的权限ID
+ */
+func (r *RoleRepository) Create(role *model.Role, permissionIDs []uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(role).Error; err != nil {
+			return err
+		}
+		return createRolePermissions(tx, role.ID, permissionIDs)
+	})
+}
+
+/**
+ * ListPermissions 按ID顺序获取全部权限
+ */
+func (r *RoleRepository) ListPermissions() ([]model.Permission, error) {
+	var permissions []model.Permission
+	err := database.DB.Order("id").Find(&permissions).Error
+	return permissions, err
+}
+
+/**
+ * PermissionCodes 获取每个角色拥有的权限编码
+ *
+ * 返回值：
+ *   map[uint][]string - 角色ID -> 权限编码列表，没有权限的角色不在结果中
+ */
+func (r *RoleRepository) PermissionCodes() (map[uint][]string, error) {
+	var rows []struct {
+		RoleID uint
+		Code   string
+	}
+	err := database.DB.Model(&model.RolePermission{}).
+		Select("role_permissions.role_id, permissions.code").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Order("role_permissions.role_id, permissions.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make(map[uint][]string)
+	for _, row := range rows {
+		codes[row.RoleID] = append(codes[row.RoleID], row.Code)
+	}
+	return codes, nil
+}
+
+/**
+ * SetPermissions 整体替换角色的权限（带事务）
+ *
+ * 参数：
+ *   roleID uint - 角色ID
+ *   permissionIDs []uint - 新的权限ID列表，为空时清空角色权限
+ */
+func (r *RoleRepository) SetPermissions(roleID uint, permissionIDs []uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", roleID).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return createRolePermissions(tx, roleID, permissionIDs)
+	})
+}
+
+/**
+ * createRolePermissions 在事务中写入角色权限关联
+ */
+func createRolePermissions(tx *gorm.DB, roleID uint, permissionIDs []uint) error {
+	if len(permissionIDs) == 0 {
+		return nil
+	}
+	rolePermissions := make([]model.RolePermission, len(permissionIDs))
+	for i, id := range permissionIDs {
+		rolePermissions[i] = model.RolePermission{RoleID: roleID, PermissionID: id}
+	}
+	return tx.Create(&rolePermissions).Error
+}
+
+/**
+ * ChangeRole 修改用户角色并写入审计日志（带事务）
+ *
+ * 参数：
+ *   userID uint - 用户ID
+ *   from int - 用户当前角色，角色已被并发修改时不更新
+ *   to int - 新角色ID
+ *   log *model.AdminAuditLog - 审计日志，角色变更成功时写入
+ *
+ * 返回值：
+ *   bool - 是否变更成功，当前角色不是 from 时返回 false
+ */
+func (r *UserRepository) ChangeRole(userID uint, from, to int, log *model.AdminAuditLog) (bool, error) {
+	changed := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).
+			Where("id = ? AND role = ?", userID, from).
+			Update("role", to)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		changed = true
+		return tx.Create(log).Error
+	})
+	return changed, err
+}
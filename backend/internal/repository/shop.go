@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * ==================== 店铺 ====================
+ *
+ * 店铺审核等状态变更都带有状态条件，并与审计日志在同一事务中写入。
+ * 商家后台的商品、订单操作都带有 shop_id 条件，不能操作其他店铺的数据。
+ *
+ * 提供的方法：
+ * - ShopRepository.Create: 创建开店申请
+ * - ShopRepository.GetByID / GetByOwner: 查询店铺
+ * - ShopRepository.UpdateInfo: 修改店铺资料，被驳回的申请重新进入待审核
+ * - ShopRepository.List: 按状态、关键字分页查询店铺
+ * - ShopRepository.Review: 审核、停业并写入审计日志，同时授予或收回店主的商家角色
+ * - ShopRepository.IsOpen: 店铺是否营业中
+ * - ProductRepository.ListByShop: 店铺商品列表（含下架商品）
+ * - ProductRepository.GetByShop: 查询本店商品
+ * - ProductRepository.SetStock: 设置本店商品库存
+ * - OrderRepository.ListByShop: 店铺订单列表
+ * - OrderRepository.GetByShop: 查询本店订单
+ * - OrderRepository.MarkShipped: 已支付订单标记为已发货
+ */
+
+// ErrShopNotFound 店铺不存在
+var ErrShopNotFound = errors.New("店铺不存在")
+
+// ShopRepository 店铺数据访问
+type ShopRepository struct{}
+
+// NewShopRepository 创建店铺Repository实例
+func NewShopRepository() *ShopRepository {
+	return &ShopRepository{}
+}
+
+/**
+ * Create 创建开店申请
+ */
+func (r *ShopRepository) Create(shop *model.Shop) error {
+	return database.DB.Create(shop).Error
+}
+
+/**
+ * GetByID 根据ID查询店铺
+ */
+func (r *ShopRepository) GetByID(id uint) (*model.Shop, error) {
+	var shop model.Shop
+	if err := database.DB.First(&shop, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShopNotFound
+		}
+		return nil, err
+	}
+	return &shop, nil
+}
+
+/**
+ * GetByOwner 查询用户的店铺
+ */
+func (r *ShopRepository) GetByOwner(ownerID uint) (*model.Shop, error) {
+	var shop model.Shop
+	if err := database.DB.Where("owner_id = ?", ownerID).First(&shop).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShopNotFound
+		}
+		return nil, err
+	}
+	return &shop, nil
+}
+
+/**
+ * GetByName 根据名称查询店铺
+ */
+func (r *ShopRepository) GetByName(name string) (*model.Shop, error) {
+	var shop model.Shop
+	if err := database.DB.Where("name = ?", name).First(&shop).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShopNotFound
+		}
+		return nil, err
+	}
+	return &shop, nil
+}
+
+/**
+ * UpdateInfo 修改店铺名称、简介和Logo
+ *
+ * 被驳回的申请修改后重新进入待审核状态。
+ */
+func (r *ShopRepository) UpdateInfo(shop *model.Shop) error {
+	updates := map[string]interface{}{
+		"name":        shop.Name,
+		"description": shop.Description,
+		"logo":        shop.Logo,
+	}
+	if shop.Status == model.ShopStatusRejected {
+		updates["status"] = model.ShopStatusPending
+		shop.Status = model.ShopStatusPending
+	}
+	return database.DB.Model(&model.Shop{}).Where("id = ?", shop.ID).Updates(updates).Error
+}
+
+// ShopQuery 店铺查询条件，零值表示不过滤
+type ShopQuery struct {
+	Status  int
+	Keyword string // 匹配店铺名称
+}
+
+/**
+ * List 分页查询店铺，按申请时间倒序
+ */
+func (r *ShopRepository) List(q ShopQuery, page, pageSize int) ([]model.Shop, int64, error) {
+	query := database.DB.Model(&model.Shop{})
+	if q.Status > 0 {
+		query = query.Where("status = ?", q.Status)
+	}
+	if q.Keyword != "" {
+		query = query.Where("name LIKE ?", "%"+q.Keyword+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var shops []model.Shop
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&shops).Error
+	return shops, total, err
+}
+
+/**
+ * OwnerRoleChange 店铺状态变更时店主角色的调整
+ *
+ * 只有店主当前角色为 From 时才改为 To，管理员等其他角色不受影响；Applied 回填是否实际修改。
+ */
+type OwnerRoleChange struct {
+	OwnerID uint
+	From    int
+	To      int
+	Applied bool
+}
+
+/**
+ * Review 变更店铺状态并写入审计日志（带事务）
+ *
+ * 参数：
+ *   id uint - 店铺ID
+ *   from []int - 允许变更的当前状态
+ *   to int - 目标状态
+ *   reason string - 驳回或停业原因
+ *   log *model.AdminAuditLog - 审计日志，状态变更成功时写入
+ *   role *OwnerRoleChange - 店主角色调整，为 nil 时不修改角色
+ *
+ * 返回值：
+ *   bool - 是否变更成功，当前状态不在 from 中时返回 false
+ */
+func (r *ShopRepository) Review(id uint, from []int, to int, reason string, log *model.AdminAuditLog, role *OwnerRoleChange) (bool, error) {
+	changed := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Shop{}).
+			Where("id = ? AND status IN ?", id, from).
+			Updates(map[string]interface{}{
+				"status":        to,
+				"review_reason": reason,
+				"reviewed_by":   log.OperatorID,
+				"reviewed_at":   time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		changed = true
+		if err := tx.Create(log).Error; err != nil {
+			return err
+		}
+
+		if role == nil {
+			return nil
+		}
+		result = tx.Model(&model.User{}).
+			Where("id = ? AND role = ?", role.OwnerID, role.From).
+			Update("role", role.To)
+		if result.Error != nil {
+			return result.Error
+		}
+		role.Applied = result.RowsAffected > 0
+		return nil
+	})
+	return changed, err
+}
+
+/**
+ * IsOpen 店铺是否营业中，shopID 为 0（平台自营）时返回 true
+ */
+func (r *ShopRepository) IsOpen(shopID uint) (bool, error) {
+	if shopID == 0 {
+		return true, nil
+	}
+	var count int64
+	err := database.DB.Model(&model.Shop{}).
+		Where("id = ? AND status = ?", shopID, model.ShopStatusApproved).
+		Count(&count).Error
+	return count > 0, err
+}
+
+/**
+ * ListByShop 店铺商品列表，包含下架和定时上架的商品，按创建时间倒序
+ */
+func (r *ProductRepository) ListByShop(shopID uint, page, pageSize int) ([]model.Product, int64, error) {
+	query := database.DB.Model(&model.Product{}).Where("shop_id = ?", shopID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var products []model.Product
+	err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&products).Error
+	return products, total, err
+}
+
+/**
+ * GetByShop 查询本店商品，商品不存在或属于其他店铺时返回 ErrProductNotFound
+ */
+func (r *ProductRepository) GetByShop(id, shopID uint) (*model.Product, error) {
+	var product model.Product
+	if err := database.DB.Where("id = ? AND shop_id = ?", id, shopID).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+/**
+ * SetStock 设置本店商品库存
+ *
+ * 返回值：
+ *   bool - 是否更新成功，商品不属于该店铺时返回 false
+ */
+func (r *ProductRepository) SetStock(id, shopID uint, stock int) (bool, error) {
+	result := database.DB.Model(&model.Product{}).
+		Where("id = ? AND shop_id = ?", id, shopID).
+		Update("stock", stock)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		r.InvalidateCache(id)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+/**
+ * ListByShop 店铺订单列表，status 小于 0 时不按状态过滤，按创建时间倒序
+ */
+func (r *OrderRepository) ListByShop(shopID uint, status, page, pageSize int) ([]model.Order, int64, error) {
+	query := database.DB.Model(&model.Order{}).Where("shop_id = ?", shopID)
+	if status >= 0 {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var orders []model.Order
+	err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&orders).Error
+	return orders, total, err
+}
+
+/**
+ * GetByShop 查询本店订单，订单不存在或属于其他店铺时返回 ErrOrderNotFound
+ */
+func (r *OrderRepository) GetByShop(orderNo string, shopID uint) (*model.Order, error) {
+	var order model.Order
+	if err := database.DB.Where("order_no = ? AND shop_id = ?", orderNo, shopID).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+/**
+ * MarkShipped 本店已支付的订单标记为已发货
+ *
+ * 返回值：
+ *   bool - 是否更新成功，订单不是已支付状态时返回 false
+ */
+func (r *OrderRepository) MarkShipped(orderNo string, shopID uint) (bool, error) {
+	result := database.DB.Model(&model.Order{}).
+		Where("order_no = ? AND shop_id = ? AND status = ?", orderNo, shopID, 2).
+		Update("status", 3)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
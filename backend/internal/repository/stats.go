@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"time"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm/clause"
+)
+
+/**
+ * ==================== StatsRepository 经营统计数据访问层 ====================
+ *
+ * 负责管理后台统计所需的聚合查询和每日快照读写。
+ *
+ * 提供的方法：
+ * - ComputeDay: 实时汇总某一天的经营数据
+ * - ListDailyStats: 读取日期范围内的每日快照
+ * - UpsertDailyStat: 写入或覆盖某一天的快照
+ * - TopProducts: 时间段内的商品销售排行
+ * - SeckillSummary: 时间段内按商品汇总的秒杀订单
+ */
+
+/**
+ * StatsRepository 经营统计仓储结构体
+ */
+type StatsRepository struct{}
+
+/**
+ * NewStatsRepository 创建经营统计仓库实例
+ */
+func NewStatsRepository() *StatsRepository {
+	return &StatsRepository{}
+}
+
+/**
+ * paidTimeExpr 订单支付时间，历史订单没有支付时间时按下单时间
+ */
+const paidTimeExpr = "COALESCE(paid_at, created_at)"
+
+/**
+ * ComputeDay 实时汇总 [start, end) 内的经营数据
+ *
+ * 合并支付父订单不计入，购物车结算按店铺子订单计数，避免重复统计。
+ *
+ * 参数：
+ *   day string - 统计日期（写入返回值的 StatDate）
+ *   start, end time.Time - 当天的起止时间
+ */
+func (r *StatsRepository) ComputeDay(day string, start, end time.Time) (*model.DailyStat, error) {
+	stat := &model.DailyStat{StatDate: day}
+
+	var paid struct {
+		GMV   float64
+		Count int64
+	}
+	err := database.DB.Model(&model.Order{}).
+		Select("COALESCE(SUM(total_price), 0) AS gmv, COUNT(*) AS count").
+		Where("status IN ? AND order_type <> ?", paidOrderStatuses, model.OrderTypeCombined).
+		Where(paidTimeExpr+" >= ? AND "+paidTimeExpr+" < ?", start, end).
+		Scan(&paid).Error
+	if err != nil {
+		return nil, err
+	}
+	stat.GMV, stat.PaidOrders = paid.GMV, paid.Count
+
+	var created struct {
+		Total     int64
+		Cancelled int64
+	}
+	err = database.DB.Model(&model.Order{}).
+		Select("COUNT(*) AS total, COALESCE(SUM(CASE WHEN status = 5 THEN 1 ELSE 0 END), 0) AS cancelled").
+		Where("order_type <> ? AND created_at >= ? AND created_at < ?", model.OrderTypeCombined, start, end).
+		Scan(&created).Error
+	if err != nil {
+		return nil, err
+	}
+	stat.CreatedOrders, stat.CancelledOrders = created.Total, created.Cancelled
+
+	err = database.DB.Model(&model.User{}).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Count(&stat.NewUsers).Error
+	if err != nil {
+		return nil, err
+	}
+	return stat, nil
+}
+
+/**
+ * ListDailyStats 读取 [startDay, endDay] 内的每日快照
+ *
+ * 参数：
+ *   startDay, endDay string - 日期，格式 2006-01-02
+ */
+func (r *StatsRepository) ListDailyStats(startDay, endDay string) ([]model.DailyStat, error) {
+	var stats []model.DailyStat
+	err := database.DB.Where("stat_date >= ? AND stat_date <= ?", startDay, endDay).
+		Order("stat_date").
+		Find(&stats).Error
+	return stats, err
+}
+
+/**
+ * UpsertDailyStat 写入某一天的快照，已存在时覆盖统计字段
+ */
+func (r *StatsRepository) UpsertDailyStat(stat *model.DailyStat) error {
+	return database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "stat_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"gmv", "paid_orders", "created_orders", "cancelled_orders", "new_users", "updated_at"}),
+	}).Create(stat).Error
+}
+
+/**
+ * ProductSalesStat 商品销售统计
+ */
+type ProductSalesStat struct {
+	ProductID   uint    `json:"product_id"`
+	ProductName string  `json:"product_name"` // 订单中的商品名称快照，商品改名后取其中之一
+	Quantity    int64   `json:"quantity"`
+	Orders      int64   `json:"orders"`
+	GMV         float64 `json:"gmv"`
+}
+
+/**
+ * TopProducts 时间段内支付订单的商品销售排行
+ *
+ * 结算子订单按商品明细统计，销售额为分摊优惠后的实付金额。
+ *
+ * 参数：
+ *   start, end time.Time - 支付时间范围 [start, end)
+ *   orderBy string - 排序字段：gmv 或 quantity
+ *   limit int - 返回条数
+ */
+func (r *StatsRepository) TopProducts(start, end time.Time, orderBy string, limit int) ([]ProductSalesStat, error) {
+	order := "gmv DESC"
+	if orderBy == "quantity" {
+		order = "quantity DESC"
+	}
+
+	var stats []ProductSalesStat
+	err := database.DB.Table("(?) AS o", orderLines()).
+		Select("product_id, MAX(product_name) AS product_name, SUM(quantity) AS quantity, COUNT(*) AS orders, SUM(total_price) AS gmv").
+		Where("status IN ?", paidOrderStatuses).
+		Where(paidTimeExpr+" >= ? AND "+paidTimeExpr+" < ?", start, end).
+		Group("product_id").
+		Order(order).
+		Limit(limit).
+		Scan(&stats).Error
+	return stats, err
+}
+
+/**
+ * SeckillStat 单个秒杀商品的订单统计
+ */
+type SeckillStat struct {
+	ProductID       uint    `json:"product_id"`
+	ProductName     string  `json:"product_name"`
+	Orders          int64   `json:"orders"`           // 秒杀成功生成的订单数
+	PaidOrders      int64   `json:"paid_orders"`      // 已支付订单数
+	CancelledOrders int64   `json:"cancelled_orders"` // 已取消订单数
+	GMV             float64 `json:"gmv"`              // 已支付订单金额
+	FirstOrderAt    string  `json:"first_order_at"`
+	LastOrderAt     string  `json:"last_order_at"`
+}
+
+/**
+ * SeckillSummary 时间段内创建的秒杀订单，按商品汇总
+ *
+ * 参数：
+ *   start, end time.Time - 下单时间范围 [start, end)
+ */
+func (r *StatsRepository) SeckillSummary(start, end time.Time) ([]SeckillStat, error) {
+	var rows []struct {
+		ProductID       uint
+		ProductName     string
+		Orders          int64
+		PaidOrders      int64
+		CancelledOrders int64
+		GMV             float64
+		FirstOrderAt    time.Time
+		LastOrderAt     time.Time
+	}
+	err := database.DB.Model(&model.Order{}).
+		Select("product_id, MAX(product_name) AS product_name, COUNT(*) AS orders, "+
+			"COALESCE(SUM(CASE WHEN status IN ? THEN 1 ELSE 0 END), 0) AS paid_orders, "+
+			"COALESCE(SUM(CASE WHEN status = 5 THEN 1 ELSE 0 END), 0) AS cancelled_orders, "+
+			"COALESCE(SUM(CASE WHEN status IN ? THEN total_price ELSE 0 END), 0) AS gmv, "+
+			"MIN(created_at) AS first_order_at, MAX(created_at) AS last_order_at",
+			paidOrderStatuses, paidOrderStatuses).
+		Where("order_type = ? AND created_at >= ? AND created_at < ?", model.OrderTypeSeckill, start, end).
+		Group("product_id").
+		Order("orders DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]SeckillStat, len(rows))
+	for i, row := range rows {
+		stats[i] = SeckillStat{
+			ProductID:       row.ProductID,
+			ProductName:     row.ProductName,
+			Orders:          row.Orders,
+			PaidOrders:      row.PaidOrders,
+			CancelledOrders: row.CancelledOrders,
+			GMV:             row.GMV,
+			FirstOrderAt:    row.FirstOrderAt.Format("2006-01-02 15:04:05"),
+			LastOrderAt:     row.LastOrderAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+	return stats, nil
+}
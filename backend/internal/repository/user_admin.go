@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"strings"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * ==================== 后台用户管理 ====================
+ *
+ * 账号状态的变更都带有状态条件，并与审计日志在同一事务中写入，
+ * 状态没有变化时不写日志。
+ *
+ * 提供的方法：
+ * - UserRepository.Search: 按关键字、状态、角色分页查询用户
+ * - UserRepository.ChangeStatus: 变更账号状态并写入审计日志
+ * - UserRepository.ActivateLocked: 重置密码后恢复被锁定的账号
+ * - AuditLogRepository.Create: 写入审计日志
+ * - AuditLogRepository.List: 分页查询审计日志
+ */
+
+// UserQuery 后台用户查询条件，零值表示不过滤
+type UserQuery struct {
+	Keyword string // 匹配用户名、昵称、邮箱、手机号
+	Status  int
+	Role    int
+}
+
+/**
+ * Search 分页查询用户，按注册时间倒序
+ */
+func (r *UserRepository) Search(q UserQuery, page, pageSize int) ([]model.User, int64, error) {
+	query := database.DB.Model(&model.User{})
+	if q.Keyword != "" {
+		like := "%" + escapeLike(q.Keyword) + "%"
+		query = query.Where("username LIKE ? OR nickname LIKE ? OR email LIKE ? OR phone LIKE ?", like, like, like, like)
+	}
+	if q.Status > 0 {
+		query = query.Where("status = ?", q.Status)
+	}
+	if q.Role > 0 {
+		query = query.Where("role = ?", q.Role)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []model.User
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&users).Error
+	return users, total, err
+}
+
+/**
+ * ChangeStatus 变更账号状态并写入审计日志（带事务）
+ *
+ * 参数：
+ *   userID uint - 用户ID
+ *   from []int - 允许变更的当前状态
+ *   to int - 目标状态
+ *   log *model.AdminAuditLog - 审计日志，状态变更成功时写入
+ *
+ * 返回值：
+ *   bool - 是否变更成功，当前状态不在 from 中时返回 false
+ */
+func (r *UserRepository) ChangeStatus(userID uint, from []int, to int, log *model.AdminAuditLog) (bool, error) {
+	changed := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).
+			Where("id = ? AND status IN ?", userID, from).
+			Update("status", to)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		changed = true
+		return tx.Create(log).Error
+	})
+	return changed, err
+}
+
+/**
+ * ActivateLocked 账号处于锁定状态时恢复正常
+ *
+ * 返回值：
+ *   bool - 是否恢复，账号未被锁定时返回 false
+ */
+func (r *UserRepository) ActivateLocked(userID uint) (bool, error) {
+	result := database.DB.Model(&model.User{}).
+		Where("id = ? AND status = ?", userID, model.UserStatusLocked).
+		Update("status", model.UserStatusActive)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+/**
+ * AuditLogRepository 后台操作审计日志数据访问
+ */
+type AuditLogRepository struct{}
+
+/**
+ * NewAuditLogRepository 创建审计日志Repository实例
+ */
+func NewAuditLogRepository() *AuditLogRepository {
+	return &AuditLogRepository{}
+}
+
+// AuditLogQuery 审计日志查询条件，零值表示不过滤
+type AuditLogQuery struct {
+	OperatorID uint
+	TargetType string
+	TargetID   uint
+	Action     string
+}
+
+/**
+ * Create 写入审计日志
+ */
+func (r *AuditLogRepository) Create(log *model.AdminAuditLog) error {
+	return database.DB.Create(log).Error
+}
+
+/**
+ * List 分页查询审计日志，按时间倒序
+ */
+func (r *AuditLogRepository) List(q AuditLogQuery, page, pageSize int) ([]model.AdminAuditLog, int64, error) {
+	query := database.DB.Model(&model.AdminAuditLog{})
+	if q.OperatorID > 0 {
+		query = query.Where("operator_id = ?", q.OperatorID)
+	}
+	if q.TargetType != "" {
+		query = query.Where("target_type = ?", q.TargetType)
+	}
+	if q.TargetID > 0 {
+		query = query.Where("target_id = ?", q.TargetID)
+	}
+	if q.Action != "" {
+		query = query.Where("action = ?", q.Action)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var logs []model.AdminAuditLog
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&logs).Error
+	return logs, total, err
+}
+
+// likeEscaper 转义 LIKE 通配符，MySQL 默认以反斜杠作为转义字符
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+/**
+ * escapeLike 转义关键字中的 % _ \，使其按字面匹配
+ */
+func escapeLike(keyword string) string {
+	return likeEscaper.Replace(keyword)
+}
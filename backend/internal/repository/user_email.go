@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"time"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+)
+
+/**
+ * ==================== 邮箱验证与重置密码 ====================
+ *
+ * 邮件中的链接只能使用一次，这里的更新都带有状态条件，
+ * 并发提交同一个链接时只有一个请求会更新成功。
+ *
+ * 提供的方法：
+ * - UserRepository.MarkEmailVerified: 标记邮箱已验证
+ * - UserRepository.ReplacePassword: 密码未被修改过时替换为新密码
+ */
+
+/**
+ * MarkEmailVerified 标记邮箱已验证
+ *
+ * 只有邮箱仍为 email 且尚未验证时才会更新。
+ *
+ * 返回值：
+ *   bool - 是否更新成功，邮箱已变更或已验证时返回 false
+ */
+func (r *UserRepository) MarkEmailVerified(userID uint, email string) (bool, error) {
+	result := database.DB.Model(&model.User{}).
+		Where("id = ? AND email = ? AND email_verified_at IS NULL", userID, email).
+		Update("email_verified_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+/**
+ * ReplacePassword 密码未被修改过时替换为新密码
+ *
+ * 参数：
+ *   oldHash string - 读取用户时的密码哈希，与数据库不一致说明密码已被修改
+ *   newHash string - 新密码哈希
+ *
+ * 返回值：
+ *   bool - 是否更新成功
+ */
+func (r *UserRepository) ReplacePassword(userID uint, oldHash, newHash string) (bool, error) {
+	result := database.DB.Model(&model.User{}).
+		Where("id = ? AND password = ?", userID, oldHash).
+		Update("password", newHash)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/**
+ * ==================== 手机号绑定 ====================
+ *
+ * 注册时填写的手机号未经验证，只有通过短信验证码绑定（phone_verified_at 非空）的手机号
+ * 才能用于验证码登录，同一手机号只能被一个账号绑定。
+ *
+ * 提供的方法：
+ * - UserRepository.GetByVerifiedPhone: 根据已验证的手机号获取用户
+ * - UserRepository.BindPhone: 绑定手机号并标记已验证
+ */
+
+// ErrPhoneTaken 手机号已被其他账号绑定
+var ErrPhoneTaken = errors.New("手机号已被其他账号绑定")
+
+/**
+ * GetByVerifiedPhone 根据已验证的手机号获取用户
+ *
+ * 返回值：
+ *   error - 未找到返回 ErrUserNotFound
+ */
+func (r *UserRepository) GetByVerifiedPhone(phone string) (*model.User, error) {
+	var user model.User
+	err := database.DB.Where("phone = ? AND phone_verified_at IS NOT NULL", phone).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+/**
+ * BindPhone 绑定手机号并标记已验证（带事务）
+ *
+ * 事务内对同一手机号的记录加锁后再检查是否已被其他账号绑定，两个账号并发绑定时只有一个成功。
+ * 其他账号上同一手机号的未验证记录会被清空，避免注册时随意填写的手机号占用。
+ *
+ * 返回值：
+ *   error - 已被其他账号绑定返回 ErrPhoneTaken
+ */
+func (r *UserRepository) BindPhone(userID uint, phone string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var owners []model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("phone = ? AND phone_verified_at IS NOT NULL", phone).
+			Find(&owners).Error; err != nil {
+			return err
+		}
+		for _, owner := range owners {
+			if owner.ID != userID {
+				return ErrPhoneTaken
+			}
+		}
+
+		if err := tx.Model(&model.User{}).
+			Where("phone = ? AND id <> ? AND phone_verified_at IS NULL", phone, userID).
+			Update("phone", "").Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.User{}).Where("id = ?", userID).
+			Updates(map[string]interface{}{"phone": phone, "phone_verified_at": time.Now()}).Error
+	})
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"fmt"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/**
+ * ==================== 个人资料与注销账号 ====================
+ *
+ * 提供的方法：
+ * - UserRepository.UpdateProfile: 更新昵称、头像
+ * - UserRepository.ChangeEmail: 修改邮箱并清除验证状态
+ * - UserRepository.DeleteAccount: 注销账号（单个事务）
+ * - OrderRepository.CountUnfinishedByUser: 统计用户未完成的订单
+ * - OrderRepository.CountUnfinishedByShop: 统计店铺未完成的订单
+ */
+
+// DeletedUsernamePrefix 注销账号后用户名改写使用的前缀，注册时不允许使用
+const DeletedUsernamePrefix = "deleted_"
+
+// DeletedEmailDomain 注销账号后邮箱改写使用的域名（.invalid 为保留域名，不会投递）
+const DeletedEmailDomain = "deleted.gomall.invalid"
+
+// ShopClosedByOwnerReason 店主注销账号时店铺的停业原因
+const ShopClosedByOwnerReason = "店主已注销账号"
+
+/**
+ * UpdateProfile 更新个人资料
+ *
+ * 参数：
+ *   userID uint - 用户ID
+ *   updates map[string]interface{} - 要更新的字段，只允许 nickname、avatar
+ */
+func (r *UserRepository) UpdateProfile(userID uint, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	return database.DB.Model(&model.User{}).Where("id = ?", userID).
+		Select("nickname", "avatar").Updates(updates).Error
+}
+
+/**
+ * ChangeEmail 修改邮箱，新邮箱需要重新验证
+ */
+func (r *UserRepository) ChangeEmail(userID uint, email string) error {
+	return database.DB.Model(&model.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"email": email, "email_verified_at": nil}).Error
+}
+
+/**
+ * DeleteAccount 注销账号（带事务）
+ *
+ * 操作流程：
+ * 1. 删除购物车、收藏、两步验证和登录会话记录，被收藏商品的收藏数同步减一
+ * 2. 用户开过店铺时将店铺置为已停业，商品不再展示和销售
+ * 3. 清除用户名、邮箱、手机号、昵称、头像和密码，用户名和邮箱改写为 deleted_<ID>，原值可以被重新注册
+ * 4. 软删除用户记录
+ *
+ * 订单只保存用户ID和商品快照，不含个人信息，保留用于对账和售后。
+ *
+ * 返回值：
+ *   []uint - 收藏数发生变化的商品ID，调用方据此清除商品缓存
+ *   error - 失败时返回错误
+ */
+func (r *UserRepository) DeleteAccount(userID uint) ([]uint, error) {
+	var favoriteProductIDs []uint
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 锁定收藏记录，与并发的取消收藏互斥，避免收藏数重复扣减
+		favoriteProductIDs = nil
+		if err := tx.Model(&model.Favorite{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).Pluck("product_id", &favoriteProductIDs).Error; err != nil {
+			return err
+		}
+		// (user_id, product_id) 唯一，每个商品只减一；商品可能已被软删除，使用 Unscoped
+		if len(favoriteProductIDs) > 0 {
+			if err := tx.Unscoped().Model(&model.Product{}).
+				Where("id IN ?", favoriteProductIDs).
+				UpdateColumn("favorite_count", gorm.Expr("GREATEST(favorite_count - 1, 0)")).Error; err != nil {
+				return err
+			}
+		}
+
+		personal := []interface{}{
+			&model.Cart{},
+			&model.Favorite{},
+			&model.UserBackupCode{},
+			&model.UserTOTP{},
+			&model.UserSession{},
+		}
+		for _, table := range personal {
+			if err := tx.Unscoped().Where("user_id = ?", userID).Delete(table).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&model.Shop{}).
+			Where("owner_id = ? AND status <> ?", userID, model.ShopStatusSuspended).
+			Updates(map[string]interface{}{
+				"status":        model.ShopStatusSuspended,
+				"review_reason": ShopClosedByOwnerReason,
+			}).Error; err != nil {
+			return err
+		}
+
+		placeholder := fmt.Sprintf("%s%d", DeletedUsernamePrefix, userID)
+		result := tx.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"username":          placeholder,
+			"email":             placeholder + "@" + DeletedEmailDomain,
+			"phone":             "",
+			"phone_verified_at": nil,
+			"email_verified_at": nil,
+			"nickname":          "",
+			"avatar":            "",
+			"password":          "",
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+		return tx.Delete(&model.User{}, userID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return favoriteProductIDs, nil
+}
+
+/**
+ * CountUnfinishedByUser 统计用户未完成的订单（处理中、待支付、已支付、已发货）
+ */
+func (r *OrderRepository) CountUnfinishedByUser(userID uint) (int64, error) {
+	var count int64
+	err := database.DB.Model(&model.Order{}).
+		Where("user_id = ? AND status IN ?", userID, []int{0, 1, 2, 3}).
+		Count(&count).Error
+	return count, err
+}
+
+/**
+ * CountUnfinishedByShop 统计店铺未完成的订单（处理中、待支付、已支付、已发货）
+ */
+func (r *OrderRepository) CountUnfinishedByShop(shopID uint) (int64, error) {
+	var count int64
+	err := database.DB.Model(&model.Order{}).
+		Where("shop_id = ? AND status IN ?", shopID, []int{0, 1, 2, 3}).
+		Count(&count).Error
+	return count, err
+}
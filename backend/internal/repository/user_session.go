@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * ==================== SessionRepository 登录会话数据访问层 ====================
+ *
+ * 负责登录会话（设备）记录的读写。会话以刷新Token轮换家族ID（family）关联 Token。
+ *
+ * 提供的方法：
+ * - Create: 创建会话
+ * - ListActive: 获取用户未吊销、未过期的会话
+ * - GetByID: 根据ID获取用户自己的会话
+ * - Revoke: 吊销单个会话
+ * - RevokeAll: 吊销用户的全部会话
+ * - Touch: 更新最近活跃时间（节流）
+ * - Extend: 刷新Token后顺延过期时间
+ */
+
+/**
+ * ErrSessionNotFound 会话不存在错误
+ */
+var ErrSessionNotFound = errors.New("会话不存在")
+
+/**
+ * SessionRepository 登录会话仓储结构体
+ */
+type SessionRepository struct{}
+
+/**
+ * NewSessionRepository 创建登录会话仓库实例
+ */
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{}
+}
+
+/**
+ * Create 创建会话
+ */
+func (r *SessionRepository) Create(session *model.UserSession) error {
+	return database.DB.Create(session).Error
+}
+
+/**
+ * ListActive 获取用户未吊销、未过期的会话，按最近活跃时间倒序
+ */
+func (r *SessionRepository) ListActive(userID uint) ([]model.UserSession, error) {
+	var sessions []model.UserSession
+	err := database.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+/**
+ * GetByID 根据ID获取会话，只能获取属于 userID 的会话
+ *
+ * 返回值：
+ *   error - 未找到或不属于该用户返回 ErrSessionNotFound
+ */
+func (r *SessionRepository) GetByID(userID, id uint) (*model.UserSession, error) {
+	var session model.UserSession
+	if err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+/**
+ * Revoke 吊销会话，返回 false 表示会话不存在或已吊销
+ */
+func (r *SessionRepository) Revoke(family string) (bool, error) {
+	result := database.DB.Model(&model.UserSession{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+/**
+ * RevokeAll 吊销用户的全部会话（退出所有设备、修改密码）
+ */
+func (r *SessionRepository) RevokeAll(userID uint) error {
+	return database.DB.Model(&model.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+/**
+ * Touch 更新最近活跃时间
+ *
+ * 只更新 interval 之前活跃过的会话，避免每个请求都写数据库。
+ */
+func (r *SessionRepository) Touch(family string, interval time.Duration) error {
+	now := time.Now()
+	return database.DB.Model(&model.UserSession{}).
+		Where("family = ? AND revoked_at IS NULL AND last_seen_at < ?", family, now.Add(-interval)).
+		Update("last_seen_at", now).Error
+}
+
+/**
+ * Extend 刷新Token后更新活跃时间并顺延过期时间
+ */
+func (r *SessionRepository) Extend(family string, expiresAt time.Time) error {
+	return database.DB.Model(&model.UserSession{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Updates(map[string]interface{}{"last_seen_at": time.Now(), "expires_at": expiresAt}).Error
+}
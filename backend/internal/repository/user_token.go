@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * ==================== 用户 Token 版本 ====================
+ *
+ * 用户的 Token 版本写入 JWT，版本递增后该用户已签发的 Token 全部失效。
+ *
+ * 提供的方法：
+ * - UserRepository.GetTokenVersion: 获取用户当前的 Token 版本
+ * - UserRepository.IncrTokenVersion: 递增 Token 版本并返回新版本
+ */
+
+/**
+ * GetTokenVersion 获取用户当前的 Token 版本
+ *
+ * 返回值：
+ *   error - 用户不存在返回 ErrUserNotFound
+ */
+func (r *UserRepository) GetTokenVersion(userID uint) (int64, error) {
+	var versions []int64
+	err := database.DB.Model(&model.User{}).Where("id = ?", userID).Pluck("token_version", &versions).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(versions) == 0 {
+		return 0, ErrUserNotFound
+	}
+	return versions[0], nil
+}
+
+/**
+ * IncrTokenVersion 递增用户的 Token 版本（带事务）
+ *
+ * 返回值：
+ *   int64 - 递增后的版本
+ *   error - 用户不存在返回 ErrUserNotFound
+ */
+func (r *UserRepository) IncrTokenVersion(userID uint) (int64, error) {
+	var version int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).Where("id = ?", userID).
+			Update("token_version", gorm.Expr("token_version + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+		// 同一事务内读取，行锁保证读到的是本次递增后的值
+		return tx.Model(&model.User{}).Select("token_version").Where("id = ?", userID).Scan(&version).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
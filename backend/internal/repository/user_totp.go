@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gomall/backend/internal/database"
+	"gomall/backend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+/**
+ * ==================== TwoFactorRepository 两步验证数据访问层 ====================
+ *
+ * 负责 TOTP 密钥和备用码的读写。已使用的时间步和备用码都通过条件更新消费，
+ * 并发提交同一个验证码时只有一个请求能成功。
+ *
+ * 提供的方法：
+ * - GetTOTP: 获取用户的 TOTP 记录
+ * - SavePendingTOTP: 保存待绑定的密钥（替换之前未完成的绑定）
+ * - EnableTOTP: 启用 TOTP 并写入备用码（单个事务）
+ * - ConsumeTOTPStep: 消费一个时间步，拒绝重放
+ * - ReplaceBackupCodes: 整体替换备用码
+ * - ConsumeBackupCode: 消费一个未使用的备用码
+ * - CountBackupCodes: 统计未使用的备用码数量
+ * - DeleteTOTP: 删除 TOTP 记录和备用码（单个事务）
+ */
+
+/**
+ * ErrTOTPNotFound 用户未绑定两步验证
+ */
+var ErrTOTPNotFound = errors.New("未设置两步验证")
+
+/**
+ * TwoFactorRepository 两步验证仓储结构体
+ */
+type TwoFactorRepository struct{}
+
+/**
+ * NewTwoFactorRepository 创建两步验证仓库实例
+ */
+func NewTwoFactorRepository() *TwoFactorRepository {
+	return &TwoFactorRepository{}
+}
+
+/**
+ * GetTOTP 获取用户的 TOTP 记录（包括尚未启用的）
+ *
+ * 返回值：
+ *   error - 未找到返回 ErrTOTPNotFound
+ */
+func (r *TwoFactorRepository) GetTOTP(userID uint) (*model.UserTOTP, error) {
+	var record model.UserTOTP
+	if err := database.DB.Where("user_id = ?", userID).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTOTPNotFound
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+/**
+ * SavePendingTOTP 保存待绑定的密钥
+ *
+ * 只替换未启用的记录；已启用时不做修改，返回 false。
+ */
+func (r *TwoFactorRepository) SavePendingTOTP(userID uint, secret string) (bool, error) {
+	saved := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND enabled_at IS NULL", userID).Delete(&model.UserTOTP{}).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&model.UserTOTP{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		saved = true
+		return tx.Create(&model.UserTOTP{UserID: userID, Secret: secret}).Error
+	})
+	return saved, err
+}
+
+/**
+ * EnableTOTP 启用 TOTP 并写入备用码（带事务）
+ *
+ * 参数：
+ *   userID uint - 用户ID
+ *   step int64 - 绑定时提交的验证码所在时间步，记为已使用
+ *   codeHashes []string - 备用码哈希
+ *
+ * 返回值：
+ *   bool - 记录不存在或已启用时返回 false
+ */
+func (r *TwoFactorRepository) EnableTOTP(userID uint, step int64, codeHashes []string) (bool, error) {
+	enabled := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.UserTOTP{}).
+			Where("user_id = ? AND enabled_at IS NULL", userID).
+			Updates(map[string]interface{}{"enabled_at": time.Now(), "last_used_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		enabled = true
+		return replaceBackupCodes(tx, userID, codeHashes)
+	})
+	return enabled, err
+}
+
+/**
+ * ConsumeTOTPStep 消费一个时间步
+ *
+ * 只有 step 大于上次使用的时间步时才会更新，返回 false 表示验证码已被使用过。
+ */
+func (r *TwoFactorRepository) ConsumeTOTPStep(userID uint, step int64) (bool, error) {
+	result := database.DB.Model(&model.UserTOTP{}).
+		Where("user_id = ? AND enabled_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+/**
+ * ReplaceBackupCodes 删除旧的备用码并写入新的备用码
+ */
+func (r *TwoFactorRepository) ReplaceBackupCodes(userID uint, codeHashes []string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		return replaceBackupCodes(tx, userID, codeHashes)
+	})
+}
+
+func replaceBackupCodes(tx *gorm.DB, userID uint, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.UserBackupCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]model.UserBackupCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, model.UserBackupCode{UserID: userID, CodeHash: hash})
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
+
+/**
+ * ConsumeBackupCode 消费一个未使用的备用码，返回 false 表示备用码不存在或已使用
+ */
+func (r *TwoFactorRepository) ConsumeBackupCode(userID uint, codeHash string) (bool, error) {
+	result := database.DB.Model(&model.UserBackupCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+/**
+ * CountBackupCodes 统计未使用的备用码数量
+ */
+func (r *TwoFactorRepository) CountBackupCodes(userID uint) (int64, error) {
+	var count int64
+	err := database.DB.Model(&model.UserBackupCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+/**
+ * DeleteTOTP 删除 TOTP 记录和全部备用码（带事务）
+ */
+func (r *TwoFactorRepository) DeleteTOTP(userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserBackupCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.UserTOTP{}).Error
+	})
+}
@@ -31,6 +31,25 @@ const (
 	CodeUserLoginRequired = 10008 // 需要登录
 	CodeUserParamError    = 10009 // 用户参数错误
 	CodeUserNotAdmin      = 10010 // 非管理员用户
+
+	// 账号安全相关 10011-10020
+	CodeUserAccountLocked    = 10011 // 账号因连续登录失败被锁定
+	CodeUserEmailNotVerified = 10012 // 邮箱未验证
+	CodeUserLinkInvalid      = 10013 // 邮件链接无效、已使用或已过期
+	CodeUserMailFailed       = 10014 // 邮件发送失败
+	CodeUserSmsCodeInvalid   = 10015 // 短信验证码错误或已过期
+	CodeUserPhoneBound       = 10016 // 手机号已被其他账号绑定
+	CodeUserTwoFactorRequired = 10017 // 需要通过两步验证（管理后台强制两步验证）
+	CodeUserTwoFactorInvalid  = 10018 // 两步验证码错误或预认证Token已失效
+	CodeUserAccountBusy       = 10019 // 存在未完成的订单，不能注销账号
+
+	// 用户收藏相关 10021-10030
+	CodeFavoriteFailed = 10021 // 收藏操作失败
+	CodeHistoryFailed  = 10022 // 浏览记录操作失败
+
+	// 角色权限相关 10031-10040
+	CodeRoleNotFound = 10031 // 角色不存在
+	CodeRoleFailed   = 10032 // 角色权限操作失败
 )
 
 // ============================================
@@ -48,6 +67,14 @@ const (
 	CodeProductUpdateFailed = 20008 // 商品更新失败
 	CodeProductDeleteFailed = 20009 // 商品删除失败
 	CodeProductStatusError = 20010 // 商品状态错误
+
+	// 商品图集相关 20011-20020
+	CodeProductImageNotFound = 20011 // 商品图片不存在
+	CodeProductImageFailed   = 20012 // 商品图片操作失败
+
+	// 商品导入导出 20021-20030
+	CodeProductImportFailed = 20021 // 商品导入失败
+	CodeProductExportFailed = 20022 // 商品导出失败
 )
 
 // ============================================
@@ -135,6 +162,18 @@ const (
 	CodeUploadNotAllowed   = 70010 // 无上传权限
 )
 
+// ============================================
+// 店铺模块错误码 (80001-80099)
+// ============================================
+const (
+	// 店铺相关 80001-80010
+	CodeShopNotFound    = 80001 // 店铺不存在或未开店
+	CodeShopNotOpen     = 80002 // 店铺未通过审核或已停业
+	CodeShopExists      = 80003 // 已提交过开店申请
+	CodeShopNameTaken   = 80004 // 店铺名称已被使用
+	CodeShopStatusError = 80005 // 店铺当前状态不允许该操作
+)
+
 // ============================================
 // 错误码映射（错误码 -> 错误消息）
 // ============================================
@@ -162,6 +201,19 @@ var codeMsgMap = map[int]string{
 	CodeUserLoginRequired: "需要登录",
 	CodeUserParamError:    "用户参数错误",
 	CodeUserNotAdmin:      "非管理员用户",
+	CodeUserAccountLocked: "账号已锁定",
+	CodeUserEmailNotVerified: "邮箱未验证",
+	CodeUserLinkInvalid:   "链接无效或已过期",
+	CodeUserMailFailed:    "邮件发送失败",
+	CodeUserSmsCodeInvalid: "验证码错误或已过期",
+	CodeUserPhoneBound:    "手机号已被其他账号绑定",
+	CodeUserTwoFactorRequired: "需要两步验证",
+	CodeUserTwoFactorInvalid:  "两步验证码错误",
+	CodeUserAccountBusy:       "存在未完成的订单",
+	CodeFavoriteFailed:    "收藏操作失败",
+	CodeHistoryFailed:     "浏览记录操作失败",
+	CodeRoleNotFound:      "角色不存在",
+	CodeRoleFailed:        "角色权限操作失败",
 
 	// 商品
 	CodeProductNotFound:     "商品不存在",
@@ -174,6 +226,10 @@ var codeMsgMap = map[int]string{
 	CodeProductUpdateFailed: "商品更新失败",
 	CodeProductDeleteFailed: "商品删除失败",
 	CodeProductStatusError: "商品状态错误",
+	CodeProductImageNotFound: "商品图片不存在",
+	CodeProductImageFailed:   "商品图片操作失败",
+	CodeProductImportFailed:  "商品导入失败",
+	CodeProductExportFailed:  "商品导出失败",
 
 	// 订单
 	CodeOrderNotFound:       "订单不存在",
@@ -234,6 +290,13 @@ var codeMsgMap = map[int]string{
 	CodeUploadParamError:   "上传参数错误",
 	CodeUploadServerError:  "文件服务器错误",
 	CodeUploadNotAllowed:   "无上传权限",
+
+	// 店铺
+	CodeShopNotFound:    "店铺不存在",
+	CodeShopNotOpen:     "店铺未营业",
+	CodeShopExists:      "已提交过开店申请",
+	CodeShopNameTaken:   "店铺名称已被使用",
+	CodeShopStatusError: "店铺状态错误",
 }
 
 // GetCodeMsg 根据错误码获取错误消息
@@ -3,6 +3,7 @@ package router
 import (
 	"gomall/backend/internal/api"
 	"gomall/backend/internal/middleware"
+	"gomall/backend/internal/model"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -29,6 +30,16 @@ func Setup(r *gin.Engine) {
 	authHandler := api.NewAuthHandler()
 	fileHandler := api.NewFileHandler()
 	wechatPayHandler := api.NewWeChatPayHandler()
+	favoriteHandler := api.NewFavoriteHandler()
+	historyHandler := api.NewHistoryHandler()
+	statsHandler := api.NewStatsHandler()
+	roleHandler := api.NewRoleHandler()
+	userAdminHandler := api.NewUserAdminHandler()
+	smsHandler := api.NewSmsHandler()
+	twoFactorHandler := api.NewTwoFactorHandler()
+	sessionHandler := api.NewSessionHandler()
+	profileHandler := api.NewProfileHandler()
+	shopHandler := api.NewShopHandler()
 	healthCheck := api.NewHealthCheck()
 
 	// 全局中间件顺序：
@@ -51,6 +62,9 @@ func Setup(r *gin.Engine) {
 	// Prometheus 指标端点
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	// JWT 公钥集合（供其他服务验签）
+	r.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	// Swagger API 文档
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.NewHandler(), ginSwagger.URL("/swagger/doc.json")))
 
@@ -63,6 +77,19 @@ func Setup(r *gin.Engine) {
 		})
 	})
 
+	// 后台权限校验
+	requireProductRead := middleware.RequirePermission(model.PermProductRead)
+	requireProductWrite := middleware.RequirePermission(model.PermProductWrite)
+	requireSeckillManage := middleware.RequirePermission(model.PermSeckillManage)
+	requireStatsRead := middleware.RequirePermission(model.PermStatsRead)
+	requireSystemMaintain := middleware.RequirePermission(model.PermSystemMaintain)
+	requireRoleManage := middleware.RequirePermission(model.PermRoleManage)
+	requireUserManage := middleware.RequirePermission(model.PermUserManage)
+	requireShopManage := middleware.RequirePermission(model.PermShopManage)
+
+	// 下单、秒杀、支付需要邮箱已验证
+	requireVerifiedEmail := middleware.RequireVerifiedEmail()
+
 	// 全局限流和熔断器保护（用于 API 组）
 	apiGroup := r.Group("/api")
 	apiGroup.Use(middleware.MetricsMiddleware())
@@ -71,47 +98,123 @@ func Setup(r *gin.Engine) {
 		userGroup := apiGroup.Group("/user")
 		{
 			userGroup.POST("/register", userHandler.Register)
-			// 登录接口暂不限流
-			userGroup.POST("/login", userHandler.Login)
+			// 登录接口按IP限流，失败次数过多时另由登录服务锁定账号和IP
+			userGroup.POST("/login", middleware.LoginRateLimit(), userHandler.Login)
+			userGroup.POST("/sms/code", middleware.LoginRateLimit(), smsHandler.SendLoginCode)      // 发送登录验证码
+			userGroup.POST("/sms/login", middleware.LoginRateLimit(), smsHandler.LoginByCode)       // 验证码登录（未注册自动注册）
+			userGroup.POST("/login/2fa", middleware.LoginRateLimit(), twoFactorHandler.VerifyLogin) // 两步验证登录
 		}
 
 		// 商品模块（部分需要登录）
 		productGroup := apiGroup.Group("/product")
 		{
-			productGroup.GET("", productHandler.List)    // 获取商品列表（无需登录）
-			productGroup.GET("/:id", productHandler.Get) // 获取商品详情（无需登录）
+			productGroup.GET("", productHandler.List)                                         // 获取商品列表（无需登录）
+			productGroup.GET("/rank", productHandler.Rank)                                    // 商品排行榜（无需登录）
+			productGroup.GET("/:id", middleware.OptionalAuthMiddleware(), productHandler.Get) // 获取商品详情（无需登录，登录后返回收藏状态）
+			productGroup.GET("/:id/recommendations", productHandler.Recommendations)          // 经常一起购买（无需登录）
 
-			// 以下接口需要管理员权限
+			// 以下接口需要后台权限
 			productGroup.Use(middleware.AdminAuthMiddleware())
-			productGroup.POST("", productHandler.Create)       // 创建商品
-			productGroup.PUT("/:id", productHandler.Update)    // 更新商品
-			productGroup.DELETE("/:id", productHandler.Delete) // 删除商品
+			productGroup.POST("", requireProductWrite, productHandler.Create)       // 创建商品
+			productGroup.PUT("/:id", requireProductWrite, productHandler.Update)    // 更新商品
+			productGroup.DELETE("/:id", requireProductWrite, productHandler.Delete) // 删除商品
+
+			// 商品图集管理
+			productGroup.GET("/:id/images", requireProductRead, productHandler.ListImages)                   // 获取图集
+			productGroup.POST("/:id/images", requireProductWrite, productHandler.AddImages)                  // 添加图片
+			productGroup.PUT("/:id/images/sort", requireProductWrite, productHandler.SortImages)             // 调整顺序
+			productGroup.PUT("/:id/images/:image_id/main", requireProductWrite, productHandler.SetMainImage) // 设置主图
+			productGroup.DELETE("/:id/images/:image_id", requireProductWrite, productHandler.RemoveImage)    // 删除图片
 		}
 
 		// 订单模块（需要登录）
 		orderGroup := apiGroup.Group("/order")
 		orderGroup.Use(middleware.AuthMiddleware())
 		{
-			orderGroup.POST("/checkout", orderHandler.Checkout)       // 购物车结算
-			orderGroup.POST("", orderHandler.Create)                  // 创建订单
-			orderGroup.GET("", orderHandler.List)                     // 获取订单列表
-			orderGroup.GET("/:order_no", orderHandler.Get)            // 获取订单详情
-			orderGroup.POST("/:order_no/pay", orderHandler.Pay)       // 支付订单
-			orderGroup.POST("/:order_no/cancel", orderHandler.Cancel) // 取消订单
+			orderGroup.POST("/checkout", requireVerifiedEmail, orderHandler.Checkout) // 购物车结算
+			orderGroup.POST("", requireVerifiedEmail, orderHandler.Create)            // 创建订单
+			orderGroup.GET("", orderHandler.List)                                     // 获取订单列表
+			orderGroup.GET("/:order_no", orderHandler.Get)                            // 获取订单详情
+			orderGroup.POST("/:order_no/pay", requireVerifiedEmail, orderHandler.Pay) // 支付订单
+			orderGroup.POST("/:order_no/cancel", orderHandler.Cancel)                 // 取消订单
 		}
 
 		// --- 新增：秒杀模块 ---
 		seckillGroup := apiGroup.Group("/seckill")
 		seckillGroup.Use(middleware.AuthMiddleware(), middleware.SeckillRateLimit())
 		{
-			seckillGroup.POST("", seckillHandler.Seckill) // 秒杀接口: POST /api/seckill
+			seckillGroup.POST("", requireVerifiedEmail, seckillHandler.Seckill) // 秒杀接口: POST /api/seckill
 		}
 
-		// 秒杀管理（需要管理员权限）
+		// 秒杀管理（需要 seckill:manage 权限）
 		seckillAdminGroup := apiGroup.Group("/seckill")
-		seckillAdminGroup.Use(middleware.AdminAuthMiddleware())
+		seckillAdminGroup.Use(middleware.AdminAuthMiddleware(), requireSeckillManage)
 		seckillAdminGroup.POST("/init", seckillHandler.InitStock) // 初始化库存: POST /api/seckill/init
 
+		// 管理后台（需要后台角色，各接口按权限校验）
+		adminGroup := apiGroup.Group("/admin")
+		adminGroup.Use(middleware.AdminAuthMiddleware())
+		{
+			// 维护任务
+			adminGroup.POST("/products/bloom/rebuild", requireSystemMaintain, productHandler.RebuildBloom)                         // 重建商品布隆过滤器
+			adminGroup.POST("/products/rank/rebuild", requireSystemMaintain, productHandler.RebuildRank)                           // 重建商品销量榜
+			adminGroup.POST("/products/recommendations/recompute", requireSystemMaintain, productHandler.RecomputeRecommendations) // 重新计算商品推荐
+
+			// 商品导入导出
+			adminGroup.POST("/products/import", requireProductWrite, productHandler.Import)                // 批量导入商品
+			adminGroup.GET("/products/export", requireProductRead, productHandler.Export)                  // 导出商品CSV
+			adminGroup.GET("/products/:id/price-history", requireProductRead, productHandler.PriceHistory) // 商品价格变更记录
+
+			// 经营统计
+			adminGroup.GET("/stats/overview", requireStatsRead, statsHandler.Overview)        // 经营概览
+			adminGroup.GET("/stats/top-products", requireStatsRead, statsHandler.TopProducts) // 商品销售排行
+			adminGroup.GET("/stats/seckill", requireStatsRead, statsHandler.Seckill)          // 秒杀结果统计
+
+			// 角色权限
+			adminGroup.GET("/roles", requireRoleManage, roleHandler.ListRoles)                          // 角色列表
+			adminGroup.POST("/roles", requireRoleManage, roleHandler.CreateRole)                        // 创建角色
+			adminGroup.PUT("/roles/:id/permissions", requireRoleManage, roleHandler.SetRolePermissions) // 设置角色权限
+			adminGroup.GET("/permissions", requireRoleManage, roleHandler.ListPermissions)              // 权限列表
+			adminGroup.PUT("/users/:id/role", requireRoleManage, roleHandler.AssignRole)                // 分配用户角色
+
+			// 用户管理
+			adminGroup.GET("/users", requireUserManage, userAdminHandler.List)                              // 用户列表
+			adminGroup.GET("/users/audit-logs", requireUserManage, userAdminHandler.AuditLogs)              // 用户管理审计日志
+			adminGroup.GET("/users/:id/orders", requireUserManage, userAdminHandler.Orders)                 // 用户订单
+			adminGroup.POST("/users/:id/disable", requireUserManage, userAdminHandler.Disable)              // 禁用账号
+			adminGroup.POST("/users/:id/enable", requireUserManage, userAdminHandler.Enable)                // 启用账号
+			adminGroup.POST("/users/:id/reset-password", requireUserManage, userAdminHandler.ResetPassword) // 要求重置密码
+			adminGroup.POST("/users/:id/unlock", requireUserManage, userAdminHandler.Unlock)                // 解除登录锁定
+
+			// 店铺审核
+			adminGroup.GET("/shops", requireShopManage, shopHandler.AdminList)            // 店铺列表
+			adminGroup.POST("/shops/:id/approve", requireShopManage, shopHandler.Approve) // 审核通过 / 恢复营业
+			adminGroup.POST("/shops/:id/reject", requireShopManage, shopHandler.Reject)   // 驳回申请
+			adminGroup.POST("/shops/:id/suspend", requireShopManage, shopHandler.Suspend) // 店铺停业
+		}
+
+		// 店铺（需要登录）
+		shopGroup := apiGroup.Group("/shop")
+		shopGroup.Use(middleware.AuthMiddleware())
+		{
+			shopGroup.POST("/apply", shopHandler.Apply)    // 申请开店
+			shopGroup.GET("/mine", shopHandler.Mine)       // 我的店铺
+			shopGroup.PUT("/mine", shopHandler.UpdateMine) // 修改店铺资料
+
+			// 商家后台（店铺审核通过且未停业）
+			merchantGroup := shopGroup.Group("")
+			merchantGroup.Use(middleware.MerchantMiddleware())
+			merchantGroup.GET("/products", shopHandler.Products)                // 本店商品列表
+			merchantGroup.POST("/products", shopHandler.CreateProduct)          // 发布商品
+			merchantGroup.PUT("/products/:id", shopHandler.UpdateProduct)       // 修改商品
+			merchantGroup.DELETE("/products/:id", shopHandler.DeleteProduct)    // 删除商品
+			merchantGroup.PUT("/products/:id/stock", shopHandler.SetStock)      // 设置库存
+			merchantGroup.POST("/seckill/init", shopHandler.InitSeckill)        // 初始化秒杀库存
+			merchantGroup.GET("/orders", shopHandler.Orders)                    // 本店订单列表
+			merchantGroup.GET("/orders/:order_no", shopHandler.Order)           // 本店订单详情
+			merchantGroup.POST("/orders/:order_no/ship", shopHandler.ShipOrder) // 订单发货
+		}
+
 		// --- 新增：购物车模块 ---
 		cartGroup := apiGroup.Group("/cart")
 		cartGroup.Use(middleware.AuthMiddleware())
@@ -127,7 +230,26 @@ func Setup(r *gin.Engine) {
 		profileGroup := apiGroup.Group("/user")
 		profileGroup.Use(middleware.AuthMiddleware())
 		{
-			profileGroup.GET("/profile", userHandler.GetProfile) // 获取个人信息
+			profileGroup.GET("/profile", userHandler.GetProfile)          // 获取个人信息
+			profileGroup.PUT("/profile", profileHandler.UpdateProfile)    // 修改个人资料
+			profileGroup.DELETE("/account", profileHandler.DeleteAccount) // 注销账号
+
+			// 绑定手机
+			profileGroup.POST("/phone/code", smsHandler.SendBindCode) // 发送绑定验证码
+			profileGroup.PUT("/phone", smsHandler.BindPhone)          // 绑定或更换手机号
+
+			// 登录设备管理
+			profileGroup.GET("/sessions", sessionHandler.List)          // 登录设备列表
+			profileGroup.DELETE("/sessions/:id", sessionHandler.Delete) // 下线登录设备
+
+			// 收藏
+			profileGroup.POST("/favorites", favoriteHandler.Add)                  // 收藏商品
+			profileGroup.DELETE("/favorites/:product_id", favoriteHandler.Remove) // 取消收藏
+			profileGroup.GET("/favorites", favoriteHandler.List)                  // 收藏列表
+
+			// 浏览记录
+			profileGroup.GET("/history", historyHandler.List)     // 浏览记录
+			profileGroup.DELETE("/history", historyHandler.Clear) // 清空浏览记录（?product_id= 删除单条）
 		}
 
 		// --- 新增：认证模块 ---
@@ -136,6 +258,24 @@ func Setup(r *gin.Engine) {
 			authGroup.POST("/refresh-token", authHandler.RefreshToken)                                  // 刷新Token: POST /api/auth/refresh-token
 			authGroup.POST("/change-password", middleware.AuthMiddleware(), authHandler.ChangePassword) // 修改密码: POST /api/auth/change-password
 			authGroup.POST("/logout", middleware.AuthMiddleware(), authHandler.Logout)                  // 退出登录: POST /api/auth/logout
+			authGroup.POST("/logout-all", middleware.AuthMiddleware(), authHandler.LogoutAll)           // 退出所有设备: POST /api/auth/logout-all
+
+			// 邮箱验证与找回密码（未登录接口按IP限流）
+			authGroup.POST("/email/verification", middleware.AuthMiddleware(), authHandler.SendVerificationEmail) // 发送验证邮件
+			authGroup.POST("/email/verify", middleware.LoginRateLimit(), authHandler.VerifyEmail)                 // 验证邮箱
+			authGroup.POST("/password/forgot", middleware.LoginRateLimit(), authHandler.ForgotPassword)           // 找回密码
+			authGroup.POST("/password/reset", middleware.LoginRateLimit(), authHandler.ResetPassword)             // 重置密码
+
+			// 两步验证（TOTP）
+			twoFactorGroup := authGroup.Group("/2fa")
+			twoFactorGroup.Use(middleware.AuthMiddleware())
+			{
+				twoFactorGroup.GET("", twoFactorHandler.Status)                              // 两步验证状态
+				twoFactorGroup.POST("/setup", twoFactorHandler.Setup)                        // 获取密钥和二维码链接
+				twoFactorGroup.POST("/enable", twoFactorHandler.Enable)                      // 启用
+				twoFactorGroup.POST("/disable", twoFactorHandler.Disable)                    // 关闭
+				twoFactorGroup.POST("/backup-codes", twoFactorHandler.RegenerateBackupCodes) // 重新生成备用码
+			}
 		}
 
 		// --- 新增：文件上传模块 ---
@@ -155,22 +295,13 @@ func Setup(r *gin.Engine) {
 		wechatPayGroup := apiGroup.Group("/pay/wechat")
 		wechatPayGroup.Use(middleware.AuthMiddleware())
 		{
-			wechatPayGroup.POST("/unified-order", wechatPayHandler.UnifiedOrder) // 统一下单: POST /api/pay/wechat/unified-order
-			wechatPayGroup.GET("/query", wechatPayHandler.QueryOrder)            // 订单查询: GET /api/pay/wechat/query
-			wechatPayGroup.POST("/close", wechatPayHandler.CloseOrder)           // 关闭订单: POST /api/pay/wechat/close
-			wechatPayGroup.POST("/refund", wechatPayHandler.Refund)              // 申请退款: POST /api/pay/wechat/refund
+			wechatPayGroup.POST("/unified-order", requireVerifiedEmail, wechatPayHandler.UnifiedOrder) // 统一下单: POST /api/pay/wechat/unified-order
+			wechatPayGroup.GET("/query", wechatPayHandler.QueryOrder)                                  // 订单查询: GET /api/pay/wechat/query
+			wechatPayGroup.POST("/close", wechatPayHandler.CloseOrder)                                 // 关闭订单: POST /api/pay/wechat/close
+			wechatPayGroup.POST("/refund", wechatPayHandler.Refund)                                    // 申请退款: POST /api/pay/wechat/refund
 		}
 
 		// 微信支付回调（无需认证）
 		apiGroup.POST("/pay/wechat/notify", wechatPayHandler.Notify) // 支付回调: POST /api/pay/wechat/notify
 	}
 }
-
-// RegisterShopRoutes 商家管理路由（预留）
-func RegisterShopRoutes(r *gin.Engine) {
-	shopGroup := r.Group("/api/shop")
-	shopGroup.Use(middleware.AuthMiddleware())
-	{
-		// 商家相关接口
-	}
-}
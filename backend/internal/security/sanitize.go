@@ -0,0 +1,110 @@
+package security
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// allowedHTMLTags 富文本白名单：标签 -> 允许的属性
+var allowedHTMLTags = map[string]map[string]bool{
+	"p": {}, "br": {}, "hr": {}, "div": {}, "span": {},
+	"b": {}, "strong": {}, "i": {}, "em": {}, "u": {}, "s": {}, "sub": {}, "sup": {},
+	"h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+	"ul": {}, "ol": {}, "li": {}, "blockquote": {}, "pre": {}, "code": {},
+	"table": {}, "thead": {}, "tbody": {}, "tr": {},
+	"th":  {"colspan": true, "rowspan": true},
+	"td":  {"colspan": true, "rowspan": true},
+	"a":   {"href": true, "title": true},
+	"img": {"src": true, "alt": true, "width": true, "height": true},
+}
+
+// droppedHTMLTags 连同内容一起丢弃的危险标签
+var droppedHTMLTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true,
+	"embed": true, "noscript": true, "template": true, "svg": true, "math": true,
+}
+
+// voidHTMLTags 无需闭合的空元素
+var voidHTMLTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+// SanitizeHTML 按白名单清洗富文本
+// 不在白名单内的标签会被去掉但保留文本，script/style 等标签连同内容一起删除，
+// href/src 只允许 http(s) 或站内相对路径，杜绝 javascript: 之类的协议
+func SanitizeHTML(input string) string {
+	z := html.NewTokenizer(strings.NewReader(input))
+	var b strings.Builder
+	skipDepth := 0
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			// io.EOF 或解析错误，已写入的内容都是安全的
+			return b.String()
+
+		case html.TextToken:
+			if skipDepth == 0 {
+				b.WriteString(html.EscapeString(string(z.Text())))
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if droppedHTMLTags[tok.Data] {
+				if tt == html.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+			attrs, ok := allowedHTMLTags[tok.Data]
+			if !ok {
+				continue
+			}
+			b.WriteString("<" + tok.Data)
+			for _, attr := range tok.Attr {
+				if !attrs[attr.Key] {
+					continue
+				}
+				if (attr.Key == "href" || attr.Key == "src") && !isSafeURL(attr.Val) {
+					continue
+				}
+				b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+			}
+			if tok.Data == "a" {
+				b.WriteString(` rel="nofollow noopener"`)
+			}
+			b.WriteString(">")
+
+		case html.EndTagToken:
+			tok := z.Token()
+			if droppedHTMLTags[tok.Data] {
+				if skipDepth > 0 {
+					skipDepth--
+				}
+				continue
+			}
+			if skipDepth > 0 || voidHTMLTags[tok.Data] {
+				continue
+			}
+			if _, ok := allowedHTMLTags[tok.Data]; ok {
+				b.WriteString("</" + tok.Data + ">")
+			}
+		}
+	}
+}
+
+// isSafeURL 检查链接是否为 http(s) 或站内相对路径
+// 浏览器会把 \ 当作 /，并忽略链接中的制表符和换行，/\evil.com、/<TAB>/evil.com 都会变成协议相对的站外链接
+func isSafeURL(raw string) bool {
+	u := strings.ToLower(strings.TrimSpace(raw))
+	if strings.ContainsFunc(u, func(r rune) bool { return r < 0x20 || r == 0x7f }) {
+		return false
+	}
+	if len(u) > 1 && u[0] == '/' && (u[1] == '/' || u[1] == '\\') {
+		return false
+	}
+	return strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "/")
+}
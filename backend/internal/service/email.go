@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gomall/backend/internal/config"
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/mailer"
+	"gomall/backend/internal/redis"
+	"gomall/backend/internal/repository"
+	"gomall/backend/pkg/jwt"
+	"gomall/backend/pkg/password"
+
+	"go.uber.org/zap"
+)
+
+// 邮箱验证与找回密码
+// 邮件中的链接携带签名的一次性 Token（jwt.ActionClaims），有效期见 mail.verify_ttl_hours / reset_ttl_minutes：
+// - 邮箱验证 Token 绑定邮箱，邮箱变更或已验证后失效
+// - 重置密码 Token 绑定当前密码哈希的摘要，密码修改后失效
+// 找回密码接口无论邮箱是否存在都返回成功，避免泄露注册信息
+
+var (
+	// ErrEmailAlreadyVerified 邮箱已验证
+	ErrEmailAlreadyVerified = errors.New("邮箱已验证")
+	// ErrEmailNotVerified 邮箱未验证
+	ErrEmailNotVerified = errors.New("请先验证邮箱")
+	// ErrEmailNotSet 手机号注册的账号尚未填写邮箱
+	ErrEmailNotSet = errors.New("请先填写邮箱")
+	// ErrActionTokenInvalid 链接无效或已使用
+	ErrActionTokenInvalid = errors.New("链接无效或已使用")
+	// ErrActionTokenExpired 链接已过期
+	ErrActionTokenExpired = errors.New("链接已过期，请重新获取")
+	// ErrMailTooFrequent 邮件发送过于频繁
+	ErrMailTooFrequent = errors.New("邮件发送过于频繁，请稍后再试")
+	// ErrMailSendFailed 邮件发送失败
+	ErrMailSendFailed = errors.New("邮件发送失败，请稍后再试")
+)
+
+const (
+	// mailCooldown 同一用户同类邮件的最短发送间隔
+	mailCooldown = time.Minute
+	// mailSendTimeout 单封邮件的发送超时
+	mailSendTimeout = 30 * time.Second
+
+	mailKindVerify = "verify"
+	mailKindReset  = "reset"
+)
+
+// mailSettings 邮件相关配置
+type mailSettings struct {
+	linkBaseURL     string
+	verifyTTL       time.Duration
+	resetTTL        time.Duration
+	requireVerified bool
+}
+
+// loadMailSettings 读取 mail 配置，未配置时使用默认值
+func loadMailSettings() mailSettings {
+	settings := mailSettings{
+		linkBaseURL: "http://localhost:3000",
+		verifyTTL:   24 * time.Hour,
+		resetTTL:    30 * time.Minute,
+	}
+	mailConfig := config.GetMail()
+	if mailConfig == nil {
+		return settings
+	}
+	if baseURL := os.ExpandEnv(mailConfig.GetString("link_base_url")); baseURL != "" {
+		settings.linkBaseURL = strings.TrimRight(baseURL, "/")
+	}
+	if hours := mailConfig.GetInt("verify_ttl_hours"); hours > 0 {
+		settings.verifyTTL = time.Duration(hours) * time.Hour
+	}
+	if minutes := mailConfig.GetInt("reset_ttl_minutes"); minutes > 0 {
+		settings.resetTTL = time.Duration(minutes) * time.Minute
+	}
+	settings.requireVerified = mailConfig.GetBool("require_verified_email")
+	return settings
+}
+
+// EmailService 邮箱验证与找回密码服务
+type EmailService struct {
+	userRepo *repository.UserRepository
+}
+
+// NewEmailService 创建邮箱服务
+func NewEmailService() *EmailService {
+	return &EmailService{
+		userRepo: repository.NewUserRepository(),
+	}
+}
+
+// ForgotPasswordRequest 找回密码请求
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6,max=20"`
+}
+
+// VerifyEmailRequest 邮箱验证请求
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// SendVerification 向用户当前邮箱发送验证邮件
+func (s *EmailService) SendVerification(ctx context.Context, userID uint) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	if IsPlaceholderEmail(user.Email) {
+		return ErrEmailNotSet
+	}
+	if !acquireMailCooldown(ctx, mailKindVerify, user.ID) {
+		return ErrMailTooFrequent
+	}
+
+	settings := loadMailSettings()
+	token, err := jwt.GenerateActionToken(jwt.SubjectEmailVerify, user.ID, user.Email, "", settings.verifyTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("%s，您好：\n\n请点击以下链接验证您的邮箱（%d 小时内有效）：\n%s\n\n如果这不是您本人的操作，请忽略本邮件。\n",
+		user.Username, int(settings.verifyTTL.Hours()), actionLink(settings, "/verify-email", token))
+	return sendMail(ctx, &mailer.Message{To: user.Email, Subject: "GoMall 邮箱验证", Body: body})
+}
+
+// sendVerificationAsync 注册后异步发送验证邮件，失败只记录日志
+func (s *EmailService) sendVerificationAsync(userID uint) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+		if err := s.SendVerification(ctx, userID); err != nil {
+			logger.Warn("验证邮件发送失败", zap.Uint("user_id", userID), zap.Error(err))
+		}
+	}()
+}
+
+// VerifyEmail 校验邮箱验证链接并标记邮箱已验证
+func (s *EmailService) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := parseActionToken(token, jwt.SubjectEmailVerify)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrActionTokenInvalid
+		}
+		return err
+	}
+	if user.Email != claims.Email {
+		return ErrActionTokenInvalid
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	updated, err := s.userRepo.MarkEmailVerified(user.ID, claims.Email)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrActionTokenInvalid
+	}
+	return nil
+}
+
+// ForgotPassword 发送重置密码邮件
+// 邮箱未注册、发送过于频繁或发送失败时同样返回，只记录日志，响应时间与邮箱是否存在无关
+func (s *EmailService) ForgotPassword(email string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+		if err := s.sendPasswordReset(ctx, email); err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+			logger.Warn("重置密码邮件发送失败", zap.Error(err))
+		}
+	}()
+}
+
+func (s *EmailService) sendPasswordReset(ctx context.Context, email string) error {
+	if IsPlaceholderEmail(email) {
+		return repository.ErrUserNotFound
+	}
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		return err
+	}
+	if !acquireMailCooldown(ctx, mailKindReset, user.ID) {
+		return ErrMailTooFrequent
+	}
+
+	settings := loadMailSettings()
+	token, err := jwt.GenerateActionToken(jwt.SubjectPasswordReset, user.ID, user.Email, passwordBinding(user.Password), settings.resetTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("%s，您好：\n\n请点击以下链接重置密码（%d 分钟内有效，只能使用一次）：\n%s\n\n如果这不是您本人的操作，请忽略本邮件，您的密码不会改变。\n",
+		user.Username, int(settings.resetTTL.Minutes()), actionLink(settings, "/reset-password", token))
+	return sendMail(ctx, &mailer.Message{To: user.Email, Subject: "GoMall 重置密码", Body: body})
+}
+
+// ResetPassword 校验重置密码链接并设置新密码
+// 成功后该用户已签发的 Token 全部失效，登录锁定解除，管理员要求重置密码而锁定的账号恢复正常；
+// 能收到邮件说明邮箱可用，一并标记为已验证
+func (s *EmailService) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
+	claims, err := parseActionToken(req.Token, jwt.SubjectPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrActionTokenInvalid
+		}
+		return err
+	}
+	if claims.Binding == "" || claims.Binding != passwordBinding(user.Password) {
+		return ErrActionTokenInvalid
+	}
+
+	newHash, err := password.HashPassword(req.NewPassword)
+	if err != nil {
+		return errors.New("密码加密失败")
+	}
+	replaced, err := s.userRepo.ReplacePassword(user.ID, user.Password, newHash)
+	if err != nil {
+		return err
+	}
+	if !replaced {
+		return ErrActionTokenInvalid
+	}
+
+	if _, err := RevokeAllTokens(ctx, user.ID); err != nil {
+		logger.Error("重置密码后Token吊销失败", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+	if redis.Client != nil {
+		if _, err := redis.ClearLoginLock(ctx, redis.LoginScopeAccount, user.ID); err != nil {
+			logger.Warn("重置密码后登录锁定解除失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+	}
+	if _, err := s.userRepo.ActivateLocked(user.ID); err != nil {
+		logger.Warn("重置密码后账号状态恢复失败", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+	if user.EmailVerifiedAt == nil && user.Email == claims.Email {
+		if _, err := s.userRepo.MarkEmailVerified(user.ID, user.Email); err != nil {
+			logger.Warn("重置密码后邮箱验证状态更新失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// EnsureEmailVerified 检查用户邮箱已验证，mail.require_verified_email 关闭时直接通过
+func EnsureEmailVerified(userID uint) error {
+	if !loadMailSettings().requireVerified {
+		return nil
+	}
+	user, err := repository.NewUserRepository().GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt == nil {
+		if IsPlaceholderEmail(user.Email) {
+			return ErrEmailNotSet
+		}
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// parseActionToken 解析邮件链接中的 Token，统一转换为业务错误
+func parseActionToken(token, subject string) (*jwt.ActionClaims, error) {
+	claims, err := jwt.ParseActionToken(token, subject)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrActionTokenExpired
+		}
+		return nil, ErrActionTokenInvalid
+	}
+	return claims, nil
+}
+
+// passwordBinding 密码哈希的摘要，写入重置密码 Token，不直接暴露哈希
+func passwordBinding(passwordHash string) string {
+	sum := sha256.Sum256([]byte(passwordHash))
+	return hex.EncodeToString(sum[:8])
+}
+
+// actionLink 生成邮件中的前端链接
+func actionLink(settings mailSettings, path, token string) string {
+	return settings.linkBaseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// acquireMailCooldown 检查发送冷却，Redis 不可用时不限制
+func acquireMailCooldown(ctx context.Context, kind string, userID uint) bool {
+	if redis.Client == nil {
+		return true
+	}
+	ok, err := redis.AcquireMailCooldown(ctx, kind, userID, mailCooldown)
+	if err != nil {
+		logger.Warn("邮件发送冷却读取失败", zap.Uint("user_id", userID), zap.Error(err))
+		return true
+	}
+	return ok
+}
+
+// sendMail 发送邮件，失败时记录日志并返回 ErrMailSendFailed
+func sendMail(ctx context.Context, msg *mailer.Message) error {
+	if err := mailer.Send(ctx, msg); err != nil {
+		logger.Error("邮件发送失败", zap.String("to", msg.To), zap.String("subject", msg.Subject), zap.Error(err))
+		return ErrMailSendFailed
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"time"
+
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/repository"
+)
+
+// 用户收藏
+// 收藏记录只保存商品ID，列表展示时通过商品缓存读取实时价格和库存，
+// 商品下架或被删除后收藏仍然保留，列表中标记为已下架
+
+// FavoriteService 收藏服务
+type FavoriteService struct {
+	favoriteRepo *repository.FavoriteRepository
+	productRepo  *repository.ProductRepository
+}
+
+// NewFavoriteService 创建收藏服务实例
+func NewFavoriteService() *FavoriteService {
+	return &FavoriteService{
+		favoriteRepo: repository.NewFavoriteRepository(),
+		productRepo:  repository.NewProductRepository(),
+	}
+}
+
+// AddFavoriteRequest 添加收藏请求
+type AddFavoriteRequest struct {
+	ProductID uint `json:"product_id" binding:"required"`
+}
+
+// FavoriteItemResponse 收藏列表项
+// 商品已被删除时只返回 ProductID，OffShelf 为 true
+type FavoriteItemResponse struct {
+	ProductID    uint    `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	ProductImage string  `json:"product_image"`
+	Price        float64 `json:"price"`
+	Stock        int     `json:"stock"`
+	InStock      bool    `json:"in_stock"`
+	OffShelf     bool    `json:"off_shelf"`
+	FavoritedAt  string  `json:"favorited_at"`
+}
+
+// Add 收藏商品，重复收藏不报错
+// 未上架（包括定时上架前）的商品也允许收藏，上架后用户可在收藏列表中看到
+func (s *FavoriteService) Add(userID uint, req *AddFavoriteRequest) error {
+	if _, err := s.productRepo.GetByIDWithCache(req.ProductID); err != nil {
+		return err
+	}
+
+	added, err := s.favoriteRepo.Add(userID, req.ProductID)
+	if err != nil {
+		return err
+	}
+	if added {
+		// 收藏人数随商品一起缓存
+		s.productRepo.InvalidateCache(req.ProductID)
+	}
+	return nil
+}
+
+// Remove 取消收藏，未收藏时不报错
+func (s *FavoriteService) Remove(userID, productID uint) error {
+	removed, err := s.favoriteRepo.Remove(userID, productID)
+	if err != nil {
+		return err
+	}
+	if removed {
+		s.productRepo.InvalidateCache(productID)
+	}
+	return nil
+}
+
+// List 分页获取用户收藏，附带商品实时价格和库存
+func (s *FavoriteService) List(userID uint, page, pageSize int) ([]FavoriteItemResponse, int64, error) {
+	favorites, total, err := s.favoriteRepo.ListByUserID(userID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	productIDs := make([]uint, len(favorites))
+	for i, f := range favorites {
+		productIDs[i] = f.ProductID
+	}
+	products, err := s.productRepo.GetByIDsWithCache(productIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	productMap := make(map[uint]*model.Product, len(products))
+	for i := range products {
+		productMap[products[i].ID] = &products[i]
+	}
+
+	now := time.Now()
+	items := make([]FavoriteItemResponse, len(favorites))
+	for i, f := range favorites {
+		item := FavoriteItemResponse{
+			ProductID:   f.ProductID,
+			OffShelf:    true,
+			FavoritedAt: f.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if p, ok := productMap[f.ProductID]; ok {
+			item.ProductName = p.Name
+			item.ProductImage = p.ImageURL
+			item.Price = p.Price
+			item.Stock = p.Stock
+			item.InStock = p.Stock > 0
+			item.OffShelf = !p.IsOnShelf(now)
+		}
+		items[i] = item
+	}
+	return items, total, nil
+}
+
+// IsFavorite 判断用户是否收藏了商品，查询失败时按未收藏处理
+func (s *FavoriteService) IsFavorite(userID, productID uint) bool {
+	favorited, err := s.favoriteRepo.FavoritedProductIDs(userID, []uint{productID})
+	if err != nil {
+		return false
+	}
+	return favorited[productID]
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/redis"
+	"gomall/backend/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// 浏览记录
+// 登录用户查看商品详情时写入 Redis（见 internal/redis/history.go），只保留最近的
+// historyMaxSize 条，historyTTL 内没有新的浏览则整体过期；所有人的浏览都会计入当天浏览量。
+// 浏览记录只存在 Redis 中，Redis 不可用时不记录，不影响详情接口。
+
+const (
+	// historyMaxSize 每个用户保留的浏览记录条数
+	historyMaxSize = 100
+	// historyTTL 浏览记录保留时间
+	historyTTL = 30 * 24 * time.Hour
+	// productViewsTTL 每日浏览量保留时间，覆盖排行榜的最长统计周期
+	productViewsTTL = 31 * 24 * time.Hour
+	// historyTimeout 单次 Redis 操作超时
+	historyTimeout = 200 * time.Millisecond
+)
+
+// ErrHistoryUnavailable 浏览记录不可用
+var ErrHistoryUnavailable = errors.New("浏览记录服务不可用")
+
+// HistoryService 浏览记录服务
+type HistoryService struct {
+	productRepo *repository.ProductRepository
+}
+
+// NewHistoryService 创建浏览记录服务实例
+func NewHistoryService() *HistoryService {
+	return &HistoryService{
+		productRepo: repository.NewProductRepository(),
+	}
+}
+
+// HistoryItemResponse 浏览记录项
+type HistoryItemResponse struct {
+	ProductID    uint    `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	ProductImage string  `json:"product_image"`
+	Price        float64 `json:"price"`
+	Stock        int     `json:"stock"`
+	OffShelf     bool    `json:"off_shelf"`
+	ViewedAt     string  `json:"viewed_at"`
+}
+
+// RecordView 记录一次商品浏览
+// userID 为 0 表示游客，只计入浏览量（全站和 category 分类排行）；失败只记录日志
+func (s *HistoryService) RecordView(ctx context.Context, userID, productID uint, category string) {
+	if redis.Client == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, historyTimeout)
+	defer cancel()
+
+	now := time.Now()
+	if err := redis.IncrRank(ctx, redis.ProductViewsPrefix, now, category, productID, 1, productViewsTTL); err != nil {
+		logger.Warn("商品浏览量统计失败", zap.Uint("product_id", productID), zap.Error(err))
+	}
+	if userID == 0 {
+		return
+	}
+	if err := redis.AddHistory(ctx, userID, productID, now, historyMaxSize, historyTTL); err != nil {
+		logger.Warn("浏览记录写入失败", zap.Uint("user_id", userID), zap.Uint("product_id", productID), zap.Error(err))
+	}
+}
+
+// List 分页获取浏览记录（按浏览时间倒序），附带商品实时信息
+// 已删除的商品从浏览记录中移除，因此单页条数可能少于 pageSize
+func (s *HistoryService) List(ctx context.Context, userID uint, page, pageSize int) ([]HistoryItemResponse, int64, error) {
+	if redis.Client == nil {
+		return nil, 0, ErrHistoryUnavailable
+	}
+
+	ids, viewedAt, total, err := redis.ListHistory(ctx, userID, int64((page-1)*pageSize), int64(pageSize))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	products, err := s.productRepo.GetByIDsWithCache(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	productMap := make(map[uint]*model.Product, len(products))
+	for i := range products {
+		productMap[products[i].ID] = &products[i]
+	}
+
+	now := time.Now()
+	items := make([]HistoryItemResponse, 0, len(ids))
+	var removed []uint
+	for i, id := range ids {
+		p, ok := productMap[id]
+		if !ok {
+			removed = append(removed, id)
+			continue
+		}
+		items = append(items, HistoryItemResponse{
+			ProductID:    p.ID,
+			ProductName:  p.Name,
+			ProductImage: p.ImageURL,
+			Price:        p.Price,
+			Stock:        p.Stock,
+			OffShelf:     !p.IsOnShelf(now),
+			ViewedAt:     viewedAt[i].Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	if len(removed) > 0 {
+		if err := redis.RemoveHistory(ctx, userID, removed...); err != nil {
+			logger.Warn("清理已删除商品的浏览记录失败", zap.Uint("user_id", userID), zap.Error(err))
+		} else {
+			total -= int64(len(removed))
+		}
+	}
+	return items, total, nil
+}
+
+// Remove 删除单个商品的浏览记录
+func (s *HistoryService) Remove(ctx context.Context, userID, productID uint) error {
+	if redis.Client == nil {
+		return ErrHistoryUnavailable
+	}
+	return redis.RemoveHistory(ctx, userID, productID)
+}
+
+// Clear 清空浏览记录
+func (s *HistoryService) Clear(ctx context.Context, userID uint) error {
+	if redis.Client == nil {
+		return ErrHistoryUnavailable
+	}
+	return redis.ClearHistory(ctx, userID)
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/repository"
+)
+
+// 购物车结算拆单
+// 结算时按店铺拆分：父订单（OrderType = 3）只用于支付，每个店铺一张子订单负责发货和退款。
+// 金额按分计算，各子订单实付之和等于父订单实付。
+// 父订单支付、取消时子订单随之更新；子订单不能单独支付或取消
+
+// ErrChildOrderOperation 子订单不能单独支付或取消
+var ErrChildOrderOperation = errors.New("该订单为合并结算的店铺子订单，请支付或取消所属的合并订单")
+
+// OrderItemResponse 订单商品明细
+type OrderItemResponse struct {
+	ProductID    uint    `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	ProductImage string  `json:"product_image"`
+	Price        float64 `json:"price"`
+	Quantity     int     `json:"quantity"`
+	PayAmount    float64 `json:"pay_amount"`
+}
+
+// checkoutLine 结算的一个购物车商品
+type checkoutLine struct {
+	product  *model.Product
+	quantity int
+}
+
+// buildCheckoutOrders 按店铺分组生成父订单和子订单
+func buildCheckoutOrders(userID uint, lines []checkoutLine) (*model.Order, []repository.ShopOrder) {
+	// 按店铺在购物车中首次出现的顺序分组
+	var shopIDs []uint
+	groups := make(map[uint][]checkoutLine)
+	for _, line := range lines {
+		if _, ok := groups[line.product.ShopID]; !ok {
+			shopIDs = append(shopIDs, line.product.ShopID)
+		}
+		groups[line.product.ShopID] = append(groups[line.product.ShopID], line)
+	}
+
+	children := make([]repository.ShopOrder, 0, len(shopIDs))
+	var total int64
+	quantity := 0
+	for _, shopID := range shopIDs {
+		group := groups[shopID]
+		items := make([]model.OrderItem, len(group))
+		var subtotal int64
+		childQuantity := 0
+		for j, line := range group {
+			amount := ToCents(line.product.Price) * int64(line.quantity)
+			items[j] = model.OrderItem{
+				ProductID:    line.product.ID,
+				ProductName:  line.product.Name,
+				ProductImage: line.product.ImageURL,
+				Price:        line.product.Price,
+				Quantity:     line.quantity,
+				PayAmount:    fromCents(amount),
+			}
+			subtotal += amount
+			childQuantity += line.quantity
+		}
+		total += subtotal
+		quantity += childQuantity
+
+		children = append(children, repository.ShopOrder{
+			Order: &model.Order{
+				OrderNo:      generateOrderNo(),
+				UserID:       userID,
+				ShopID:       shopID,
+				ProductName:  orderTitle(group),
+				ProductImage: group[0].product.ImageURL,
+				Quantity:     childQuantity,
+				TotalPrice:   fromCents(subtotal),
+				Status:       1, // 待支付
+				PayType:      1,
+				OrderType:    model.OrderTypeNormal,
+			},
+			Items: items,
+		})
+	}
+
+	parent := &model.Order{
+		OrderNo:      generateOrderNo(),
+		UserID:       userID,
+		ProductName:  orderTitle(lines),
+		ProductImage: lines[0].product.ImageURL,
+		Quantity:     quantity,
+		TotalPrice:   fromCents(total),
+		Status:       1, // 待支付
+		PayType:      1,
+		OrderType:    model.OrderTypeCombined,
+	}
+	return parent, children
+}
+
+// orderTitle 订单标题：第一个商品名称，多个商品时加上商品种数
+func orderTitle(lines []checkoutLine) string {
+	if len(lines) == 1 {
+		return lines[0].product.Name
+	}
+	return fmt.Sprintf("%s 等%d种商品", lines[0].product.Name, len(lines))
+}
+
+// ToCents 元转换为分，四舍五入避免浮点误差（19.99 元为 1999 分）
+func ToCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+// newOrderItemResponses 商品明细转换为响应结构
+func newOrderItemResponses(items []model.OrderItem) []OrderItemResponse {
+	responses := make([]OrderItemResponse, len(items))
+	for i, item := range items {
+		responses[i] = OrderItemResponse{
+			ProductID:    item.ProductID,
+			ProductName:  item.ProductName,
+			ProductImage: item.ProductImage,
+			Price:        item.Price,
+			Quantity:     item.Quantity,
+			PayAmount:    item.PayAmount,
+		}
+	}
+	return responses
+}
+
+// orderDetail 订单详情：父订单附带店铺子订单和商品明细，子订单附带商品明细和父订单号
+func orderDetail(orderRepo *repository.OrderRepository, order *model.Order) (*OrderResponse, error) {
+	response := newOrderResponse(order)
+
+	if order.OrderType == model.OrderTypeCombined {
+		children, err := orderRepo.ListChildren(order.ID)
+		if err != nil {
+			return nil, err
+		}
+		items, err := orderRepo.ListItemsByParent(order.ID)
+		if err != nil {
+			return nil, err
+		}
+		itemsByOrder := make(map[uint][]model.OrderItem, len(children))
+		for _, item := range items {
+			itemsByOrder[item.OrderID] = append(itemsByOrder[item.OrderID], item)
+		}
+		for i := range children {
+			child := newOrderResponse(&children[i])
+			child.ParentOrderNo = order.OrderNo
+			child.Items = newOrderItemResponses(itemsByOrder[children[i].ID])
+			response.Children = append(response.Children, child)
+		}
+		return &response, nil
+	}
+
+	if order.ParentID != 0 {
+		parent, err := orderRepo.GetByID(order.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		items, err := orderRepo.ListItems(order.ID)
+		if err != nil {
+			return nil, err
+		}
+		response.ParentOrderNo = parent.OrderNo
+		response.Items = newOrderItemResponses(items)
+	}
+	return &response, nil
+}
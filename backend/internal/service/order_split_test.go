@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+
+	"gomall/backend/internal/model"
+)
+
+func checkoutProduct(id, shopID uint, price float64) *model.Product {
+	return &model.Product{ID: id, ShopID: shopID, Name: "p", Price: price}
+}
+
+func TestBuildCheckoutOrders(t *testing.T) {
+	tests := []struct {
+		name            string
+		lines           []checkoutLine
+		wantParentTotal float64
+		wantChildTotals []float64
+	}{
+		{
+			name: "single shop",
+			lines: []checkoutLine{
+				{checkoutProduct(1, 1, 19.99), 2},
+			},
+			wantParentTotal: 39.98,
+			wantChildTotals: []float64{39.98},
+		},
+		{
+			name: "cent prices add up exactly",
+			lines: []checkoutLine{
+				{checkoutProduct(1, 1, 19.99), 1},
+				{checkoutProduct(2, 2, 0.01), 3},
+			},
+			wantParentTotal: 20.02,
+			wantChildTotals: []float64{19.99, 0.03},
+		},
+		{
+			name: "grouped by shop in cart order",
+			lines: []checkoutLine{
+				{checkoutProduct(1, 2, 10), 1},
+				{checkoutProduct(2, 1, 0.29), 1},
+				{checkoutProduct(3, 2, 0.57), 2},
+			},
+			wantParentTotal: 11.43,
+			wantChildTotals: []float64{11.14, 0.29},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent, children := buildCheckoutOrders(7, tt.lines)
+
+			if parent.OrderType != model.OrderTypeCombined || parent.UserID != 7 {
+				t.Fatalf("parent = %+v, want combined order of user 7", parent)
+			}
+			if ToCents(parent.TotalPrice) != ToCents(tt.wantParentTotal) {
+				t.Fatalf("parent total = %.2f, want %.2f", parent.TotalPrice, tt.wantParentTotal)
+			}
+			if len(children) != len(tt.wantChildTotals) {
+				t.Fatalf("got %d child orders, want %d", len(children), len(tt.wantChildTotals))
+			}
+
+			var childSum, quantity int64
+			for i, child := range children {
+				if ToCents(child.Order.TotalPrice) != ToCents(tt.wantChildTotals[i]) {
+					t.Errorf("child %d total = %.2f, want %.2f", i, child.Order.TotalPrice, tt.wantChildTotals[i])
+				}
+				childSum += ToCents(child.Order.TotalPrice)
+				quantity += int64(child.Order.Quantity)
+
+				// 明细实付为单价 × 数量，之和等于子订单实付
+				var itemSum int64
+				for _, item := range child.Items {
+					if ToCents(item.PayAmount) != ToCents(item.Price)*int64(item.Quantity) {
+						t.Errorf("child %d item %d pay amount %.2f, want price × quantity", i, item.ProductID, item.PayAmount)
+					}
+					itemSum += ToCents(item.PayAmount)
+				}
+				if itemSum != ToCents(child.Order.TotalPrice) {
+					t.Errorf("child %d items sum %d, want %d", i, itemSum, ToCents(child.Order.TotalPrice))
+				}
+			}
+			if childSum != ToCents(parent.TotalPrice) {
+				t.Errorf("children sum %d, parent total %d", childSum, ToCents(parent.TotalPrice))
+			}
+			if quantity != int64(parent.Quantity) {
+				t.Errorf("children quantity %d, parent quantity %d", quantity, parent.Quantity)
+			}
+		})
+	}
+}
+
+func TestToCents(t *testing.T) {
+	for amount, want := range map[float64]int64{19.99: 1999, 0.29: 29, 0.57: 57, 100: 10000} {
+		if got := ToCents(amount); got != want {
+			t.Errorf("ToCents(%v) = %d, want %d", amount, got, want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"gomall/backend/internal/config"
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/redis"
+
+	"go.uber.org/zap"
+)
+
+// 商品布隆过滤器：拦截不存在的商品ID，防止恶意请求穿透到缓存和数据库
+// Redis 不可用或位图尚未构建时放行（fail open），只影响防护效果，不影响正常请求
+
+const (
+	defaultProductBloomBits   = 1 << 24 // 约 2MB，100 万商品时误判率约 0.01%
+	defaultProductBloomHashes = 7
+
+	// productBloomBatchSize 重建时每批读取的商品数
+	productBloomBatchSize = 1000
+
+	// productBloomTimeout 单次布隆过滤器查询超时
+	productBloomTimeout = 200 * time.Millisecond
+)
+
+var (
+	productBloomOnce sync.Once
+	productBloom     *redis.BloomFilter
+)
+
+// getProductBloom 获取商品布隆过滤器（首次使用时按配置创建）
+func getProductBloom() *redis.BloomFilter {
+	productBloomOnce.Do(func() {
+		bits, hashes := uint64(defaultProductBloomBits), uint(defaultProductBloomHashes)
+		if config.Config != nil {
+			if cacheConfig := config.GetCache(); cacheConfig != nil {
+				if v := cacheConfig.GetInt64("bloom_bits"); v > 0 {
+					bits = uint64(v)
+				}
+				if v := cacheConfig.GetInt("bloom_hashes"); v > 0 {
+					hashes = uint(v)
+				}
+			}
+		}
+		productBloom = redis.NewBloomFilter(redis.CacheKey("bloom", redis.ProductCachePrefix), bits, hashes)
+	})
+	return productBloom
+}
+
+// MightExist 判断商品是否可能存在
+// 返回 false 时商品一定不存在，可以直接返回"商品不存在"
+func (s *ProductService) MightExist(ctx context.Context, productID uint) bool {
+	if redis.Client == nil {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(ctx, productBloomTimeout)
+	defer cancel()
+
+	exists, err := getProductBloom().MightContain(ctx, strconv.FormatUint(uint64(productID), 10))
+	if err != nil {
+		return true
+	}
+	return exists
+}
+
+// RebuildBloom 根据商品表重建布隆过滤器，返回写入的商品数量
+// 启动时和管理员手动触发时调用
+func (s *ProductService) RebuildBloom(ctx context.Context) (int, error) {
+	if redis.Client == nil {
+		return 0, errors.New("Redis未初始化")
+	}
+
+	bloom := getProductBloom()
+	count := 0
+	var lastID uint
+	err := bloom.Rebuild(ctx, func(add func(items ...string) error) error {
+		return s.productRepo.ScanIDs(0, productBloomBatchSize, func(ids []uint) error {
+			count += len(ids)
+			lastID = ids[len(ids)-1]
+			return add(productIDStrings(ids)...)
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// 重建期间新建的商品写入的是旧位图，RENAME 之后补写一次
+	err = s.productRepo.ScanIDs(lastID, productBloomBatchSize, func(ids []uint) error {
+		count += len(ids)
+		return bloom.Add(ctx, productIDStrings(ids)...)
+	})
+	return count, err
+}
+
+// addToBloom 新建商品后写入布隆过滤器
+func (s *ProductService) addToBloom(productIDs ...uint) {
+	if redis.Client == nil || len(productIDs) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), productBloomTimeout)
+	defer cancel()
+
+	if err := getProductBloom().Add(ctx, productIDStrings(productIDs)...); err != nil {
+		logger.Warn("商品写入布隆过滤器失败", zap.Uints("product_ids", productIDs), zap.Error(err))
+	}
+}
+
+// productIDStrings 商品ID转为布隆过滤器元素
+func productIDStrings(ids []uint) []string {
+	items := make([]string, len(ids))
+	for i, id := range ids {
+		items[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return items
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/repository"
+)
+
+// ErrProductImageLimit 图集数量超出上限
+var ErrProductImageLimit = errors.New("商品图片数量超出上限")
+
+// maxProductImages 单个商品最多允许的图片数量
+const maxProductImages = 20
+
+// AddProductImagesRequest 添加商品图片请求
+// URLs 通常是 /api/upload 或 /api/upload/multi 返回的地址
+type AddProductImagesRequest struct {
+	URLs []string `json:"urls" binding:"required,min=1,max=20,dive,required,max=500"`
+}
+
+// SortProductImagesRequest 调整图片顺序请求
+// ImageIDs 按期望的展示顺序排列
+type SortProductImagesRequest struct {
+	ImageIDs []uint `json:"image_ids" binding:"required,min=1"`
+}
+
+// ProductImageResponse 商品图片响应
+type ProductImageResponse struct {
+	ID     uint   `json:"id"`
+	URL    string `json:"url"`
+	Sort   int    `json:"sort"`
+	IsMain bool   `json:"is_main"`
+}
+
+// ListImages 获取商品图集
+func (s *ProductService) ListImages(productID uint) ([]ProductImageResponse, error) {
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return nil, err
+	}
+
+	images, err := s.imageRepo.ListByProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+	return toProductImageResponses(images), nil
+}
+
+// AddImages 为商品追加图片
+// 新图片追加在图集末尾；如果商品还没有主图，第一张新图片自动成为主图
+func (s *ProductService) AddImages(productID uint, req *AddProductImagesRequest) ([]ProductImageResponse, error) {
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.imageRepo.ListByProductID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing)+len(req.URLs) > maxProductImages {
+		return nil, ErrProductImageLimit
+	}
+
+	maxSort, err := s.imageRepo.MaxSort(productID)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]model.ProductImage, 0, len(req.URLs))
+	for i, url := range req.URLs {
+		images = append(images, model.ProductImage{
+			ProductID: productID,
+			URL:       strings.TrimSpace(url),
+			Sort:      maxSort + i + 1,
+		})
+	}
+	if err := s.imageRepo.CreateBatch(images); err != nil {
+		return nil, errors.New("添加商品图片失败")
+	}
+	// 详情缓存包含图集，图集的每次变化都要清除
+	s.productRepo.InvalidateCache(productID)
+
+	hasMain := false
+	for _, img := range existing {
+		if img.IsMain {
+			hasMain = true
+			break
+		}
+	}
+	if !hasMain {
+		if err := s.imageRepo.SetMain(&images[0]); err != nil {
+			return nil, errors.New("设置主图失败")
+		}
+		s.productRepo.InvalidateCache(productID)
+		s.productRepo.InvalidateListCache()
+	}
+
+	return s.ListImages(productID)
+}
+
+// SortImages 调整图集顺序
+func (s *ProductService) SortImages(productID uint, req *SortProductImagesRequest) ([]ProductImageResponse, error) {
+	if err := s.imageRepo.UpdateSort(productID, req.ImageIDs); err != nil {
+		return nil, err
+	}
+	s.productRepo.InvalidateCache(productID)
+	return s.ListImages(productID)
+}
+
+// SetMainImage 设置主图
+// 主图会同步到 Product.ImageURL，列表、购物车和订单快照都继续使用该字段
+func (s *ProductService) SetMainImage(productID, imageID uint) error {
+	image, err := s.getOwnedImage(productID, imageID)
+	if err != nil {
+		return err
+	}
+	if err := s.imageRepo.SetMain(image); err != nil {
+		return err
+	}
+	s.productRepo.InvalidateCache(productID)
+	s.productRepo.InvalidateListCache()
+	return nil
+}
+
+// RemoveImage 删除图片
+func (s *ProductService) RemoveImage(productID, imageID uint) error {
+	image, err := s.getOwnedImage(productID, imageID)
+	if err != nil {
+		return err
+	}
+	if err := s.imageRepo.Delete(image); err != nil {
+		return err
+	}
+	s.productRepo.InvalidateCache(productID)
+	if image.IsMain {
+		s.productRepo.InvalidateListCache()
+	}
+	return nil
+}
+
+// getOwnedImage 获取属于指定商品的图片，防止跨商品操作
+func (s *ProductService) getOwnedImage(productID, imageID uint) (*model.ProductImage, error) {
+	image, err := s.imageRepo.GetByID(imageID)
+	if err != nil {
+		return nil, err
+	}
+	if image.ProductID != productID {
+		return nil, repository.ErrProductImageNotFound
+	}
+	return image, nil
+}
+
+// toProductImageResponses 转换图集响应
+func toProductImageResponses(images []model.ProductImage) []ProductImageResponse {
+	responses := make([]ProductImageResponse, len(images))
+	for i, img := range images {
+		responses[i] = ProductImageResponse{
+			ID:     img.ID,
+			URL:    img.URL,
+			Sort:   img.Sort,
+			IsMain: img.IsMain,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/repository"
+	"gomall/backend/internal/security"
+)
+
+// 商品批量导入导出
+// 导入：解析 CSV/JSON -> 逐行校验（由接口层使用全局验证器完成）-> 按 SKU 分批 upsert
+// 导出：按筛选条件分批读取，边读边写 CSV，导出文件可直接用于再次导入
+// 商家可以编辑商品文字字段，导出时以 = + - @ 制表符 回车开头的单元格前加 '，
+// 防止表格软件把单元格当作公式执行；导入 CSV 时去掉这个前缀
+
+var (
+	// ErrUnsupportedImportFormat 不支持的导入文件格式
+	ErrUnsupportedImportFormat = errors.New("仅支持 .csv 和 .json 文件")
+	// ErrTooManyImportRows 导入行数超出上限
+	ErrTooManyImportRows = fmt.Errorf("单次最多导入 %d 行", maxProductImportRows)
+)
+
+const (
+	// maxProductImportRows 单次导入的最大行数
+	maxProductImportRows = 10000
+	// productImportBatchSize 每个事务写入的行数
+	productImportBatchSize = 100
+	// productExportBatchSize 导出时每批读取的行数
+	productExportBatchSize = 500
+)
+
+// productCSVHeader 导入导出共用的 CSV 列
+var productCSVHeader = []string{"sku", "name", "description", "detail", "price", "stock", "category", "image_url", "status"}
+
+// utf8BOM 导出时写入 BOM，Excel 打开中文不乱码；导入时自动去除
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ProductImportRow 导入行
+// Row 为数据行号（从1开始，不含 CSV 表头），用于错误报告
+type ProductImportRow struct {
+	Row         int     `json:"-"`
+	SKU         string  `json:"sku" validate:"required,max=64"`
+	Name        string  `json:"name" validate:"required,max=200"`
+	Description string  `json:"description"`
+	Detail      string  `json:"detail"`
+	Price       float64 `json:"price" validate:"gt=0"`
+	Stock       int     `json:"stock" validate:"gte=0"`
+	Category    string  `json:"category" validate:"max=50"`
+	ImageURL    string  `json:"image_url" validate:"max=500"`
+	Status      *int    `json:"status" validate:"omitempty,oneof=0 1"` // 不填时新建商品上架，已有商品保持原状态
+}
+
+// ProductImportError 单行导入错误
+type ProductImportError struct {
+	Row     int    `json:"row"`
+	SKU     string `json:"sku,omitempty"`
+	Message string `json:"message"`
+}
+
+// ProductImportResult 导入结果报告
+type ProductImportResult struct {
+	Total   int                  `json:"total"`
+	Created int                  `json:"created"`
+	Updated int                  `json:"updated"`
+	Failed  int                  `json:"failed"`
+	Errors  []ProductImportError `json:"errors"`
+}
+
+// ProductExportFilter 导出筛选条件
+type ProductExportFilter = repository.ProductExportFilter
+
+// ParseProductImportFile 根据文件扩展名解析导入文件
+// 返回成功解析的行和解析失败的行；文件整体无法解析时返回 error
+func ParseProductImportFile(filename string, r io.Reader) ([]ProductImportRow, []ProductImportError, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return parseProductCSV(r)
+	case ".json":
+		return parseProductJSON(r)
+	default:
+		return nil, nil, ErrUnsupportedImportFormat
+	}
+}
+
+// parseProductCSV 解析 CSV，第一行为表头，列顺序不限
+func parseProductCSV(r io.Reader) ([]ProductImportRow, []ProductImportError, error) {
+	br := bufio.NewReader(r)
+	if head, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(head, utf8BOM) {
+		_, _ = br.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取表头失败: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"sku", "name", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("缺少必需列: %s", required)
+		}
+	}
+
+	var rows []ProductImportRow
+	var rowErrors []ProductImportError
+	for rowNum := 1; ; rowNum++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("第 %d 行解析失败: %w", rowNum, err)
+		}
+		if rowNum > maxProductImportRows {
+			return nil, nil, ErrTooManyImportRows
+		}
+
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(unescapeCSVCell(record[i]))
+			}
+			return ""
+		}
+
+		row := ProductImportRow{
+			Row:         rowNum,
+			SKU:         get("sku"),
+			Name:        get("name"),
+			Description: get("description"),
+			Detail:      get("detail"),
+			Category:    get("category"),
+			ImageURL:    get("image_url"),
+		}
+
+		var parseErrs []string
+		if v := get("price"); v != "" {
+			if row.Price, err = strconv.ParseFloat(v, 64); err != nil {
+				parseErrs = append(parseErrs, "price 不是有效数字")
+			}
+		}
+		if v := get("stock"); v != "" {
+			if row.Stock, err = strconv.Atoi(v); err != nil {
+				parseErrs = append(parseErrs, "stock 不是有效整数")
+			}
+		}
+		if v := get("status"); v != "" {
+			status, err := strconv.Atoi(v)
+			if err != nil {
+				parseErrs = append(parseErrs, "status 不是有效整数")
+			} else {
+				row.Status = &status
+			}
+		}
+		if len(parseErrs) > 0 {
+			rowErrors = append(rowErrors, ProductImportError{Row: rowNum, SKU: row.SKU, Message: strings.Join(parseErrs, "; ")})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, rowErrors, nil
+}
+
+// parseProductJSON 解析 JSON 数组，每个元素一行
+func parseProductJSON(r io.Reader) ([]ProductImportRow, []ProductImportError, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, nil, fmt.Errorf("JSON 格式错误，应为商品数组: %w", err)
+	}
+	if len(items) > maxProductImportRows {
+		return nil, nil, ErrTooManyImportRows
+	}
+
+	var rows []ProductImportRow
+	var rowErrors []ProductImportError
+	for i, item := range items {
+		var row ProductImportRow
+		if err := json.Unmarshal(item, &row); err != nil {
+			rowErrors = append(rowErrors, ProductImportError{Row: i + 1, Message: "字段类型错误: " + err.Error()})
+			continue
+		}
+		row.Row = i + 1
+		row.SKU = strings.TrimSpace(row.SKU)
+		rows = append(rows, row)
+	}
+	return rows, rowErrors, nil
+}
+
+// ImportProducts 按 SKU 批量导入已校验的行
+// rowErrors 为解析/校验阶段已失败的行，会合并进最终报告；operatorID 记入价格变更记录
+func (s *ProductService) ImportProducts(operatorID uint, rows []ProductImportRow, rowErrors []ProductImportError) *ProductImportResult {
+	result := &ProductImportResult{
+		Total:  len(rows) + len(rowErrors),
+		Errors: append([]ProductImportError{}, rowErrors...),
+	}
+
+	// 同一文件中重复的 SKU 只保留第一次出现的行
+	firstRow := make(map[string]int, len(rows))
+	unique := make([]ProductImportRow, 0, len(rows))
+	for _, row := range rows {
+		if first, ok := firstRow[row.SKU]; ok {
+			result.Errors = append(result.Errors, ProductImportError{
+				Row:     row.Row,
+				SKU:     row.SKU,
+				Message: fmt.Sprintf("SKU 与第 %d 行重复", first),
+			})
+			continue
+		}
+		firstRow[row.SKU] = row.Row
+		unique = append(unique, row)
+	}
+
+	for start := 0; start < len(unique); start += productImportBatchSize {
+		end := start + productImportBatchSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+		batch := unique[start:end]
+
+		items := make([]repository.ProductUpsertItem, len(batch))
+		for i, row := range batch {
+			items[i] = repository.ProductUpsertItem{Product: row.toProduct(), StatusSet: row.Status != nil}
+		}
+
+		createdIDs, updatedIDs, err := s.productRepo.UpsertBySKU(items, operatorID)
+		if err != nil {
+			// 整批事务已回滚，批内所有行都记为失败
+			for _, row := range batch {
+				result.Errors = append(result.Errors, ProductImportError{
+					Row:     row.Row,
+					SKU:     row.SKU,
+					Message: "写入失败（同批数据已回滚）: " + err.Error(),
+				})
+			}
+			continue
+		}
+
+		result.Created += len(createdIDs)
+		result.Updated += len(updatedIDs)
+		for _, id := range updatedIDs {
+			s.productRepo.InvalidateCache(id)
+		}
+		for _, id := range createdIDs {
+			s.productRepo.InvalidateCache(id)
+		}
+		// 被更新的商品可能是刚恢复的软删除 SKU，删除期间重建的布隆过滤器中没有它们
+		s.addToBloom(createdIDs...)
+		s.addToBloom(updatedIDs...)
+		s.productRepo.InvalidateListCache()
+	}
+
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Row < result.Errors[j].Row
+	})
+	result.Failed = len(result.Errors)
+	return result
+}
+
+// ExportProductsCSV 按筛选条件导出商品 CSV，边查询边写入 w
+func (s *ProductService) ExportProductsCSV(w io.Writer, filter ProductExportFilter) error {
+	if _, err := w.Write(utf8BOM); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(productCSVHeader); err != nil {
+		return err
+	}
+
+	err := s.productRepo.ExportInBatches(filter, productExportBatchSize, func(products []model.Product) error {
+		for i := range products {
+			p := &products[i]
+			if err := writer.Write([]string{
+				escapeCSVCell(productSKU(p)),
+				escapeCSVCell(p.Name),
+				escapeCSVCell(p.Description),
+				escapeCSVCell(p.Detail),
+				strconv.FormatFloat(p.Price, 'f', 2, 64),
+				strconv.Itoa(p.Stock),
+				escapeCSVCell(p.Category),
+				escapeCSVCell(p.ImageURL),
+				strconv.Itoa(p.Status),
+			}); err != nil {
+				return err
+			}
+		}
+		// 每批刷新一次，让客户端尽早收到数据
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// toProduct 导入行转换为商品模型
+// 未指定状态时按新建商品的默认值（上架）填充，更新已有商品时不使用该默认值
+func (row *ProductImportRow) toProduct() *model.Product {
+	sku := row.SKU
+	status := 1
+	if row.Status != nil {
+		status = *row.Status
+	}
+	return &model.Product{
+		SKU:         &sku,
+		Name:        row.Name,
+		Description: row.Description,
+		Detail:      security.SanitizeHTML(row.Detail),
+		Price:       row.Price,
+		Stock:       row.Stock,
+		Category:    row.Category,
+		ImageURL:    row.ImageURL,
+		Status:      status,
+	}
+}
+
+// productSKU 读取商品 SKU，未设置时返回空字符串
+func productSKU(p *model.Product) string {
+	if p.SKU == nil {
+		return ""
+	}
+	return *p.SKU
+}
+
+// csvFormulaPrefixes 表格软件会当作公式解析的单元格首字符
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// escapeCSVCell 以公式字符开头的单元格前加 '，表格软件按文本显示
+func escapeCSVCell(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeCSVCell 去掉 escapeCSVCell 添加的 ' 前缀，导出文件再次导入时内容不变
+func unescapeCSVCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"time"
+
+	"gomall/backend/internal/model"
+)
+
+// 商品价格记录
+// 每次调价都会在 product_price_history 中留下记录（旧价格、新价格、操作人、原因），
+// 详情页据此展示"近30天最低价"，满足促销价格展示规范
+
+// lowestPriceWindow 最低价统计窗口
+const lowestPriceWindow = 30 * 24 * time.Hour
+
+// ProductPriceHistoryResponse 价格变更记录响应
+type ProductPriceHistoryResponse struct {
+	ID         uint    `json:"id"`
+	OldPrice   float64 `json:"old_price"`
+	NewPrice   float64 `json:"new_price"`
+	OperatorID uint    `json:"operator_id"`
+	Reason     string  `json:"reason"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// GetPriceHistory 分页获取商品价格变更记录
+func (s *ProductService) GetPriceHistory(productID uint, page, pageSize int) ([]ProductPriceHistoryResponse, int64, error) {
+	if _, err := s.productRepo.GetByID(productID); err != nil {
+		return nil, 0, err
+	}
+
+	records, total, err := s.productRepo.ListPriceHistory(productID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]ProductPriceHistoryResponse, len(records))
+	for i, r := range records {
+		responses[i] = ProductPriceHistoryResponse{
+			ID:         r.ID,
+			OldPrice:   r.OldPrice,
+			NewPrice:   r.NewPrice,
+			OperatorID: r.OperatorID,
+			Reason:     r.Reason,
+			CreatedAt:  r.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+	return responses, total, nil
+}
+
+// lowestPrice30d 近30天最低价：窗口内变更记录中的最低价与当前价取较小值
+// lowest 为 nil（没有变更记录或查询失败）时退回当前价格，不影响详情展示
+func lowestPrice30d(price float64, lowest *float64) float64 {
+	if lowest == nil || *lowest > price {
+		return price
+	}
+	return *lowest
+}
+
+// newPriceHistory 价格发生变化时构建变更记录，未变化时返回 nil
+func newPriceHistory(oldPrice, newPrice float64, operatorID uint, reason string) *model.ProductPriceHistory {
+	if oldPrice == newPrice {
+		return nil
+	}
+	return &model.ProductPriceHistory{
+		OldPrice:   oldPrice,
+		NewPrice:   newPrice,
+		OperatorID: operatorID,
+		Reason:     reason,
+	}
+}
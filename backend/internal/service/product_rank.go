@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/redis"
+	"gomall/backend/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// 商品排行榜（销量榜、浏览榜）
+// 销量：订单支付成功时按支付日期累加（全站 + 分类），每晚根据订单表重建最近 rankRebuildDays 天，
+//       修正漏记（如 Redis 短暂不可用）和重复计数
+// 浏览量：商品详情接口累加（见 history.go），只存在 Redis 中，不参与重建
+// 每日排行榜到期自动删除；读取时过滤已删除和已下架的商品
+
+// ErrRankUnavailable 排行榜不可用
+var ErrRankUnavailable = errors.New("排行榜服务不可用")
+
+// ErrRankRebuilding 其他请求或实例正在重建销量榜
+var ErrRankRebuilding = errors.New("销量榜正在重建中")
+
+const (
+	// productSalesTTL 每日销量榜保留时间，覆盖最长统计周期（一周）和重建窗口
+	productSalesTTL = 8 * 24 * time.Hour
+	// rankUnionTTL 多日合并结果的缓存时间
+	rankUnionTTL = time.Minute
+	// rankDefaultLimit 默认返回条数
+	rankDefaultLimit = 10
+	// rankRebuildDays 每晚重建的销量榜天数（含当天）
+	rankRebuildDays = 7
+	// rankRebuildHour 每天重建的时间（时）
+	rankRebuildHour = 3
+	// rankRebuildLockKey 多实例部署时只允许一个实例重建
+	rankRebuildLockKey = "gomall:lock:rank_rebuild"
+	// rankTimeout 排行榜读取和支付后累加销量的超时
+	rankTimeout = 500 * time.Millisecond
+)
+
+// ProductRankRequest 排行榜查询参数
+type ProductRankRequest struct {
+	Type     string `form:"type" binding:"omitempty,oneof=sales views"` // 默认 sales
+	Category string `form:"category" binding:"max=50"`
+	Period   string `form:"period" binding:"omitempty,oneof=day week"` // 默认 day
+	Limit    int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// ProductRankResponse 排行榜条目
+type ProductRankResponse struct {
+	Rank      int     `json:"rank"`
+	ProductID uint    `json:"product_id"`
+	Name      string  `json:"name"`
+	ImageURL  string  `json:"image_url"`
+	Price     float64 `json:"price"`
+	Category  string  `json:"category"`
+	Score     int64   `json:"score"` // 销量或浏览次数
+}
+
+// GetRank 查询商品排行榜
+func (s *ProductService) GetRank(ctx context.Context, req *ProductRankRequest) ([]ProductRankResponse, error) {
+	if redis.Client == nil {
+		return nil, ErrRankUnavailable
+	}
+	ctx, cancel := context.WithTimeout(ctx, rankTimeout)
+	defer cancel()
+
+	prefix := redis.ProductSalesPrefix
+	if req.Type == "views" {
+		prefix = redis.ProductViewsPrefix
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = rankDefaultLimit
+	}
+	days := 1
+	if req.Period == "week" {
+		days = 7
+	}
+
+	now := time.Now()
+	keys := make([]string, days)
+	for i := range keys {
+		keys[i] = redis.RankKey(prefix, now.AddDate(0, 0, -i), req.Category)
+	}
+	unionKey := redis.RankKey(prefix+":week", now, req.Category)
+
+	// 多取一些，给被过滤掉的已删除/已下架商品留出余量
+	items, err := redis.TopRank(ctx, keys, unionKey, rankUnionTTL, int64(limit*2))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductID
+	}
+	products, err := s.productRepo.GetByIDsWithCache(ids)
+	if err != nil {
+		return nil, err
+	}
+	productMap := make(map[uint]*model.Product, len(products))
+	for i := range products {
+		productMap[products[i].ID] = &products[i]
+	}
+
+	responses := make([]ProductRankResponse, 0, limit)
+	for _, item := range items {
+		p, ok := productMap[item.ProductID]
+		if !ok || !p.IsOnShelf(now) {
+			continue
+		}
+		responses = append(responses, ProductRankResponse{
+			Rank:      len(responses) + 1,
+			ProductID: p.ID,
+			Name:      p.Name,
+			ImageURL:  p.ImageURL,
+			Price:     p.Price,
+			Category:  p.Category,
+			Score:     int64(item.Score),
+		})
+		if len(responses) == limit {
+			break
+		}
+	}
+	return responses, nil
+}
+
+// recordOrderSales 订单支付成功后累加销量，失败只记录日志（由每晚重建修正）
+// 合并支付父订单按各子订单的商品明细累加
+func recordOrderSales(productRepo *repository.ProductRepository, order *model.Order) {
+	if redis.Client == nil || order.PaidAt == nil {
+		return
+	}
+
+	if order.OrderType != model.OrderTypeCombined {
+		recordProductSales(productRepo, order, order.ProductID, order.Quantity)
+		return
+	}
+	items, err := repository.NewOrderRepository().ListItemsByParent(order.ID)
+	if err != nil {
+		logger.Warn("商品销量统计失败", zap.String("order_no", order.OrderNo), zap.Error(err))
+		return
+	}
+	for _, item := range items {
+		recordProductSales(productRepo, order, item.ProductID, item.Quantity)
+	}
+}
+
+// recordProductSales 按商品当前分类累加销量
+func recordProductSales(productRepo *repository.ProductRepository, order *model.Order, productID uint, quantity int) {
+	product, err := productRepo.GetByIDWithCache(productID)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rankTimeout)
+	defer cancel()
+	if err := redis.IncrRank(ctx, redis.ProductSalesPrefix, *order.PaidAt, product.Category, productID, float64(quantity), productSalesTTL); err != nil {
+		logger.Warn("商品销量统计失败", zap.String("order_no", order.OrderNo), zap.Error(err))
+	}
+}
+
+// RunRankRebuilder 每天 rankRebuildHour 点重建销量榜，阻塞运行，应在独立协程中调用
+func (s *ProductService) RunRankRebuilder() {
+	for {
+		time.Sleep(time.Until(nextDailyRun(time.Now(), rankRebuildHour)))
+
+		err := s.RebuildSalesRank(context.Background())
+		if errors.Is(err, ErrRankRebuilding) {
+			// 多实例部署时由抢到锁的实例执行
+			continue
+		}
+		if err != nil {
+			logger.Error("商品销量榜重建失败", zap.Error(err))
+			continue
+		}
+		logger.Info("商品销量榜重建完成")
+	}
+}
+
+// nextDailyRun 计算 now 之后下一次每日任务的执行时间（当地时间 hour 点整）
+func nextDailyRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// RebuildSalesRank 根据订单表重建最近 rankRebuildDays 天的销量榜（全站 + 分类）
+// 其他请求或实例正在重建时返回 ErrRankRebuilding
+func (s *ProductService) RebuildSalesRank(ctx context.Context) error {
+	if redis.Client == nil {
+		return ErrRankUnavailable
+	}
+	locked, err := redis.Client.SetNX(ctx, rankRebuildLockKey, 1, time.Hour).Result()
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return ErrRankRebuilding
+	}
+	defer redis.Client.Del(ctx, rankRebuildLockKey)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for i := 0; i < rankRebuildDays; i++ {
+		day := today.AddDate(0, 0, -i)
+		sales, err := s.orderRepo.SalesBetween(day, day.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+
+		overall := make(map[uint]float64, len(sales))
+		byCategory := make(map[string]map[uint]float64)
+		for _, item := range sales {
+			overall[item.ProductID] += float64(item.Quantity)
+			if item.Category == "" {
+				continue
+			}
+			if byCategory[item.Category] == nil {
+				byCategory[item.Category] = make(map[uint]float64)
+			}
+			byCategory[item.Category][item.ProductID] += float64(item.Quantity)
+		}
+
+		// 商品改分类或删除后，旧的分类榜不会再被覆盖，需要单独删除
+		dayKey := redis.RankKey(redis.ProductSalesPrefix, day, "")
+		existingKeys, err := redis.RankCategoryKeys(ctx, redis.ProductSalesPrefix, day)
+		if err != nil {
+			return err
+		}
+		for _, key := range existingKeys {
+			if _, ok := byCategory[strings.TrimPrefix(key, dayKey+":")]; ok {
+				continue
+			}
+			if err := redis.Client.Del(ctx, key).Err(); err != nil {
+				return err
+			}
+		}
+
+		if err := redis.ReplaceRank(ctx, dayKey, overall, productSalesTTL); err != nil {
+			return err
+		}
+		for category, scores := range byCategory {
+			if err := redis.ReplaceRank(ctx, redis.RankKey(redis.ProductSalesPrefix, day, category), scores, productSalesTTL); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
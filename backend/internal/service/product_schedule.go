@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/rabbitmq"
+	"gomall/backend/internal/redis"
+
+	"go.uber.org/zap"
+)
+
+// 商品定时上下架
+// 管理员设置 publish_at/unpublish_at，定时任务到期后修改 status 并清空已执行的时间，
+// 同时删除商品缓存、发布商品事件（搜索索引等下游服务订阅 product_event_queue 更新）。
+// 定时任务延迟期间，列表和详情通过 Product.EffectiveStatus 按计划时间计算实际状态。
+
+// ErrInvalidSchedule 上下架时间不合法
+var ErrInvalidSchedule = errors.New("下架时间必须晚于上架时间")
+
+const (
+	// productScheduleInterval 定时任务扫描间隔
+	productScheduleInterval = 30 * time.Second
+	// productScheduleBatchSize 单次扫描最多处理的商品数
+	productScheduleBatchSize = 500
+	// productScheduleLockKey 多实例部署时只允许一个实例执行
+	productScheduleLockKey = "gomall:lock:product_schedule"
+)
+
+// applySchedule 把上下架计划写入商品
+// 上架时间已过的直接上架；上架时间在未来的先保持下架，等待定时任务
+func applySchedule(product *model.Product, publishAt, unpublishAt *time.Time, now time.Time) error {
+	if publishAt != nil && unpublishAt != nil && !unpublishAt.After(*publishAt) {
+		return ErrInvalidSchedule
+	}
+
+	if publishAt != nil {
+		if publishAt.After(now) {
+			product.PublishAt = publishAt
+			product.Status = 0
+		} else {
+			product.PublishAt = nil
+			product.Status = 1
+		}
+	}
+	if unpublishAt != nil {
+		if product.PublishAt != nil && !unpublishAt.After(*product.PublishAt) {
+			return ErrInvalidSchedule
+		}
+		product.UnpublishAt = unpublishAt
+	}
+	return nil
+}
+
+// RunScheduler 定时执行到期的上下架计划，阻塞运行，应在独立协程中调用
+func (s *ProductService) RunScheduler() {
+	ticker := time.NewTicker(productScheduleInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		count, err := s.ProcessDueSchedules(context.Background())
+		if err != nil {
+			logger.Error("商品定时上下架执行失败", zap.Error(err))
+			continue
+		}
+		if count > 0 {
+			logger.Info("商品定时上下架执行完成", zap.Int("count", count))
+		}
+	}
+}
+
+// ProcessDueSchedules 执行一轮到期的上下架计划，返回实际变更的商品数
+func (s *ProductService) ProcessDueSchedules(ctx context.Context) (int, error) {
+	// 多实例部署时通过 Redis 锁保证同一时刻只有一个实例执行
+	// 条件更新本身是幂等的，Redis 不可用时直接执行
+	if redis.Client != nil {
+		locked, err := redis.Client.SetNX(ctx, productScheduleLockKey, 1, productScheduleInterval).Result()
+		if err == nil && !locked {
+			return 0, nil
+		}
+		if err == nil {
+			defer redis.Client.Del(ctx, productScheduleLockKey)
+		}
+	}
+
+	now := time.Now()
+	products, err := s.productRepo.FindDueSchedules(now, productScheduleBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := range products {
+		p := &products[i]
+
+		var changed bool
+		var event string
+		var status int
+		if p.UnpublishAt != nil && !now.Before(*p.UnpublishAt) {
+			changed, err = s.productRepo.ApplyUnpublish(p.ID, *p.UnpublishAt)
+			event, status = rabbitmq.ProductEventUnpublished, 0
+		} else {
+			changed, err = s.productRepo.ApplyPublish(p.ID, *p.PublishAt)
+			event, status = rabbitmq.ProductEventPublished, 1
+		}
+		if err != nil {
+			logger.Error("商品定时上下架更新失败", zap.Uint("product_id", p.ID), zap.Error(err))
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		count++
+		s.productRepo.InvalidateCache(p.ID)
+		if err := rabbitmq.PublishProductEvent(ctx, &rabbitmq.ProductEventMessage{
+			ProductID:  p.ID,
+			Event:      event,
+			Status:     status,
+			OccurredAt: now,
+		}); err != nil {
+			logger.Warn("商品事件发布失败", zap.Uint("product_id", p.ID), zap.String("event", event), zap.Error(err))
+		}
+	}
+	if count > 0 {
+		s.productRepo.InvalidateListCache()
+	}
+	return count, nil
+}
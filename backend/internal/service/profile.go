@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gomall/backend/internal/config"
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/redis"
+	"gomall/backend/internal/repository"
+	"gomall/backend/pkg/password"
+
+	"go.uber.org/zap"
+)
+
+// 个人资料与注销账号
+// 昵称、头像直接修改；头像只接受上传服务返回的地址（upload.domain + /uploads/）。
+// 修改邮箱需要当前密码（手机号注册的占位邮箱除外），新邮箱重新进入未验证状态并发送验证邮件；
+// 修改手机号需要发送到新手机号的验证码，与绑定手机号相同（见 sms.go）。
+// 注销账号：身份校验通过且没有未完成的订单（包括本人店铺的订单）后，吊销全部 Token，删除购物车、收藏、会话等个人数据，
+// 店铺置为已停业，清除用户名、邮箱、手机号等个人信息后软删除，原用户名和邮箱可以被重新注册
+
+var (
+	// ErrEmailTaken 邮箱已被其他账号使用
+	ErrEmailTaken = errors.New("邮箱已被注册")
+	// ErrAvatarInvalid 头像不是上传服务返回的地址
+	ErrAvatarInvalid = errors.New("头像地址无效，请先通过上传接口上传图片")
+	// ErrPasswordRequired 敏感操作需要当前密码
+	ErrPasswordRequired = errors.New("请输入当前密码")
+	// ErrAccountBusy 存在未完成的订单，不能注销
+	ErrAccountBusy = errors.New("存在未完成的订单，请完成或取消后再注销账号")
+	// ErrShopBusy 用户的店铺存在未完成的订单，不能注销
+	ErrShopBusy = errors.New("店铺存在未完成的订单，请处理完毕后再注销账号")
+)
+
+// UpdateProfileRequest 修改个人资料请求，未提交的字段保持不变
+type UpdateProfileRequest struct {
+	Nickname *string `json:"nickname" binding:"omitempty,max=50"`
+	Avatar   *string `json:"avatar" binding:"omitempty,max=500"`
+	Email    *string `json:"email" binding:"omitempty,email,max=100"`
+	// Password 当前密码，修改邮箱时需要
+	Password  string `json:"password"`
+	Phone     string `json:"phone"`
+	PhoneCode string `json:"phone_code" binding:"required_with=Phone,omitempty,len=6,numeric"`
+}
+
+// DeleteAccountRequest 注销账号请求
+// 身份校验使用当前密码，或已绑定手机号收到的登录验证码；启用了两步验证时还需要动态验证码
+type DeleteAccountRequest struct {
+	Password      string `json:"password"`
+	SmsCode       string `json:"sms_code"`
+	TwoFactorCode string `json:"two_factor_code"`
+}
+
+// ProfileService 个人资料服务
+type ProfileService struct {
+	userRepo  *repository.UserRepository
+	orderRepo *repository.OrderRepository
+}
+
+// NewProfileService 创建个人资料服务
+func NewProfileService() *ProfileService {
+	return &ProfileService{
+		userRepo:  repository.NewUserRepository(),
+		orderRepo: repository.NewOrderRepository(),
+	}
+}
+
+// UpdateProfile 修改个人资料
+func (s *ProfileService) UpdateProfile(ctx context.Context, userID uint, req *UpdateProfileRequest) (*UserResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Nickname != nil {
+		updates["nickname"] = strings.TrimSpace(*req.Nickname)
+	}
+	if req.Avatar != nil {
+		avatar := strings.TrimSpace(*req.Avatar)
+		if avatar != "" && !strings.HasPrefix(avatar, uploadURLPrefix()) {
+			return nil, ErrAvatarInvalid
+		}
+		updates["avatar"] = avatar
+	}
+
+	newEmail := ""
+	if req.Email != nil {
+		newEmail = strings.ToLower(strings.TrimSpace(*req.Email))
+		if newEmail == strings.ToLower(user.Email) {
+			newEmail = ""
+		}
+	}
+	if newEmail != "" {
+		if IsPlaceholderEmail(newEmail) || strings.HasSuffix(newEmail, "@"+repository.DeletedEmailDomain) {
+			return nil, ErrEmailTaken
+		}
+		// 占位邮箱的账号没有设置过密码，凭登录状态即可设置邮箱
+		if !IsPlaceholderEmail(user.Email) {
+			if req.Password == "" {
+				return nil, ErrPasswordRequired
+			}
+			if !password.CheckPassword(req.Password, user.Password) {
+				return nil, ErrInvalidPassword
+			}
+		}
+		if exist, _ := s.userRepo.GetByEmail(newEmail); exist != nil && exist.ID != userID {
+			return nil, ErrEmailTaken
+		}
+	}
+
+	// 手机号先校验验证码，失败时资料不做任何修改
+	if req.Phone != "" && req.Phone != user.Phone {
+		if _, err := NewSmsService().BindPhone(ctx, userID, &PhoneCodeRequest{Phone: req.Phone, Code: req.PhoneCode}); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.userRepo.UpdateProfile(userID, updates); err != nil {
+		return nil, err
+	}
+	if newEmail != "" {
+		if err := s.userRepo.ChangeEmail(userID, newEmail); err != nil {
+			return nil, err
+		}
+		NewEmailService().sendVerificationAsync(userID)
+	}
+
+	user, err = s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return newUserResponse(user), nil
+}
+
+// DeleteAccount 注销账号
+func (s *ProfileService) DeleteAccount(ctx context.Context, userID uint, req *DeleteAccountRequest) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case req.Password != "":
+		if !password.CheckPassword(req.Password, user.Password) {
+			return ErrInvalidPassword
+		}
+	case req.SmsCode != "" && user.PhoneVerifiedAt != nil:
+		if err := NewSmsService().verifyCode(ctx, SmsPurposeLogin, user.Phone, req.SmsCode); err != nil {
+			return err
+		}
+	default:
+		return ErrPasswordRequired
+	}
+
+	if err := NewTwoFactorService().verifyCode(userID, req.TwoFactorCode, true); err != nil && !errors.Is(err, ErrTwoFactorNotEnabled) {
+		if req.TwoFactorCode == "" {
+			return ErrTwoFactorRequired
+		}
+		return err
+	}
+
+	unfinished, err := s.orderRepo.CountUnfinishedByUser(userID)
+	if err != nil {
+		return err
+	}
+	if unfinished > 0 {
+		return ErrAccountBusy
+	}
+	shop, err := repository.NewShopRepository().GetByOwner(userID)
+	switch {
+	case err == nil:
+		unfinished, err = s.orderRepo.CountUnfinishedByShop(shop.ID)
+		if err != nil {
+			return err
+		}
+		if unfinished > 0 {
+			return ErrShopBusy
+		}
+	case !errors.Is(err, repository.ErrShopNotFound):
+		return err
+	}
+
+	// 先吊销 Token，失败时不删除账号，避免已注销的账号仍能访问
+	if _, err := RevokeAllTokens(ctx, userID); err != nil {
+		return err
+	}
+	favoriteProductIDs, err := s.userRepo.DeleteAccount(userID)
+	if err != nil {
+		return err
+	}
+	// 商品详情缓存中含收藏数
+	productRepo := repository.NewProductRepository()
+	for _, id := range favoriteProductIDs {
+		productRepo.InvalidateCache(id)
+	}
+	if shop != nil {
+		// 店铺已停业，其商品不再出现在列表中
+		productRepo.InvalidateListCache()
+	}
+	if redis.Client != nil {
+		if err := redis.ClearHistory(ctx, userID); err != nil {
+			logger.Warn("注销账号清除浏览记录失败", zap.Uint("user_id", userID), zap.Error(err))
+		}
+	}
+	logger.Info("用户注销账号", zap.Uint("user_id", userID))
+	return nil
+}
+
+// uploadURLPrefix 上传服务返回的文件地址前缀，与文件上传接口使用相同的 upload.domain 配置
+func uploadURLPrefix() string {
+	uploadConfig := config.GetApp().Sub("upload")
+	if uploadConfig == nil {
+		uploadConfig = config.Config.Sub("app")
+	}
+	domain := uploadConfig.GetString("domain")
+	if domain == "" {
+		domain = "http://localhost:8080"
+	}
+	return strings.TrimRight(domain, "/") + "/uploads/"
+}
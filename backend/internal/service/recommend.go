@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/redis"
+	"gomall/backend/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// "经常一起购买"推荐
+// 离线任务（cmd/recommend 或每日定时）根据购物篮计算商品共现：
+// - 订单：每个订单只有一件商品，同一用户相隔不超过 basketSessionGap 的已支付订单视为一个购物篮
+//   （购物车结算一次生成的多个订单自然落在同一个购物篮中）
+// - 购物车：每个用户当前的购物车视为一个购物篮
+// 共现次数达到 recommendMinSupport 的商品对按余弦相似度打分，每个商品保留前 recommendTopK 个，
+// 结果整体写入 product_recommendations。接口读取时过滤已删除、已下架和无库存的商品。
+
+const (
+	// recommendOrderWindow 参与计算的订单时间范围
+	recommendOrderWindow = 180 * 24 * time.Hour
+	// basketSessionGap 同一用户两个订单间隔不超过该值时视为同一购物篮
+	basketSessionGap = 30 * time.Minute
+	// maxBasketSize 单个购物篮参与计算的最大商品数，避免异常大的购物篮产生大量商品对
+	maxBasketSize = 50
+	// recommendMinSupport 商品对至少共现的次数
+	recommendMinSupport = 2
+	// recommendTopK 每个商品保留的推荐数
+	recommendTopK = 20
+	// recommendDefaultLimit 接口默认返回条数
+	recommendDefaultLimit = 10
+	// recommendJobHour 每天计算的时间（时）
+	recommendJobHour = 4
+	// recommendLockKey 多实例部署时只允许一个实例计算
+	recommendLockKey = "gomall:lock:recommend"
+)
+
+// RecommendService 商品推荐服务
+type RecommendService struct {
+	recommendRepo *repository.RecommendationRepository
+	orderRepo     *repository.OrderRepository
+	cartRepo      *repository.CartRepository
+	productRepo   *repository.ProductRepository
+}
+
+// NewRecommendService 创建商品推荐服务实例
+func NewRecommendService() *RecommendService {
+	return &RecommendService{
+		recommendRepo: repository.NewRecommendationRepository(),
+		orderRepo:     repository.NewOrderRepository(),
+		cartRepo:      repository.NewCartRepository(),
+		productRepo:   repository.NewProductRepository(),
+	}
+}
+
+// ProductRecommendationResponse 推荐商品
+type ProductRecommendationResponse struct {
+	ProductID uint    `json:"product_id"`
+	Name      string  `json:"name"`
+	ImageURL  string  `json:"image_url"`
+	Price     float64 `json:"price"`
+	Category  string  `json:"category"`
+}
+
+// RecommendResult 一次计算的统计信息
+type RecommendResult struct {
+	Baskets         int `json:"baskets"`
+	Pairs           int `json:"pairs"`
+	Recommendations int `json:"recommendations"`
+}
+
+// ListForProduct 获取商品的"经常一起购买"推荐，过滤已删除、已下架和无库存的商品
+func (s *RecommendService) ListForProduct(productID uint, limit int) ([]ProductRecommendationResponse, error) {
+	if limit <= 0 {
+		limit = recommendDefaultLimit
+	}
+
+	// 按保留上限全部读出，过滤后仍能凑够 limit 条
+	recommendations, err := s.recommendRepo.ListByProductID(productID, recommendTopK)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(recommendations))
+	for i, r := range recommendations {
+		ids[i] = r.RecommendedID
+	}
+	products, err := s.productRepo.GetByIDsWithCache(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	responses := make([]ProductRecommendationResponse, 0, limit)
+	for i := range products {
+		p := &products[i]
+		if !p.IsOnShelf(now) || p.Stock <= 0 {
+			continue
+		}
+		responses = append(responses, ProductRecommendationResponse{
+			ProductID: p.ID,
+			Name:      p.Name,
+			ImageURL:  p.ImageURL,
+			Price:     p.Price,
+			Category:  p.Category,
+		})
+		if len(responses) == limit {
+			break
+		}
+	}
+	return responses, nil
+}
+
+// RunDaily 每天 recommendJobHour 点重新计算推荐，阻塞运行，应在独立协程中调用
+func (s *RecommendService) RunDaily() {
+	for {
+		time.Sleep(time.Until(nextDailyRun(time.Now(), recommendJobHour)))
+
+		result, err := s.Compute(context.Background())
+		if err != nil {
+			logger.Error("商品推荐计算失败", zap.Error(err))
+			continue
+		}
+		if result != nil {
+			logger.Info("商品推荐计算完成",
+				zap.Int("baskets", result.Baskets),
+				zap.Int("pairs", result.Pairs),
+				zap.Int("recommendations", result.Recommendations))
+		}
+	}
+}
+
+// Compute 根据订单和购物车重新计算全部推荐
+// 其他实例正在计算时返回 nil, nil；Redis 不可用时不加锁直接计算
+func (s *RecommendService) Compute(ctx context.Context) (*RecommendResult, error) {
+	if redis.Client != nil {
+		locked, err := redis.Client.SetNX(ctx, recommendLockKey, 1, time.Hour).Result()
+		if err == nil && !locked {
+			return nil, nil
+		}
+		if err == nil {
+			defer redis.Client.Del(ctx, recommendLockKey)
+		}
+	}
+
+	counter := newCooccurrenceCounter()
+
+	// 订单：同一用户时间相近的订单合并为一个购物篮
+	var basket []uint
+	var lastItem repository.BasketItem
+	err := s.orderRepo.ScanPaidItems(time.Now().Add(-recommendOrderWindow), func(item repository.BasketItem) error {
+		if len(basket) > 0 && (item.UserID != lastItem.UserID || item.CreatedAt.Sub(lastItem.CreatedAt) > basketSessionGap) {
+			counter.add(basket)
+			basket = basket[:0]
+		}
+		basket = append(basket, item.ProductID)
+		lastItem = item
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	counter.add(basket)
+
+	// 购物车：每个用户的购物车为一个购物篮
+	basket = basket[:0]
+	var lastUserID uint
+	err = s.cartRepo.ScanItems(func(item repository.BasketItem) error {
+		if len(basket) > 0 && item.UserID != lastUserID {
+			counter.add(basket)
+			basket = basket[:0]
+		}
+		basket = append(basket, item.ProductID)
+		lastUserID = item.UserID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	counter.add(basket)
+
+	recommendations := counter.recommendations(recommendMinSupport, recommendTopK)
+	if err := s.recommendRepo.ReplaceAll(recommendations); err != nil {
+		return nil, err
+	}
+	return &RecommendResult{
+		Baskets:         counter.baskets,
+		Pairs:           len(counter.pairs),
+		Recommendations: len(recommendations),
+	}, nil
+}
+
+// productPair 商品对，a < b
+type productPair struct {
+	a, b uint
+}
+
+// cooccurrenceCounter 统计商品出现次数和商品对共现次数
+type cooccurrenceCounter struct {
+	baskets int
+	items   map[uint]int
+	pairs   map[productPair]int
+}
+
+func newCooccurrenceCounter() *cooccurrenceCounter {
+	return &cooccurrenceCounter{
+		items: make(map[uint]int),
+		pairs: make(map[productPair]int),
+	}
+}
+
+// add 统计一个购物篮，同一商品在购物篮中只计一次
+func (c *cooccurrenceCounter) add(basket []uint) {
+	if len(basket) == 0 {
+		return
+	}
+
+	seen := make(map[uint]bool, len(basket))
+	unique := make([]uint, 0, len(basket))
+	for _, id := range basket {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > maxBasketSize {
+		unique = unique[:maxBasketSize]
+	}
+	sort.Slice(unique, func(i, j int) bool { return unique[i] < unique[j] })
+
+	c.baskets++
+	for i, a := range unique {
+		c.items[a]++
+		for _, b := range unique[i+1:] {
+			c.pairs[productPair{a, b}]++
+		}
+	}
+}
+
+// recommendations 生成推荐结果：共现次数不少于 minSupport 的商品对双向写入，
+// 按余弦相似度 count / sqrt(count(a) * count(b)) 排序，每个商品保留前 topK 个
+func (c *cooccurrenceCounter) recommendations(minSupport, topK int) []model.ProductRecommendation {
+	byProduct := make(map[uint][]model.ProductRecommendation)
+	for pair, count := range c.pairs {
+		if count < minSupport {
+			continue
+		}
+		score := float64(count) / math.Sqrt(float64(c.items[pair.a])*float64(c.items[pair.b]))
+		byProduct[pair.a] = append(byProduct[pair.a], model.ProductRecommendation{
+			ProductID: pair.a, RecommendedID: pair.b, CoCount: count, Score: score,
+		})
+		byProduct[pair.b] = append(byProduct[pair.b], model.ProductRecommendation{
+			ProductID: pair.b, RecommendedID: pair.a, CoCount: count, Score: score,
+		})
+	}
+
+	var result []model.ProductRecommendation
+	for _, list := range byProduct {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score > list[j].Score
+			}
+			return list[i].CoCount > list[j].CoCount
+		})
+		if len(list) > topK {
+			list = list[:topK]
+		}
+		result = append(result, list...)
+	}
+	return result
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/repository"
+)
+
+// 角色权限（RBAC）
+// 用户的角色ID（User.Role）在登录时写入 JWT，接口通过 middleware.RequirePermission 校验权限：
+// - 超级管理员拥有全部权限，普通用户没有任何后台权限
+// - 其他角色的权限来自 role_permissions，进程内缓存 permissionCacheTTL，
+//   本实例修改权限后立即失效，其他实例最迟 permissionCacheTTL 后生效
+// 修改用户角色时递增其 Token 版本，已签发的 Token 立即失效，重新登录后按新角色签发。
+// 只有超级管理员可以授予超级管理员角色或修改超级管理员的角色，角色变更写入 admin_audit_logs
+
+var (
+	// ErrRoleImmutable 内置的超级管理员和普通用户角色不允许修改权限
+	ErrRoleImmutable = errors.New("超级管理员和普通用户角色不能修改权限")
+	// ErrUnknownPermission 权限编码不存在
+	ErrUnknownPermission = errors.New("权限不存在")
+	// ErrRoleExists 角色编码已存在
+	ErrRoleExists = errors.New("角色编码已存在")
+	// ErrChangeOwnRole 不能修改自己的角色，避免管理员误操作失去权限
+	ErrChangeOwnRole = errors.New("不能修改自己的角色")
+)
+
+// permissionCacheTTL 角色权限缓存时间
+const permissionCacheTTL = 30 * time.Second
+
+// permissionCache 角色权限的进程内缓存
+var permissionCache struct {
+	sync.RWMutex
+	perms    map[uint]map[string]bool
+	loadedAt time.Time
+}
+
+// RoleService 角色权限服务
+type RoleService struct {
+	roleRepo *repository.RoleRepository
+	userRepo *repository.UserRepository
+}
+
+// NewRoleService 创建角色权限服务实例
+func NewRoleService() *RoleService {
+	return &RoleService{
+		roleRepo: repository.NewRoleRepository(),
+		userRepo: repository.NewUserRepository(),
+	}
+}
+
+// RoleResponse 角色及其权限
+type RoleResponse struct {
+	model.Role
+	Permissions []string `json:"permissions"`
+}
+
+// CreateRoleRequest 创建角色请求
+type CreateRoleRequest struct {
+	Code        string   `json:"code" binding:"required,max=50"`
+	Name        string   `json:"name" binding:"required,max=50"`
+	Description string   `json:"description" binding:"max=200"`
+	Permissions []string `json:"permissions"`
+}
+
+// SetRolePermissionsRequest 设置角色权限请求
+type SetRolePermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+// AssignRoleRequest 分配用户角色请求
+type AssignRoleRequest struct {
+	RoleID uint `json:"role_id" binding:"required"`
+}
+
+// HasPermission 判断角色是否拥有权限
+func HasPermission(role int, code string) (bool, error) {
+	switch role {
+	case model.RoleAdmin:
+		return true, nil
+	case 0, model.RoleUser:
+		return false, nil
+	}
+
+	permissionCache.RLock()
+	perms, loadedAt := permissionCache.perms, permissionCache.loadedAt
+	permissionCache.RUnlock()
+
+	if perms == nil || time.Since(loadedAt) > permissionCacheTTL {
+		var err error
+		if perms, err = loadPermissionCache(); err != nil {
+			return false, err
+		}
+	}
+	return perms[uint(role)][code], nil
+}
+
+// loadPermissionCache 从数据库重新加载角色权限
+func loadPermissionCache() (map[uint]map[string]bool, error) {
+	codes, err := repository.NewRoleRepository().PermissionCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	perms := make(map[uint]map[string]bool, len(codes))
+	for roleID, list := range codes {
+		perms[roleID] = make(map[string]bool, len(list))
+		for _, code := range list {
+			perms[roleID][code] = true
+		}
+	}
+
+	permissionCache.Lock()
+	permissionCache.perms = perms
+	permissionCache.loadedAt = time.Now()
+	permissionCache.Unlock()
+	return perms, nil
+}
+
+// invalidatePermissionCache 权限变更后清空本实例缓存
+func invalidatePermissionCache() {
+	permissionCache.Lock()
+	permissionCache.perms = nil
+	permissionCache.Unlock()
+}
+
+// ListRoles 获取全部角色及其权限
+func (s *RoleService) ListRoles() ([]RoleResponse, error) {
+	roles, err := s.roleRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	codes, err := s.roleRepo.PermissionCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]RoleResponse, len(roles))
+	for i, role := range roles {
+		permissions := codes[role.ID]
+		if role.ID == model.RoleAdmin {
+			permissions = []string{"*"}
+		}
+		if permissions == nil {
+			permissions = []string{}
+		}
+		responses[i] = RoleResponse{Role: role, Permissions: permissions}
+	}
+	return responses, nil
+}
+
+// ListPermissions 获取全部权限
+func (s *RoleService) ListPermissions() ([]model.Permission, error) {
+	return s.roleRepo.ListPermissions()
+}
+
+// CreateRole 创建角色
+func (s *RoleService) CreateRole(req *CreateRoleRequest) (*RoleResponse, error) {
+	roles, err := s.roleRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		if role.Code == req.Code {
+			return nil, ErrRoleExists
+		}
+	}
+
+	permissionIDs, err := s.permissionIDs(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &model.Role{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := s.roleRepo.Create(role, permissionIDs); err != nil {
+		return nil, err
+	}
+	invalidatePermissionCache()
+
+	permissions := req.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return &RoleResponse{Role: *role, Permissions: permissions}, nil
+}
+
+// SetRolePermissions 整体替换角色的权限
+func (s *RoleService) SetRolePermissions(roleID uint, req *SetRolePermissionsRequest) error {
+	if roleID == model.RoleAdmin || roleID == model.RoleUser {
+		return ErrRoleImmutable
+	}
+	if _, err := s.roleRepo.GetByID(roleID); err != nil {
+		return err
+	}
+
+	permissionIDs, err := s.permissionIDs(req.Permissions)
+	if err != nil {
+		return err
+	}
+	if err := s.roleRepo.SetPermissions(roleID, permissionIDs); err != nil {
+		return err
+	}
+	invalidatePermissionCache()
+	return nil
+}
+
+// AssignRole 修改用户角色，op 为执行操作的管理员
+func (s *RoleService) AssignRole(ctx context.Context, op AdminOperator, userID uint, req *AssignRoleRequest) error {
+	if op.UserID == userID {
+		return ErrChangeOwnRole
+	}
+	if _, err := s.roleRepo.GetByID(req.RoleID); err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	to := int(req.RoleID)
+	if op.Role != model.RoleAdmin && (to == model.RoleAdmin || user.Role == model.RoleAdmin) {
+		return ErrAdminTargetForbidden
+	}
+	if user.Role == to {
+		return nil
+	}
+
+	changed, err := s.userRepo.ChangeRole(userID, user.Role, to, &model.AdminAuditLog{
+		OperatorID: op.UserID,
+		Action:     model.AuditActionUserRole,
+		TargetType: model.AuditTargetUser,
+		TargetID:   userID,
+		Detail:     fmt.Sprintf("role %d -> %d", user.Role, to),
+		IP:         op.IP,
+	})
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return ErrUserStatusConflict
+	}
+	_, err = RevokeAllTokens(ctx, userID)
+	return err
+}
+
+// permissionIDs 将权限编码转换为ID，重复的编码只保留一个
+func (s *RoleService) permissionIDs(codes []string) ([]uint, error) {
+	permissions, err := s.roleRepo.ListPermissions()
+	if err != nil {
+		return nil, err
+	}
+	idByCode := make(map[string]uint, len(permissions))
+	for _, p := range permissions {
+		idByCode[p.Code] = p.ID
+	}
+
+	seen := make(map[uint]bool, len(codes))
+	ids := make([]uint, 0, len(codes))
+	for _, code := range codes {
+		id, ok := idByCode[code]
+		if !ok {
+			return nil, ErrUnknownPermission
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
@@ -65,15 +65,19 @@ func (s *SeckillService) SeckillWithRedis(ctx context.Context, userID uint, req
 	productID := req.ProductID
 
 	// 1. 获取商品信息 (为了检查状态和价格)
-	product, err := s.productRepo.GetByID(productID)
+	// 秒杀瞬时流量集中在少数商品上，走二级缓存；库存以 Redis 预热库存为准
+	product, err := s.productRepo.GetByIDWithCache(productID)
 	if err != nil {
 		return nil, err
 	}
 
 	// 2. 检查商品状态
-	if product.Status != 1 {
+	if !product.IsOnShelf(time.Now()) {
 		return nil, errors.New("商品已下架")
 	}
+	if err := ensureShopOpen(product); err != nil {
+		return nil, err
+	}
 
 	// 3. 检查用户是否重复秒杀（使用Redis set）
 	userKey := fmt.Sprintf("seckill:user:%d:%d", userID, productID)
@@ -173,6 +177,22 @@ func (s *SeckillService) ProcessSeckillOrders() {
 			return err // 返回错误，MQ会重试
 		}
 
+		// 店铺可能在抢到库存后被停业：不再下单，退回 Redis 库存并清除用户秒杀记录
+		if err := ensureShopOpen(product); err != nil {
+			if !errors.Is(err, ErrShopNotOpen) {
+				logger.Error("检查店铺状态失败", zap.Uint("shop_id", product.ShopID), zap.Error(err))
+				return err // 返回错误，MQ会重试
+			}
+			logger.Warn("店铺未营业，放弃秒杀订单",
+				zap.Uint("user_id", msg.UserID),
+				zap.Uint("product_id", msg.ProductID),
+				zap.Uint("shop_id", product.ShopID),
+			)
+			incrStock(ctx, msg.ProductID, 1)
+			redis.Client.Del(ctx, fmt.Sprintf("seckill:user:%d:%d", msg.UserID, msg.ProductID))
+			return nil
+		}
+
 		// 3. 生成订单号
 		orderNo := generateOrderNo()
 
@@ -181,12 +201,14 @@ func (s *SeckillService) ProcessSeckillOrders() {
 			OrderNo:      orderNo,
 			UserID:       msg.UserID,
 			ProductID:    msg.ProductID,
+			ShopID:       product.ShopID,
 			ProductName:  product.Name,
 			ProductImage: product.ImageURL,
 			Quantity:     1,
 			TotalPrice:   product.Price,
 			Status:       1, // 待支付
 			PayType:      1,
+			OrderType:    model.OrderTypeSeckill,
 		}
 
 		// 5. 写入数据库 (真正的落库操作)
@@ -30,9 +30,11 @@ import (
 	"gomall/backend/internal/rabbitmq"   // RabbitMQ消息队列
 	"gomall/backend/internal/redis"      // Redis缓存
 	"gomall/backend/internal/repository" // 数据访问层
+	"gomall/backend/internal/security"   // 安全工具（富文本清洗）
 	"gomall/backend/pkg/jwt"             // JWT工具包
 	"gomall/backend/pkg/password"        // 密码工具包
 	"log"                                // 日志
+	"strings"                            // 字符串处理
 	"time"                               // 时间处理
 
 	"gorm.io/gorm" // GORM ORM框架
@@ -56,6 +58,12 @@ var ErrInvalidPassword = errors.New("密码错误")
  */
 var ErrUserDisabled = errors.New("用户已被禁用")
 
+/**
+ * ErrUsernameReserved 用户名使用了保留前缀
+ * deleted_ 前缀留给已注销账号的占位用户名，软删除的记录仍占用用户名唯一索引
+ */
+var ErrUsernameReserved = errors.New("用户名不能以 " + repository.DeletedUsernamePrefix + " 开头")
+
 /**
  * ==================== UserService 用户服务 ====================
  *
@@ -139,10 +147,40 @@ type UserResponse struct {
 	ID uint `json:"id"`
 	// Username 用户名
 	Username string `json:"username"`
+	// Nickname 昵称
+	Nickname string `json:"nickname"`
+	// Avatar 头像URL
+	Avatar string `json:"avatar"`
 	// Email 邮箱
 	Email string `json:"email"`
 	// Phone 手机号
 	Phone string `json:"phone"`
+	// Role 角色ID
+	Role int `json:"role"`
+	// Status 账号状态：1 正常 2 已禁用 3 已锁定（需重置密码）
+	Status int `json:"status"`
+	// EmailVerified 邮箱是否已验证
+	EmailVerified bool `json:"email_verified"`
+	// PhoneVerified 手机号是否已验证
+	PhoneVerified bool `json:"phone_verified"`
+}
+
+/**
+ * newUserResponse 将用户模型转换为返回给前端的用户信息
+ */
+func newUserResponse(user *model.User) *UserResponse {
+	return &UserResponse{
+		ID:            user.ID,
+		Username:      user.Username,
+		Nickname:      user.Nickname,
+		Avatar:        user.Avatar,
+		Email:         user.Email,
+		Phone:         user.Phone,
+		Role:          user.Role,
+		Status:        user.Status,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		PhoneVerified: user.PhoneVerifiedAt != nil,
+	}
 }
 
 /**
@@ -153,6 +191,7 @@ type UserResponse struct {
  * 2. 检查邮箱是否已注册
  * 3. 对密码进行加密
  * 4. 创建用户记录
+ * 5. 发送邮箱验证邮件
  *
  * 参数：
  *   req *RegisterRequest - 注册请求
@@ -162,6 +201,15 @@ type UserResponse struct {
  *   error - 错误信息（用户已存在、邮箱已注册等）
  */
 func (s *UserService) Register(req *RegisterRequest) (*UserResponse, error) {
+	// 用户名和邮箱的唯一索引不区分大小写，保留前缀和保留域名也按小写比较
+	if strings.HasPrefix(strings.ToLower(req.Username), repository.DeletedUsernamePrefix) {
+		return nil, ErrUsernameReserved
+	}
+	email := strings.ToLower(req.Email)
+	if IsPlaceholderEmail(email) || strings.HasSuffix(email, "@"+repository.DeletedEmailDomain) {
+		return nil, ErrEmailTaken
+	}
+
 	// 1. 检查用户名是否已存在
 	// 使用下划线忽略返回的错误，因为只需要判断是否存在
 	existUser, _ := s.userRepo.GetByUsername(req.Username)
@@ -190,6 +238,8 @@ func (s *UserService) Register(req *RegisterRequest) (*UserResponse, error) {
 		Password: hashedPassword,
 		Email:    req.Email,
 		Phone:    req.Phone,
+		Role:     model.RoleUser,
+		Status:   model.UserStatusActive,
 	}
 
 	// 调用Repository创建用户记录
@@ -197,85 +247,88 @@ func (s *UserService) Register(req *RegisterRequest) (*UserResponse, error) {
 		return nil, errors.New("用户创建失败")
 	}
 
+	// 异步发送邮箱验证邮件，发送失败不影响注册，用户可稍后重新发送
+	NewEmailService().sendVerificationAsync(user.ID)
+
 	// 5. 返回创建成功的用户信息
-	return &UserResponse{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		Phone:    user.Phone,
-	}, nil
+	return newUserResponse(user), nil
 }
 
 /**
  * Login 用户登录
  *
  * 登录流程：
- * 1. 根据用户名获取用户
- * 2. 验证密码是否正确
- * 3. 生成JWT Token
+ * 1. 检查客户端IP是否因失败次数过多被锁定
+ * 2. 根据用户名获取用户，检查账号是否被锁定
+ * 3. 验证密码是否正确，失败时计入账号和IP的失败次数（见 login_guard.go）
+ * 4. 生成JWT Token对（访问令牌 + 刷新令牌）；启用了两步验证时只返回预认证Token（见 two_factor.go）
  *
  * 参数：
+ *   ctx context.Context - 上下文
  *   req *LoginRequest - 登录请求
+ *   client ClientInfo - 客户端IP和 User-Agent，记录到登录会话
  *
  * 返回值：
- *   string - 访问令牌（AccessToken）
- *   *UserResponse - 用户信息
- *   error - 错误信息（用户不存在、密码错误等）
+ *   *LoginResult - Token对和用户信息，或两步验证挑战
+ *   error - 错误信息（用户不存在、密码错误、*LoginLockedError 等）
  */
-func (s *UserService) Login(req *LoginRequest) (string, *UserResponse, error) {
-	// 1. 获取用户
+func (s *UserService) Login(ctx context.Context, req *LoginRequest, client ClientInfo) (*LoginResult, error) {
+	guard := newLoginGuard()
+
+	// 1. 检查IP锁定
+	if err := guard.check(ctx, 0, client.IP); err != nil {
+		return nil, err
+	}
+
+	// 2. 获取用户并检查账号锁定
 	user, err := s.userRepo.GetByUsername(req.Username)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
-			return "", nil, errors.New("用户不存在")
+			if _, lockErr := guard.fail(ctx, 0, client.IP); lockErr != nil {
+				return nil, lockErr
+			}
+			return nil, errors.New("用户不存在")
 		}
-		return "", nil, err
+		return nil, err
+	}
+	if err := guard.check(ctx, user.ID, client.IP); err != nil {
+		return nil, err
 	}
 
-	// 2. 验证密码
+	// 3. 验证密码
 	// CheckPassword会比较明文密码和加密后的密码
 	if !password.CheckPassword(req.Password, user.Password) {
-		return "", nil, ErrInvalidPassword
-	}
-
-	// 3. 生成JWT Token
-	// 使用jwt包生成访问令牌和刷新令牌
-	jwtUtil := jwt.NewJWT()
-	tokenPair, err := jwtUtil.GenerateTokenPair(user.ID, user.Username, user.Email)
-	if err != nil {
-		return "", nil, errors.New("Token生成失败")
+		remaining, lockErr := guard.fail(ctx, user.ID, client.IP)
+		if lockErr != nil {
+			return nil, lockErr
+		}
+		if remaining > 0 {
+			return nil, fmt.Errorf("%w，还可尝试%d次", ErrInvalidPassword, remaining)
+		}
+		return nil, ErrInvalidPassword
 	}
+	guard.succeed(ctx, user.ID)
 
-	// 返回访问令牌和用户信息
-	return tokenPair.AccessToken, &UserResponse{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		Phone:    user.Phone,
-	}, nil
+	// 4. 生成JWT Token
+	// 刷新令牌属于本次登录新建的轮换家族；启用了两步验证时需再提交动态验证码
+	return NewTwoFactorService().completeLogin(ctx, user, client)
 }
 
 /**
- * RefreshToken 刷新访问令牌
+ * RefreshToken 刷新Token
  *
- * 使用刷新令牌获取新的访问令牌。
+ * 使用刷新令牌换发新的Token对，旧的刷新令牌随即失效（轮换）。
+ * 已轮换的刷新令牌再次使用时，本次登录的全部刷新令牌都会被吊销。
  *
  * 参数：
  *   refreshToken string - 刷新令牌
  *
  * 返回值：
- *   string - 新的访问令牌
+ *   *jwt.TokenPair - 新的访问令牌和刷新令牌
  *   error - 错误信息
  */
-func (s *UserService) RefreshToken(refreshToken string) (string, error) {
-	jwtUtil := jwt.NewJWT()
-	claims, err := jwtUtil.ParseToken(refreshToken)
-	if err != nil {
-		return "", errors.New("无效的刷新Token")
-	}
-
-	// 使用原claims生成新的访问令牌
-	return jwtUtil.GenerateToken(claims.UserID, claims.Username, claims.Email)
+func (s *UserService) RefreshToken(refreshToken string) (*jwt.TokenPair, error) {
+	return RotateRefreshToken(context.Background(), refreshToken)
 }
 
 /**
@@ -285,6 +338,7 @@ func (s *UserService) RefreshToken(refreshToken string) (string, error) {
  * 1. 获取用户信息
  * 2. 加密新密码
  * 3. 更新用户密码
+ * 4. 递增 Token 版本，已签发的 Token 全部失效
  *
  * 参数：
  *   userID uint - 用户ID
@@ -308,7 +362,13 @@ func (s *UserService) ChangePassword(userID uint, newPassword string) error {
 
 	// 3. 更新密码
 	user.Password = hashedPassword
-	return s.userRepo.UpdatePassword(user)
+	if err := s.userRepo.UpdatePassword(user); err != nil {
+		return err
+	}
+
+	// 4. 使已签发的 Token 全部失效
+	_, err = RevokeAllTokens(context.Background(), userID)
+	return err
 }
 
 /**
@@ -327,12 +387,7 @@ func (s *UserService) GetUserByID(id uint) (*UserResponse, error) {
 		return nil, err
 	}
 
-	return &UserResponse{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		Phone:    user.Phone,
-	}, nil
+	return newUserResponse(user), nil
 }
 
 /**
@@ -344,9 +399,13 @@ func (s *UserService) GetUserByID(id uint) (*UserResponse, error) {
  * - 商品详情查询
  * - 商品更新
  * - 商品删除
+ * - 商品图集管理（见 product_image.go）
+ * - 商品排行榜（见 product_rank.go）
  */
 type ProductService struct {
 	productRepo *repository.ProductRepository
+	imageRepo   *repository.ProductImageRepository
+	orderRepo   *repository.OrderRepository
 }
 
 /**
@@ -355,6 +414,8 @@ type ProductService struct {
 func NewProductService() *ProductService {
 	return &ProductService{
 		productRepo: repository.NewProductRepository(),
+		imageRepo:   repository.NewProductImageRepository(),
+		orderRepo:   repository.NewOrderRepository(),
 	}
 }
 
@@ -364,10 +425,15 @@ func NewProductService() *ProductService {
 type CreateProductRequest struct {
 	Name        string  `json:"name" binding:"required"`
 	Description string  `json:"description"`
+	Detail      string  `json:"detail"` // 富文本详情，保存前清洗
 	Price       float64 `json:"price" binding:"required,gt=0"`
 	Stock       int     `json:"stock" binding:"gte=0"`
 	Category    string  `json:"category"`
 	ImageURL    string  `json:"image_url"`
+
+	// 定时上下架（可选），上架时间在未来时商品先保持下架
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
 }
 
 /**
@@ -376,55 +442,97 @@ type CreateProductRequest struct {
 type UpdateProductRequest struct {
 	Name        string  `json:"name"`
 	Description string  `json:"description"`
+	Detail      string  `json:"detail"`
 	Price       float64 `json:"price"`
 	Stock       int     `json:"stock"`
 	Category    string  `json:"category"`
 	ImageURL    string  `json:"image_url"`
 	Status      int     `json:"status"`
+
+	// PriceChangeReason 调价原因，价格变化时写入价格变更记录
+	PriceChangeReason string `json:"price_change_reason" binding:"max=255"`
+
+	// 定时上下架：传入则覆盖原计划，ClearSchedule 为 true 时取消全部计划
+	PublishAt     *time.Time `json:"publish_at"`
+	UnpublishAt   *time.Time `json:"unpublish_at"`
+	ClearSchedule bool       `json:"clear_schedule"`
 }
 
 /**
  * ProductResponse 商品响应结构
+ *
+ * Detail 和 Images 只在详情接口返回，列表接口省略以减小响应体积。
+ * Status 为按定时计划计算后的实际状态。
  */
 type ProductResponse struct {
-	ID          uint    `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Stock       int     `json:"stock"`
-	Category    string  `json:"category"`
-	ImageURL    string  `json:"image_url"`
-	Status      int     `json:"status"`
-	CreatedAt   string  `json:"created_at"`
+	ID          uint                   `json:"id"`
+	ShopID      uint                   `json:"shop_id"` // 所属店铺，0 为平台自营
+	SKU         string                 `json:"sku,omitempty"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Detail      string                 `json:"detail,omitempty"`
+	Price       float64                `json:"price"`
+	LowestPrice float64                `json:"lowest_price_30d,omitempty"` // 近30天最低价，仅详情返回
+	Stock       int                    `json:"stock"`
+	Category    string                 `json:"category"`
+	ImageURL    string                 `json:"image_url"`
+	Images      []ProductImageResponse `json:"images,omitempty"`
+	Status      int                    `json:"status"`
+	PublishAt   *time.Time             `json:"publish_at,omitempty"`
+	UnpublishAt *time.Time             `json:"unpublish_at,omitempty"`
+	CreatedAt   string                 `json:"created_at"`
+
+	FavoriteCount int   `json:"favorite_count"`
+	IsFavorite    *bool `json:"is_favorite,omitempty"` // 当前用户是否已收藏，仅登录用户查看详情时返回
 }
 
 /**
- * Create 创建商品
+ * Create 创建商品（平台自营）
  */
 func (s *ProductService) Create(req *CreateProductRequest) (*ProductResponse, error) {
+	return s.createInShop(0, req)
+}
+
+/**
+ * createInShop 创建商品，shopID 为所属店铺，0 表示平台自营
+ */
+func (s *ProductService) createInShop(shopID uint, req *CreateProductRequest) (*ProductResponse, error) {
 	product := &model.Product{
+		ShopID:      shopID,
 		Name:        req.Name,
 		Description: req.Description,
+		Detail:      security.SanitizeHTML(req.Detail),
 		Price:       req.Price,
 		Stock:       req.Stock,
 		Category:    req.Category,
 		ImageURL:    req.ImageURL,
 		Status:      1, // 默认上架
 	}
+	if err := applySchedule(product, req.PublishAt, req.UnpublishAt, time.Now()); err != nil {
+		return nil, err
+	}
 
 	if err := s.productRepo.Create(product); err != nil {
 		return nil, errors.New("商品创建失败")
 	}
+	// 清除可能存在的空值占位，并写入布隆过滤器
+	s.productRepo.InvalidateCache(product.ID)
+	s.productRepo.InvalidateListCache()
+	s.addToBloom(product.ID)
 
 	return &ProductResponse{
 		ID:          product.ID,
+		ShopID:      product.ShopID,
 		Name:        product.Name,
 		Description: product.Description,
+		Detail:      product.Detail,
 		Price:       product.Price,
 		Stock:       product.Stock,
 		Category:    product.Category,
 		ImageURL:    product.ImageURL,
 		Status:      product.Status,
+		PublishAt:   product.PublishAt,
+		UnpublishAt: product.UnpublishAt,
 		CreatedAt:   product.CreatedAt.Format("2006-01-02 15:04:05"),
 	}, nil
 }
@@ -432,21 +540,28 @@ func (s *ProductService) Create(req *CreateProductRequest) (*ProductResponse, er
 /**
  * GetList 获取商品列表
  */
-func (s *ProductService) GetList(page, pageSize int, category string) ([]ProductResponse, int64) {
-	products, total := s.productRepo.GetList(page, pageSize, category)
+func (s *ProductService) GetList(page, pageSize int, category string, shopID uint) ([]ProductResponse, int64) {
+	products, total := s.productRepo.GetListWithCache(page, pageSize, category, shopID)
+	now := time.Now()
 
 	responses := make([]ProductResponse, len(products))
 	for i, p := range products {
 		responses[i] = ProductResponse{
 			ID:          p.ID,
+			ShopID:      p.ShopID,
+			SKU:         productSKU(&p),
 			Name:        p.Name,
 			Description: p.Description,
 			Price:       p.Price,
 			Stock:       p.Stock,
 			Category:    p.Category,
 			ImageURL:    p.ImageURL,
-			Status:      p.Status,
+			Status:      p.EffectiveStatus(now),
+			PublishAt:   p.PublishAt,
+			UnpublishAt: p.UnpublishAt,
 			CreatedAt:   p.CreatedAt.Format("2006-01-02 15:04:05"),
+
+			FavoriteCount: p.FavoriteCount,
 		}
 	}
 
@@ -455,34 +570,51 @@ func (s *ProductService) GetList(page, pageSize int, category string) ([]Product
 
 /**
  * GetByID 根据ID获取商品
+ *
+ * 详情接口返回富文本详情和完整图集。
+ * 商品、图集和近30天最低价整体走读穿缓存（见 repository/product_detail_cache.go），
+ * 实际上下架状态按当前时间计算，不随缓存过期。
  */
 func (s *ProductService) GetByID(id uint) (*ProductResponse, error) {
-	product, err := s.productRepo.GetByID(id)
+	detail, err := s.productRepo.GetDetailWithCache(id, lowestPriceWindow)
 	if err != nil {
 		return nil, err
 	}
+	product := &detail.Product
 
 	return &ProductResponse{
 		ID:          product.ID,
+		ShopID:      product.ShopID,
+		SKU:         productSKU(product),
 		Name:        product.Name,
 		Description: product.Description,
+		Detail:      product.Detail,
 		Price:       product.Price,
+		LowestPrice: lowestPrice30d(product.Price, detail.LowestPrice),
 		Stock:       product.Stock,
 		Category:    product.Category,
 		ImageURL:    product.ImageURL,
-		Status:      product.Status,
+		Images:      toProductImageResponses(detail.Images),
+		Status:      product.EffectiveStatus(time.Now()),
+		PublishAt:   product.PublishAt,
+		UnpublishAt: product.UnpublishAt,
 		CreatedAt:   product.CreatedAt.Format("2006-01-02 15:04:05"),
+
+		FavoriteCount: product.FavoriteCount,
 	}, nil
 }
 
 /**
  * Update 更新商品
+ *
+ * 价格变化时在同一事务中写入价格变更记录，operatorID 为操作人。
  */
-func (s *ProductService) Update(id uint, req *UpdateProductRequest) error {
+func (s *ProductService) Update(id, operatorID uint, req *UpdateProductRequest) error {
 	product, err := s.productRepo.GetByID(id)
 	if err != nil {
 		return err
 	}
+	oldPrice := product.Price
 
 	// 更新字段
 	if req.Name != "" {
@@ -491,6 +623,9 @@ func (s *ProductService) Update(id uint, req *UpdateProductRequest) error {
 	if req.Description != "" {
 		product.Description = req.Description
 	}
+	if req.Detail != "" {
+		product.Detail = security.SanitizeHTML(req.Detail)
+	}
 	if req.Price > 0 {
 		product.Price = req.Price
 	}
@@ -506,15 +641,36 @@ func (s *ProductService) Update(id uint, req *UpdateProductRequest) error {
 	if req.Status > 0 {
 		product.Status = req.Status
 	}
+	if req.ClearSchedule {
+		product.PublishAt = nil
+		product.UnpublishAt = nil
+	}
+	if err := applySchedule(product, req.PublishAt, req.UnpublishAt, time.Now()); err != nil {
+		return err
+	}
 
-	return s.productRepo.Update(product)
+	history := newPriceHistory(oldPrice, product.Price, operatorID, req.PriceChangeReason)
+	if err := s.productRepo.UpdateWithPriceHistory(product, history); err != nil {
+		return err
+	}
+
+	// 先更新数据库再删除缓存
+	s.productRepo.InvalidateCache(id)
+	s.productRepo.InvalidateListCache()
+	return nil
 }
 
 /**
  * Delete 删除商品
  */
 func (s *ProductService) Delete(id uint) error {
-	return s.productRepo.Delete(id)
+	if err := s.productRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.productRepo.InvalidateCache(id)
+	s.productRepo.InvalidateListCache()
+	return nil
 }
 
 /**
@@ -566,6 +722,7 @@ type OrderResponse struct {
 	OrderNo      string  `json:"order_no"`
 	UserID       uint    `json:"user_id"`
 	ProductID    uint    `json:"product_id"`
+	ShopID       uint    `json:"shop_id"`
 	ProductName  string  `json:"product_name"`
 	ProductImage string  `json:"product_image"`
 	Quantity     int     `json:"quantity"`
@@ -573,6 +730,37 @@ type OrderResponse struct {
 	Status       int     `json:"status"`
 	PayType      int     `json:"pay_type"`
 	CreatedAt    string  `json:"created_at"`
+
+	OrderType int  `json:"order_type"`
+	ParentID  uint `json:"parent_id"`
+
+	// 以下字段只在订单详情和结算结果中返回
+	ParentOrderNo string              `json:"parent_order_no,omitempty"` // 子订单所属父订单号，支付、查询支付状态使用
+	Children      []OrderResponse     `json:"children,omitempty"`        // 父订单的店铺子订单
+	Items         []OrderItemResponse `json:"items,omitempty"`           // 子订单的商品明细
+}
+
+/**
+ * newOrderResponse 订单模型转换为响应结构
+ */
+func newOrderResponse(order *model.Order) OrderResponse {
+	return OrderResponse{
+		ID:           order.ID,
+		OrderNo:      order.OrderNo,
+		UserID:       order.UserID,
+		ProductID:    order.ProductID,
+		ShopID:       order.ShopID,
+		ProductName:  order.ProductName,
+		ProductImage: order.ProductImage,
+		Quantity:     order.Quantity,
+		TotalPrice:   order.TotalPrice,
+		Status:       order.Status,
+		PayType:      order.PayType,
+		CreatedAt:    order.CreatedAt.Format("2006-01-02 15:04:05"),
+
+		OrderType: order.OrderType,
+		ParentID:  order.ParentID,
+	}
 }
 
 /**
@@ -603,10 +791,13 @@ func (s *OrderService) CreateOrder(userID uint, req *CreateOrderRequest) (*Order
 		return nil, err
 	}
 
-	// 2. 检查商品状态
-	if product.Status != 1 {
+	// 2. 检查商品状态和所属店铺
+	if !product.IsOnShelf(time.Now()) {
 		return nil, errors.New("商品已下架")
 	}
+	if err := ensureShopOpen(product); err != nil {
+		return nil, err
+	}
 
 	// 3. 使用Redis库存预检
 	ctx := context.Background()
@@ -649,6 +840,7 @@ func (s *OrderService) CreateOrder(userID uint, req *CreateOrderRequest) (*Order
 		OrderNo:      orderNo,
 		UserID:       userID,
 		ProductID:    product.ID,
+		ShopID:       product.ShopID,
 		ProductName:  product.Name,
 		ProductImage: product.ImageURL,
 		Quantity:     req.Quantity,
@@ -668,9 +860,12 @@ func (s *OrderService) CreateOrderSync(userID uint, req *CreateOrderRequest) (*O
 		return nil, err
 	}
 
-	if product.Status != 1 {
+	if !product.IsOnShelf(time.Now()) {
 		return nil, errors.New("商品已下架")
 	}
+	if err := ensureShopOpen(product); err != nil {
+		return nil, err
+	}
 
 	if product.Stock < req.Quantity {
 		return nil, repository.ErrInsufficientStock
@@ -683,6 +878,7 @@ func (s *OrderService) CreateOrderSync(userID uint, req *CreateOrderRequest) (*O
 		OrderNo:      orderNo,
 		UserID:       userID,
 		ProductID:    product.ID,
+		ShopID:       product.ShopID,
 		ProductName:  product.Name,
 		ProductImage: product.ImageURL,
 		Quantity:     req.Quantity,
@@ -698,19 +894,8 @@ func (s *OrderService) CreateOrderSync(userID uint, req *CreateOrderRequest) (*O
 		return nil, errors.New("订单创建失败")
 	}
 
-	return &OrderResponse{
-		ID:           order.ID,
-		OrderNo:      order.OrderNo,
-		UserID:       order.UserID,
-		ProductID:    order.ProductID,
-		ProductName:  order.ProductName,
-		ProductImage: order.ProductImage,
-		Quantity:     order.Quantity,
-		TotalPrice:   order.TotalPrice,
-		Status:       order.Status,
-		PayType:      order.PayType,
-		CreatedAt:    order.CreatedAt.Format("2006-01-02 15:04:05"),
-	}, nil
+	response := newOrderResponse(order)
+	return &response, nil
 }
 
 /**
@@ -721,19 +906,7 @@ func (s *OrderService) GetOrderList(userID uint, page, pageSize int) ([]OrderRes
 
 	responses := make([]OrderResponse, len(orders))
 	for i, o := range orders {
-		responses[i] = OrderResponse{
-			ID:           o.ID,
-			OrderNo:      o.OrderNo,
-			UserID:       o.UserID,
-			ProductID:    o.ProductID,
-			ProductName:  o.ProductName,
-			ProductImage: o.ProductImage,
-			Quantity:     o.Quantity,
-			TotalPrice:   o.TotalPrice,
-			Status:       o.Status,
-			PayType:      o.PayType,
-			CreatedAt:    o.CreatedAt.Format("2006-01-02 15:04:05"),
-		}
+		responses[i] = newOrderResponse(&o)
 	}
 
 	return responses, total
@@ -741,6 +914,8 @@ func (s *OrderService) GetOrderList(userID uint, page, pageSize int) ([]OrderRes
 
 /**
  * GetOrderByNo 根据订单号获取订单
+ *
+ * 父订单返回店铺子订单及商品明细，子订单返回商品明细和父订单号。
  */
 func (s *OrderService) GetOrderByNo(orderNo string) (*OrderResponse, error) {
 	order, err := s.orderRepo.GetByOrderNo(orderNo)
@@ -748,19 +923,7 @@ func (s *OrderService) GetOrderByNo(orderNo string) (*OrderResponse, error) {
 		return nil, err
 	}
 
-	return &OrderResponse{
-		ID:           order.ID,
-		OrderNo:      order.OrderNo,
-		UserID:       order.UserID,
-		ProductID:    order.ProductID,
-		ProductName:  order.ProductName,
-		ProductImage: order.ProductImage,
-		Quantity:     order.Quantity,
-		TotalPrice:   order.TotalPrice,
-		Status:       order.Status,
-		PayType:      order.PayType,
-		CreatedAt:    order.CreatedAt.Format("2006-01-02 15:04:05"),
-	}, nil
+	return orderDetail(s.orderRepo, order)
 }
 
 /**
@@ -772,12 +935,24 @@ func (s *OrderService) PayOrder(orderNo string) error {
 		return err
 	}
 
+	if order.ParentID != 0 {
+		return ErrChildOrderOperation
+	}
 	if order.Status != 1 {
 		return errors.New("订单状态不允许支付")
 	}
 
-	order.Status = 2 // 已支付
-	return s.orderRepo.Update(order)
+	// 条件更新，并发支付时只有一次成功；合并支付父订单的子订单一起更新
+	paid, err := s.orderRepo.MarkPaid(order, order.PayType, time.Now())
+	if err != nil {
+		return err
+	}
+	if !paid {
+		return errors.New("订单状态不允许支付")
+	}
+
+	recordOrderSales(s.productRepo, order)
+	return nil
 }
 
 /**
@@ -789,10 +964,25 @@ func (s *OrderService) CancelOrder(orderNo string) error {
 		return err
 	}
 
+	if order.ParentID != 0 {
+		return ErrChildOrderOperation
+	}
 	if order.Status != 1 {
 		return errors.New("当前订单状态不允许取消")
 	}
 
+	// 合并支付父订单与子订单一起取消
+	if order.OrderType == model.OrderTypeCombined {
+		cancelled, err := s.orderRepo.CancelCombined(order)
+		if err != nil {
+			return err
+		}
+		if !cancelled {
+			return errors.New("当前订单状态不允许取消")
+		}
+		return nil
+	}
+
 	order.Status = 5 // 已取消
 	return s.orderRepo.Update(order)
 }
@@ -800,9 +990,15 @@ func (s *OrderService) CancelOrder(orderNo string) error {
 /**
  * Checkout 购物车结算
  *
- * 将购物车中的所有商品转换为订单，并清空购物车。
+ * 按店铺拆单：生成一张用于支付的父订单，每个店铺一张子订单，子订单包含本店的商品明细。
+ * 整单优惠按商品金额比例分摊到子订单和商品明细。
+ * 订单创建、库存扣减和购物车清理在同一事务中完成，任一商品失败时整体回滚。
+ *
+ * 返回值：
+ *   *OrderResponse - 父订单，Children 为店铺子订单
+ *   error - 购物车为空、商品不可购买或库存不足时返回错误
  */
-func (s *OrderService) Checkout(userID uint) ([]*OrderResponse, error) {
+func (s *OrderService) Checkout(userID uint) (*OrderResponse, error) {
 	// 1. 获取购物车商品
 	cartItems, err := s.cartRepo.GetListByUserID(userID)
 	if err != nil {
@@ -812,39 +1008,47 @@ func (s *OrderService) Checkout(userID uint) ([]*OrderResponse, error) {
 		return nil, errors.New("购物车为空")
 	}
 
-	var orders []*OrderResponse
-
-	// 2. 遍历创建订单
-	// 注意：这里简单循环创建，如果中间失败，前面的订单可能已经创建成功。
-	// 理想情况下应该放在一个事务中，或者支持部分成功。
-	// 为了演示，我们接受部分成功，记录错误但不中断（或者中断返回已创建的？）
-	// 这里选择：遇到错误直接返回，前端提示重试（可能导致重复下单风险，但配合幂等性或事务可解）
-	// 为简化，我们假设都成功。
+	// 2. 校验商品是否可购买
+	lines := make([]checkoutLine, 0, len(cartItems))
+	cartIDs := make([]uint, 0, len(cartItems))
+	now := time.Now()
 	for _, item := range cartItems {
-		req := &CreateOrderRequest{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
+		product, err := s.productRepo.GetByID(item.ProductID)
+		if err == nil && !product.IsOnShelf(now) {
+			err = errors.New("商品已下架")
+		}
+		if err == nil {
+			err = ensureShopOpen(product)
+		}
+		if err == nil && product.Stock < item.Quantity {
+			err = repository.ErrInsufficientStock
 		}
-		// 使用同步创建模式
-		order, err := s.CreateOrderSync(userID, req)
 		if err != nil {
-			// 记录错误，继续或者返回？
-			// 这里简单处理：如果有一个失败，返回错误。
-			// 实际场景：可能涉及事务回滚。
 			return nil, fmt.Errorf("商品ID %d 下单失败: %v", item.ProductID, err)
 		}
-		orders = append(orders, order)
+		lines = append(lines, checkoutLine{product: product, quantity: item.Quantity})
+		cartIDs = append(cartIDs, item.ID)
 	}
 
-	// 3. 清空购物车
-	if err := s.cartRepo.DeleteAllByUserID(userID); err != nil {
-		// 购物车清空失败，但订单已创建。这会导致用户再次结算时重复下单。
-		// 这是一个严重的一致性问题。
-		// TODO: 使用事务保证 订单创建 和 购物车清空 的原子性。
-		return nil, errors.New("结算异常，请联系客服")
+	// 3. 生成父订单和店铺子订单
+	parent, children := buildCheckoutOrders(userID, lines)
+
+	// 4. 创建订单、扣减库存、清理购物车
+	if err := s.orderRepo.CreateCombined(parent, children, cartIDs); err != nil {
+		if errors.Is(err, repository.ErrInsufficientStock) || errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrInsufficientStock
+		}
+		return nil, errors.New("订单创建失败")
 	}
 
-	return orders, nil
+	response := newOrderResponse(parent)
+	for _, child := range children {
+		childResponse := newOrderResponse(child.Order)
+		childResponse.ParentOrderNo = parent.OrderNo
+		childResponse.Items = newOrderItemResponses(child.Items)
+		response.Children = append(response.Children, childResponse)
+	}
+	return &response, nil
 }
 
 /**
@@ -966,9 +1170,12 @@ func (s *CartService) AddToCart(userID uint, req *AddToCartRequest) (*CartItemRe
 		return nil, err
 	}
 
-	if product.Status != 1 {
+	if !product.IsOnShelf(time.Now()) {
 		return nil, errors.New("商品已下架")
 	}
+	if err := ensureShopOpen(product); err != nil {
+		return nil, err
+	}
 
 	if product.Stock < req.Quantity {
 		return nil, repository.ErrInsufficientStock
@@ -1064,7 +1271,7 @@ func (s *CartService) GetCartList(userID uint) (*CartResponse, error) {
 		productIDs[i] = cart.ProductID
 	}
 
-	products, err := s.productRepo.GetByIDs(productIDs)
+	products, err := s.productRepo.GetByIDsWithCache(productIDs)
 	if err != nil {
 		return nil, errors.New("获取商品信息失败")
 	}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gomall/backend/internal/config"
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/redis"
+	"gomall/backend/internal/repository"
+	"gomall/backend/pkg/jwt"
+
+	"go.uber.org/zap"
+)
+
+// 登录会话（设备）管理
+// 每次登录创建一条会话记录，ID 与刷新Token的轮换家族相同，访问Token也带有该ID（jwt.Claims.Family）。
+// 删除会话：标记吊销、删除刷新Token家族，并在 Redis 写入吊销标记使该会话的访问Token失效（见 CheckToken）。
+// 最近活跃时间由认证中间件调用 TouchSession 更新，按 security.session_touch_interval 节流
+
+// ErrSessionNotFound 会话不存在或不属于当前用户
+var ErrSessionNotFound = errors.New("会话不存在")
+
+// ClientInfo 发起登录的客户端信息，记录到会话中
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// SessionResponse 会话信息
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // 是否为当前请求所在的会话
+}
+
+// sessionTouchInterval 读取最近活跃时间的更新间隔，默认 5 分钟
+func sessionTouchInterval() time.Duration {
+	if securityConfig := config.GetSecurity(); securityConfig != nil {
+		if seconds := securityConfig.GetInt("session_touch_interval"); seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 5 * time.Minute
+}
+
+// SessionService 登录会话服务
+type SessionService struct {
+	sessionRepo *repository.SessionRepository
+}
+
+// NewSessionService 创建登录会话服务
+func NewSessionService() *SessionService {
+	return &SessionService{
+		sessionRepo: repository.NewSessionRepository(),
+	}
+}
+
+// List 获取用户的有效会话，currentFamily 为当前请求所在的会话
+func (s *SessionService) List(userID uint, currentFamily string) ([]SessionResponse, error) {
+	sessions, err := s.sessionRepo.ListActive(userID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, SessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    currentFamily != "" && session.Family == currentFamily,
+		})
+	}
+	return result, nil
+}
+
+// Delete 删除（下线）用户的一个会话，可以是当前会话
+func (s *SessionService) Delete(ctx context.Context, userID, id uint) error {
+	session, err := s.sessionRepo.GetByID(userID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrSessionNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	if session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+	return EndSession(ctx, session.Family)
+}
+
+// EndSession 结束会话：标记吊销、删除刷新Token家族，并使该会话的访问Token失效
+// Redis 不可用时无法吊销访问Token，返回 ErrRevokeUnavailable
+func EndSession(ctx context.Context, family string) error {
+	if family == "" {
+		return nil
+	}
+	if redis.Client == nil {
+		return ErrRevokeUnavailable
+	}
+	if err := redis.RevokeSession(ctx, family, jwt.NewJWT().AccessTTL()); err != nil {
+		return err
+	}
+	if err := redis.DeleteRefreshFamily(ctx, family); err != nil {
+		return err
+	}
+	if _, err := repository.NewSessionRepository().Revoke(family); err != nil {
+		logger.Warn("会话吊销状态写入失败", zap.String("family", family), zap.Error(err))
+	}
+	return nil
+}
+
+// TouchSession 更新会话最近活跃时间，失败只记录日志
+// Redis 可用时用 SETNX 节流，否则由数据库条件更新节流
+func TouchSession(ctx context.Context, claims *jwt.Claims) {
+	if claims.Family == "" {
+		return
+	}
+	interval := sessionTouchInterval()
+	if redis.Client != nil {
+		ok, err := redis.AcquireSessionTouch(ctx, claims.Family, interval)
+		if err != nil {
+			logger.Warn("会话活跃时间节流读取失败", zap.Uint("user_id", claims.UserID), zap.Error(err))
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+	if err := repository.NewSessionRepository().Touch(claims.Family, interval); err != nil {
+		logger.Warn("会话活跃时间更新失败", zap.Uint("user_id", claims.UserID), zap.Error(err))
+	}
+}
+
+// createSession 登录时创建会话记录，失败只记录日志，不影响登录
+func createSession(user *model.User, family string, client ClientInfo, expiresAt time.Time) {
+	userAgent := client.UserAgent
+	// user_agent 列为 varchar(255)，按字符截断，不能切开多字节的 UTF-8 字符
+	if utf8.RuneCountInString(userAgent) > 255 {
+		userAgent = string([]rune(userAgent)[:255])
+	}
+	session := &model.UserSession{
+		UserID:     user.ID,
+		Family:     family,
+		Device:     deviceName(client.UserAgent),
+		UserAgent:  userAgent,
+		IP:         client.IP,
+		LastSeenAt: time.Now(),
+		ExpiresAt:  expiresAt,
+	}
+	if err := repository.NewSessionRepository().Create(session); err != nil {
+		logger.Warn("登录会话记录失败", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+}
+
+// deviceName 根据 User-Agent 识别设备，格式为 "系统 · 浏览器"，无法识别时返回 "未知设备"
+func deviceName(userAgent string) string {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return "未知设备"
+	}
+
+	var system string
+	switch {
+	case strings.Contains(ua, "iphone"):
+		system = "iPhone"
+	case strings.Contains(ua, "ipad"):
+		system = "iPad"
+	case strings.Contains(ua, "android"):
+		system = "Android"
+	case strings.Contains(ua, "windows"):
+		system = "Windows"
+	case strings.Contains(ua, "mac os"), strings.Contains(ua, "macintosh"):
+		system = "macOS"
+	case strings.Contains(ua, "linux"):
+		system = "Linux"
+	}
+
+	var browser string
+	switch {
+	case strings.Contains(ua, "micromessenger"):
+		browser = "微信"
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "chrome/"), strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "firefox/"), strings.Contains(ua, "fxios/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	}
+
+	switch {
+	case system != "" && browser != "":
+		return system + " · " + browser
+	case system != "":
+		return system
+	case browser != "":
+		return browser
+	}
+	// 非浏览器客户端（App、脚本）取 User-Agent 的产品名
+	name := strings.Fields(userAgent)[0]
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	return name
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// 店铺与商家后台
+// 用户提交开店申请，平台审核通过后即为商家：每个用户最多一个店铺。
+// 审核通过（含恢复营业）时普通用户店主被授予商家角色（merchant，拥有 shop:operate 权限），停业时收回为普通用户，
+// 角色变更与店铺状态在同一事务中完成，并吊销店主已签发的 Token；店主是其他后台角色时不修改角色。
+// 商家后台（/api/shop）由 middleware.MerchantMiddleware 校验 shop:operate 权限并解析当前用户营业中的店铺，
+// 商品、库存、订单、秒杀库存的操作都限定在本店范围内。
+// 店铺未通过审核或已停业时，商品不在列表中展示，不能加入购物车和下单。
+// 审核、驳回、停业写入 admin_audit_logs，与店铺状态变更在同一事务中完成
+
+var (
+	// ErrShopNotFound 店铺不存在或用户未开店
+	ErrShopNotFound = errors.New("店铺不存在")
+	// ErrShopNotOpen 店铺未通过审核或已停业
+	ErrShopNotOpen = errors.New("店铺未通过审核或已停业")
+	// ErrShopExists 用户已提交过开店申请
+	ErrShopExists = errors.New("已提交过开店申请")
+	// ErrShopNameTaken 店铺名称已被使用
+	ErrShopNameTaken = errors.New("店铺名称已被使用")
+	// ErrShopStatusConflict 店铺当前状态不允许该操作
+	ErrShopStatusConflict = errors.New("店铺当前状态不允许该操作")
+	// ErrShopNameEmpty 店铺名称为空
+	ErrShopNameEmpty = errors.New("店铺名称不能为空")
+	// ErrShopLogoInvalid 店铺 Logo 不是上传服务返回的地址
+	ErrShopLogoInvalid = errors.New("Logo 地址无效，请先通过上传接口上传图片")
+	// ErrShopReasonRequired 驳回、停业需要填写原因
+	ErrShopReasonRequired = errors.New("请填写原因")
+	// ErrOrderNotShippable 订单不是已支付状态，不能发货
+	ErrOrderNotShippable = errors.New("只有已支付的订单可以发货")
+)
+
+// ApplyShopRequest 开店申请、修改店铺资料请求
+type ApplyShopRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+	Logo        string `json:"logo" binding:"max=500"` // 上传接口返回的地址
+}
+
+// ShopReviewRequest 店铺审核请求
+type ShopReviewRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// SetStockRequest 设置库存请求
+type SetStockRequest struct {
+	Stock int `json:"stock" binding:"gte=0"`
+}
+
+// ShopService 店铺服务
+type ShopService struct {
+	shopRepo       *repository.ShopRepository
+	roleRepo       *repository.RoleRepository
+	productRepo    *repository.ProductRepository
+	orderRepo      *repository.OrderRepository
+	productService *ProductService
+}
+
+// NewShopService 创建店铺服务
+func NewShopService() *ShopService {
+	return &ShopService{
+		shopRepo:       repository.NewShopRepository(),
+		roleRepo:       repository.NewRoleRepository(),
+		productRepo:    repository.NewProductRepository(),
+		orderRepo:      repository.NewOrderRepository(),
+		productService: NewProductService(),
+	}
+}
+
+// Apply 提交开店申请
+func (s *ShopService) Apply(ownerID uint, req *ApplyShopRequest) (*model.Shop, error) {
+	if _, err := s.shopRepo.GetByOwner(ownerID); err == nil {
+		return nil, ErrShopExists
+	} else if !errors.Is(err, repository.ErrShopNotFound) {
+		return nil, err
+	}
+	if err := s.validateShopInfo(0, req); err != nil {
+		return nil, err
+	}
+
+	shop := &model.Shop{
+		OwnerID:     ownerID,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Logo:        req.Logo,
+		Status:      model.ShopStatusPending,
+	}
+	if err := s.shopRepo.Create(shop); err != nil {
+		return nil, err
+	}
+	return shop, nil
+}
+
+// Mine 获取当前用户的店铺（任意状态）
+func (s *ShopService) Mine(ownerID uint) (*model.Shop, error) {
+	shop, err := s.shopRepo.GetByOwner(ownerID)
+	if errors.Is(err, repository.ErrShopNotFound) {
+		return nil, ErrShopNotFound
+	}
+	return shop, err
+}
+
+// UpdateMine 修改店铺资料，被驳回的申请修改后重新进入待审核
+func (s *ShopService) UpdateMine(ownerID uint, req *ApplyShopRequest) (*model.Shop, error) {
+	shop, err := s.Mine(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if shop.Status == model.ShopStatusSuspended {
+		return nil, ErrShopStatusConflict
+	}
+	if err := s.validateShopInfo(shop.ID, req); err != nil {
+		return nil, err
+	}
+
+	shop.Name = strings.TrimSpace(req.Name)
+	shop.Description = req.Description
+	shop.Logo = req.Logo
+	if err := s.shopRepo.UpdateInfo(shop); err != nil {
+		return nil, err
+	}
+	return shop, nil
+}
+
+// OpenShopID 获取用户营业中的店铺ID，供商家后台中间件使用
+func (s *ShopService) OpenShopID(ownerID uint) (uint, error) {
+	shop, err := s.Mine(ownerID)
+	if err != nil {
+		return 0, err
+	}
+	if shop.Status != model.ShopStatusApproved {
+		return 0, ErrShopNotOpen
+	}
+	return shop.ID, nil
+}
+
+// ListProducts 本店商品列表，包含下架商品
+func (s *ShopService) ListProducts(shopID uint, page, pageSize int) ([]ProductResponse, int64, error) {
+	products, total, err := s.productRepo.ListByShop(shopID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	now := time.Now()
+	result := make([]ProductResponse, 0, len(products))
+	for i := range products {
+		p := &products[i]
+		result = append(result, ProductResponse{
+			ID:          p.ID,
+			ShopID:      p.ShopID,
+			SKU:         productSKU(p),
+			Name:        p.Name,
+			Description: p.Description,
+			Price:       p.Price,
+			Stock:       p.Stock,
+			Category:    p.Category,
+			ImageURL:    p.ImageURL,
+			Status:      p.EffectiveStatus(now),
+			PublishAt:   p.PublishAt,
+			UnpublishAt: p.UnpublishAt,
+			CreatedAt:   p.CreatedAt.Format("2006-01-02 15:04:05"),
+
+			FavoriteCount: p.FavoriteCount,
+		})
+	}
+	return result, total, nil
+}
+
+// CreateProduct 创建本店商品
+func (s *ShopService) CreateProduct(shopID uint, req *CreateProductRequest) (*ProductResponse, error) {
+	return s.productService.createInShop(shopID, req)
+}
+
+// UpdateProduct 修改本店商品，库存通过 SetStock 单独设置，请求中的 stock 被忽略
+func (s *ShopService) UpdateProduct(shopID, productID, operatorID uint, req *UpdateProductRequest) error {
+	product, err := s.productRepo.GetByShop(productID, shopID)
+	if err != nil {
+		return err
+	}
+	req.Stock = product.Stock
+	return s.productService.Update(productID, operatorID, req)
+}
+
+// DeleteProduct 删除本店商品
+func (s *ShopService) DeleteProduct(shopID, productID uint) error {
+	if _, err := s.productRepo.GetByShop(productID, shopID); err != nil {
+		return err
+	}
+	return s.productService.Delete(productID)
+}
+
+// SetStock 设置本店商品库存
+func (s *ShopService) SetStock(shopID, productID uint, stock int) error {
+	updated, err := s.productRepo.SetStock(productID, shopID, stock)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return repository.ErrProductNotFound
+	}
+	return nil
+}
+
+// InitSeckillStock 为本店商品初始化秒杀库存
+func (s *ShopService) InitSeckillStock(ctx context.Context, shopID, productID uint, stock int) error {
+	if _, err := s.productRepo.GetByShop(productID, shopID); err != nil {
+		return err
+	}
+	return NewSeckillService().InitSeckillStock(ctx, productID, stock)
+}
+
+// ListOrders 本店订单列表，status 小于 0 时不按状态过滤
+func (s *ShopService) ListOrders(shopID uint, status, page, pageSize int) ([]OrderResponse, int64, error) {
+	orders, total, err := s.orderRepo.ListByShop(shopID, status, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	result := make([]OrderResponse, 0, len(orders))
+	for i := range orders {
+		result = append(result, newOrderResponse(&orders[i]))
+	}
+	return result, total, nil
+}
+
+// GetOrder 本店订单详情，结算子订单附带商品明细
+func (s *ShopService) GetOrder(shopID uint, orderNo string) (*OrderResponse, error) {
+	order, err := s.orderRepo.GetByShop(orderNo, shopID)
+	if err != nil {
+		return nil, err
+	}
+	return orderDetail(s.orderRepo, order)
+}
+
+// ShipOrder 本店已支付的订单标记为已发货
+func (s *ShopService) ShipOrder(shopID uint, orderNo string) error {
+	if _, err := s.orderRepo.GetByShop(orderNo, shopID); err != nil {
+		return err
+	}
+	shipped, err := s.orderRepo.MarkShipped(orderNo, shopID)
+	if err != nil {
+		return err
+	}
+	if !shipped {
+		return ErrOrderNotShippable
+	}
+	return nil
+}
+
+// ListShops 后台店铺列表
+func (s *ShopService) ListShops(query repository.ShopQuery, page, pageSize int) ([]model.Shop, int64, error) {
+	return s.shopRepo.List(query, page, pageSize)
+}
+
+// Approve 审核通过开店申请，或恢复已停业的店铺
+func (s *ShopService) Approve(op AdminOperator, shopID uint, reason string) error {
+	merchant, err := s.roleRepo.GetByCode(model.RoleCodeMerchant)
+	if err != nil {
+		return err
+	}
+	return s.review(op, shopID, []int{model.ShopStatusPending, model.ShopStatusSuspended},
+		model.ShopStatusApproved, model.AuditActionShopApprove, reason,
+		&repository.OwnerRoleChange{From: model.RoleUser, To: int(merchant.ID)})
+}
+
+// Reject 驳回开店申请，需要填写原因
+func (s *ShopService) Reject(op AdminOperator, shopID uint, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return ErrShopReasonRequired
+	}
+	return s.review(op, shopID, []int{model.ShopStatusPending},
+		model.ShopStatusRejected, model.AuditActionShopReject, reason, nil)
+}
+
+// Suspend 店铺停业，商品不再展示、不能下单，需要填写原因
+func (s *ShopService) Suspend(op AdminOperator, shopID uint, reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return ErrShopReasonRequired
+	}
+	merchant, err := s.roleRepo.GetByCode(model.RoleCodeMerchant)
+	if err != nil {
+		return err
+	}
+	return s.review(op, shopID, []int{model.ShopStatusApproved},
+		model.ShopStatusSuspended, model.AuditActionShopSuspend, reason,
+		&repository.OwnerRoleChange{From: int(merchant.ID), To: model.RoleUser})
+}
+
+// review 变更店铺状态，状态变更、店主角色调整与审计日志在同一事务中写入
+// 店主角色有变化时吊销其 Token，重新登录后按新角色签发
+func (s *ShopService) review(op AdminOperator, shopID uint, from []int, to int, action, reason string, role *repository.OwnerRoleChange) error {
+	shop, err := s.shopRepo.GetByID(shopID)
+	if err != nil {
+		if errors.Is(err, repository.ErrShopNotFound) {
+			return ErrShopNotFound
+		}
+		return err
+	}
+
+	if role != nil {
+		role.OwnerID = shop.OwnerID
+	}
+	changed, err := s.shopRepo.Review(shopID, from, to, reason, &model.AdminAuditLog{
+		OperatorID: op.UserID,
+		Action:     action,
+		TargetType: model.AuditTargetShop,
+		TargetID:   shopID,
+		Detail:     statusDetail(shop.Status, to, reason),
+		IP:         op.IP,
+	}, role)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return ErrShopStatusConflict
+	}
+	// 店铺营业状态决定其商品是否出现在列表中
+	s.productRepo.InvalidateListCache()
+	if role != nil && role.Applied {
+		if _, err := RevokeAllTokens(context.Background(), shop.OwnerID); err != nil {
+			logger.Warn("店主角色变更后吊销Token失败", zap.Uint("user_id", shop.OwnerID), zap.Error(err))
+		}
+	}
+	logger.Info("后台变更店铺状态", zap.Uint("operator_id", op.UserID), zap.Uint("shop_id", shopID),
+		zap.String("action", action), zap.Int("status", to))
+	return nil
+}
+
+// validateShopInfo 校验店铺名称未被其他店铺使用、Logo 为上传服务返回的地址
+func (s *ShopService) validateShopInfo(shopID uint, req *ApplyShopRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return ErrShopNameEmpty
+	}
+	if exist, err := s.shopRepo.GetByName(name); err == nil && exist.ID != shopID {
+		return ErrShopNameTaken
+	} else if err != nil && !errors.Is(err, repository.ErrShopNotFound) {
+		return err
+	}
+	if req.Logo != "" && !strings.HasPrefix(req.Logo, uploadURLPrefix()) {
+		return ErrShopLogoInvalid
+	}
+	return nil
+}
+
+// ensureShopOpen 商品属于平台自营或营业中的店铺时返回 nil，否则返回 ErrShopNotOpen
+func ensureShopOpen(product *model.Product) error {
+	open, err := repository.NewShopRepository().IsOpen(product.ShopID)
+	if err != nil {
+		return err
+	}
+	if !open {
+		return ErrShopNotOpen
+	}
+	return nil
+}
@@ -0,0 +1,397 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gomall/backend/internal/config"
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/metrics"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/redis"
+	"gomall/backend/internal/repository"
+	"gomall/backend/internal/sms"
+	"gomall/backend/pkg/password"
+
+	"go.uber.org/zap"
+)
+
+// 短信验证码登录与绑定手机
+// 验证码为 6 位数字，Redis 中只保存 HMAC-SHA256（见 redis/sms.go），输错次数过多后作废。
+// HMAC 密钥依次取 sms.code_secret、环境变量 GOMALL_SMS_SECRET、JWT 的 HS256 密钥，读到 Redis 也无法枚举出验证码。
+// 发送频率按手机号（发送间隔、每日上限）和IP（每小时上限）限制。
+// 验证码登录时手机号未绑定任何账号则自动注册，新账号使用占位邮箱，需要在个人中心补充真实邮箱后才能验证
+
+// 验证码用途
+const (
+	SmsPurposeLogin = "login"
+	SmsPurposeBind  = "bind"
+)
+
+// PlaceholderEmailDomain 手机号注册账号的占位邮箱域名（.invalid 为保留域名，不会投递）
+const PlaceholderEmailDomain = "phone.gomall.invalid"
+
+var (
+	// ErrInvalidPhone 手机号格式错误
+	ErrInvalidPhone = errors.New("手机号格式不正确")
+	// ErrSmsCodeInvalid 验证码错误或已过期
+	ErrSmsCodeInvalid = errors.New("验证码错误或已过期")
+	// ErrSmsCodeExhausted 验证码输错次数过多，已作废
+	ErrSmsCodeExhausted = errors.New("验证码错误次数过多，请重新获取")
+	// ErrSmsTooFrequent 验证码发送过于频繁
+	ErrSmsTooFrequent = errors.New("验证码发送过于频繁，请稍后再试")
+	// ErrSmsUnavailable Redis 不可用，无法发送或校验验证码
+	ErrSmsUnavailable = errors.New("短信验证码服务不可用")
+	// ErrSmsSendFailed 短信发送失败
+	ErrSmsSendFailed = errors.New("短信发送失败，请稍后再试")
+	// ErrPhoneBound 手机号已被其他账号绑定
+	ErrPhoneBound = errors.New("手机号已被其他账号绑定")
+)
+
+var phonePattern = regexp.MustCompile(`^1[3-9]\d{9}$`)
+
+// smsSettings 短信相关配置
+type smsSettings struct {
+	codeTTL           time.Duration
+	maxVerifyAttempts int
+	resendInterval    time.Duration
+	phoneDailyLimit   int
+	ipHourlyLimit     int
+}
+
+// loadSmsSettings 读取 sms 配置，未配置时使用默认值
+func loadSmsSettings() smsSettings {
+	settings := smsSettings{
+		codeTTL:           5 * time.Minute,
+		maxVerifyAttempts: 5,
+		resendInterval:    time.Minute,
+		phoneDailyLimit:   10,
+		ipHourlyLimit:     20,
+	}
+	smsConfig := config.GetSms()
+	if smsConfig == nil {
+		return settings
+	}
+	if seconds := smsConfig.GetInt("code_ttl"); seconds > 0 {
+		settings.codeTTL = time.Duration(seconds) * time.Second
+	}
+	if attempts := smsConfig.GetInt("max_verify_attempts"); attempts > 0 {
+		settings.maxVerifyAttempts = attempts
+	}
+	if seconds := smsConfig.GetInt("resend_interval"); seconds > 0 {
+		settings.resendInterval = time.Duration(seconds) * time.Second
+	}
+	if limit := smsConfig.GetInt("phone_daily_limit"); limit > 0 {
+		settings.phoneDailyLimit = limit
+	}
+	if limit := smsConfig.GetInt("ip_hourly_limit"); limit > 0 {
+		settings.ipHourlyLimit = limit
+	}
+	return settings
+}
+
+// SmsCodeRequest 发送验证码请求
+type SmsCodeRequest struct {
+	Phone string `json:"phone" binding:"required"`
+}
+
+// PhoneCodeRequest 手机号 + 验证码（验证码登录、绑定手机）
+type PhoneCodeRequest struct {
+	Phone string `json:"phone" binding:"required"`
+	Code  string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// SmsService 短信验证码服务
+type SmsService struct {
+	userRepo *repository.UserRepository
+}
+
+// NewSmsService 创建短信验证码服务
+func NewSmsService() *SmsService {
+	return &SmsService{
+		userRepo: repository.NewUserRepository(),
+	}
+}
+
+// SendCode 发送验证码，purpose 为 SmsPurposeLogin / SmsPurposeBind
+func (s *SmsService) SendCode(ctx context.Context, purpose, phone, clientIP string) error {
+	if !phonePattern.MatchString(phone) {
+		return ErrInvalidPhone
+	}
+	if redis.Client == nil {
+		return ErrSmsUnavailable
+	}
+
+	settings := loadSmsSettings()
+	if err := s.checkSendLimit(ctx, settings, phone, clientIP); err != nil {
+		return err
+	}
+
+	code, err := newSmsCode()
+	if err != nil {
+		return err
+	}
+	hash, err := hashSmsCode(purpose, phone, code)
+	if err != nil {
+		return err
+	}
+	if err := redis.SaveSmsCode(ctx, purpose, phone, hash, settings.codeTTL); err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("【GoMall】您的验证码为 %s，%d 分钟内有效，请勿泄露给他人。", code, int(settings.codeTTL.Minutes()))
+	if err := sms.Send(ctx, phone, content); err != nil {
+		logger.Error("短信发送失败", zap.String("phone", phone), zap.Error(err))
+		return ErrSmsSendFailed
+	}
+	return nil
+}
+
+// checkSendLimit 检查发送间隔、手机号每日上限和IP每小时上限
+func (s *SmsService) checkSendLimit(ctx context.Context, settings smsSettings, phone, clientIP string) error {
+	ok, err := redis.AcquireSmsCooldown(ctx, phone, settings.resendInterval)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSmsTooFrequent
+	}
+
+	count, err := redis.IncrSmsQuota(ctx, "ip", clientIP, time.Hour)
+	if err != nil {
+		return err
+	}
+	if count > int64(settings.ipHourlyLimit) {
+		return ErrSmsTooFrequent
+	}
+
+	count, err = redis.IncrSmsQuota(ctx, "phone", phone, 24*time.Hour)
+	if err != nil {
+		return err
+	}
+	if count > int64(settings.phoneDailyLimit) {
+		return ErrSmsTooFrequent
+	}
+	return nil
+}
+
+// verifyCode 校验验证码，成功后验证码作废
+func (s *SmsService) verifyCode(ctx context.Context, purpose, phone, code string) error {
+	if !phonePattern.MatchString(phone) {
+		return ErrInvalidPhone
+	}
+	if redis.Client == nil {
+		return ErrSmsUnavailable
+	}
+
+	hash, err := hashSmsCode(purpose, phone, code)
+	if err != nil {
+		return err
+	}
+	result, err := redis.VerifySmsCode(ctx, purpose, phone, hash, loadSmsSettings().maxVerifyAttempts)
+	if err != nil {
+		return err
+	}
+	switch result {
+	case 1:
+		return nil
+	case -2:
+		return ErrSmsCodeExhausted
+	default:
+		return ErrSmsCodeInvalid
+	}
+}
+
+// LoginByPhone 验证码登录，手机号未绑定账号时自动注册，返回的 bool 表示是否为新注册的账号
+// 账号启用了两步验证时同样需要再提交动态验证码
+func (s *SmsService) LoginByPhone(ctx context.Context, req *PhoneCodeRequest, client ClientInfo) (*LoginResult, bool, error) {
+	guard := newLoginGuard()
+	if err := guard.check(ctx, 0, client.IP); err != nil {
+		return nil, false, err
+	}
+
+	if err := s.verifyCode(ctx, SmsPurposeLogin, req.Phone, req.Code); err != nil {
+		if errors.Is(err, ErrSmsCodeInvalid) || errors.Is(err, ErrSmsCodeExhausted) {
+			if _, lockErr := guard.fail(ctx, 0, client.IP); lockErr != nil {
+				return nil, false, lockErr
+			}
+		}
+		return nil, false, err
+	}
+
+	created := false
+	user, err := s.userRepo.GetByVerifiedPhone(req.Phone)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		user, err = s.registerByPhone(req.Phone)
+		created = err == nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if err := guard.check(ctx, user.ID, client.IP); err != nil {
+		return nil, false, err
+	}
+	guard.succeed(ctx, user.ID)
+
+	result, err := NewTwoFactorService().completeLogin(ctx, user, client)
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		metrics.RecordUserRegister()
+	}
+	return result, created, nil
+}
+
+// registerByPhone 使用已验证的手机号注册账号，用户名随机生成，密码为随机值（需通过找回密码或修改资料设置）
+func (s *SmsService) registerByPhone(phone string) (*model.User, error) {
+	randomPassword, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := password.HashPassword(randomPassword)
+	if err != nil {
+		return nil, errors.New("密码加密失败")
+	}
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		suffix, err := randomHex(3)
+		if err != nil {
+			return nil, err
+		}
+		// 以 m 开头，不会与注销账号的 deleted_ 占位用户名冲突
+		username := fmt.Sprintf("m%s_%s", phone[len(phone)-4:], suffix)
+		user := &model.User{
+			Username:        username,
+			Password:        hashedPassword,
+			Email:           username + "@" + PlaceholderEmailDomain,
+			Phone:           phone,
+			PhoneVerifiedAt: &now,
+			Role:            model.RoleUser,
+			Status:          model.UserStatusActive,
+		}
+		if existUser, _ := s.userRepo.GetByUsername(username); existUser != nil {
+			continue
+		}
+		if err := s.userRepo.Create(user); err != nil {
+			return nil, errors.New("用户创建失败")
+		}
+		return user, nil
+	}
+	return nil, errors.New("用户创建失败")
+}
+
+// BindPhone 绑定或更换手机号，验证码发送到新手机号
+func (s *SmsService) BindPhone(ctx context.Context, userID uint, req *PhoneCodeRequest) (*UserResponse, error) {
+	if !phonePattern.MatchString(req.Phone) {
+		return nil, ErrInvalidPhone
+	}
+	owner, err := s.userRepo.GetByVerifiedPhone(req.Phone)
+	if err == nil && owner.ID != userID {
+		return nil, ErrPhoneBound
+	}
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+		return nil, err
+	}
+
+	if err := s.verifyCode(ctx, SmsPurposeBind, req.Phone, req.Code); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.BindPhone(userID, req.Phone); err != nil {
+		if errors.Is(err, repository.ErrPhoneTaken) {
+			return nil, ErrPhoneBound
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return newUserResponse(user), nil
+}
+
+// IsPlaceholderEmail 是否为手机号注册时生成的占位邮箱
+func IsPlaceholderEmail(email string) bool {
+	return strings.HasSuffix(email, "@"+PlaceholderEmailDomain)
+}
+
+// newSmsCode 生成 6 位数字验证码
+func newSmsCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// hashSmsCode 验证码的 HMAC-SHA256，混入用途和手机号，Redis 中不保存明文
+func hashSmsCode(purpose, phone, code string) (string, error) {
+	secret, err := smsCodeSecret()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose + ":" + phone + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+var (
+	smsSecretOnce sync.Once
+	smsSecret     []byte
+	smsSecretErr  error
+)
+
+// smsCodeSecret 验证码 HMAC 密钥，首次使用时加载
+// 都未配置时生成进程内随机密钥并告警，多实例部署需配置 sms.code_secret
+func smsCodeSecret() ([]byte, error) {
+	smsSecretOnce.Do(func() {
+		var secret string
+		if smsConfig := config.GetSms(); smsConfig != nil {
+			secret = smsConfig.GetString("code_secret")
+		}
+		if secret == "" {
+			secret = os.Getenv("GOMALL_SMS_SECRET")
+		}
+		if secret == "" {
+			secret = os.Getenv("GOMALL_JWT_SECRET")
+		}
+		if secret == "" {
+			if jwtConfig := config.GetJWT(); jwtConfig != nil {
+				secret = jwtConfig.GetString("secret")
+			}
+		}
+		if secret == "" {
+			random, err := randomHex(32)
+			if err != nil {
+				smsSecretErr = fmt.Errorf("生成短信验证码密钥失败: %w", err)
+				return
+			}
+			secret = random
+			logger.Warn("短信验证码密钥未配置，已生成随机密钥，多实例部署请配置 sms.code_secret")
+		}
+		smsSecret = []byte(secret)
+	})
+	return smsSecret, smsSecretErr
+}
+
+// randomHex 生成 n 字节的随机十六进制字符串
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/redis"
+	"gomall/backend/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// 管理后台经营统计
+// 历史日期读取 daily_stats 快照（每天凌晨刷新最近 statsSnapshotDays 天，补上晚到的取消和支付），
+// 当天实时计算；快照缺失的历史日期在查询时实时计算并补写快照。
+// 接口结果按查询参数缓存在 Redis 中，包含当天的查询缓存时间较短。
+
+var (
+	// ErrInvalidStatsRange 统计日期范围不合法
+	ErrInvalidStatsRange = errors.New("日期格式应为 2006-01-02，且开始日期不能晚于结束日期")
+	// ErrStatsRangeTooLong 统计日期范围过长
+	ErrStatsRangeTooLong = fmt.Errorf("统计范围不能超过 %d 天", maxStatsDays)
+)
+
+const (
+	statsDateLayout = "2006-01-02"
+	// maxStatsDays 单次查询的最大天数
+	maxStatsDays = 366
+	// defaultStatsDays 未指定日期范围时统计最近的天数
+	defaultStatsDays = 30
+	// statsSnapshotDays 每天刷新快照的天数（不含当天）
+	statsSnapshotDays = 7
+	// statsSnapshotHour 每天刷新快照的时间（时）
+	statsSnapshotHour = 1
+	// statsSnapshotLockKey 多实例部署时只允许一个实例刷新
+	statsSnapshotLockKey = "gomall:lock:daily_stats"
+	// statsLiveCacheTTL 包含当天的查询结果缓存时间
+	statsLiveCacheTTL = time.Minute
+	// statsHistoryCacheTTL 只包含历史日期的查询结果缓存时间
+	statsHistoryCacheTTL = time.Hour
+	// statsCacheTimeout 读写统计缓存的超时
+	statsCacheTimeout = 200 * time.Millisecond
+)
+
+// StatsService 经营统计服务
+type StatsService struct {
+	statsRepo *repository.StatsRepository
+}
+
+// NewStatsService 创建经营统计服务实例
+func NewStatsService() *StatsService {
+	return &StatsService{
+		statsRepo: repository.NewStatsRepository(),
+	}
+}
+
+// StatsRangeQuery 统计日期范围，日期格式 2006-01-02，默认最近30天
+type StatsRangeQuery struct {
+	Start string `form:"start"`
+	End   string `form:"end"`
+}
+
+// StatsOverviewQuery 经营概览查询参数
+type StatsOverviewQuery struct {
+	StatsRangeQuery
+	Granularity string `form:"granularity" binding:"omitempty,oneof=day week month"` // 默认 day
+}
+
+// TopProductsQuery 商品销售排行查询参数
+type TopProductsQuery struct {
+	StatsRangeQuery
+	By    string `form:"by" binding:"omitempty,oneof=gmv quantity"` // 默认 gmv
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// StatsPoint 一个统计周期的经营指标
+type StatsPoint struct {
+	Period           string  `json:"period"` // 日：2006-01-02；周：周一日期；月：2006-01
+	GMV              float64 `json:"gmv"`
+	PaidOrders       int64   `json:"paid_orders"`
+	AvgOrderValue    float64 `json:"avg_order_value"`
+	NewUsers         int64   `json:"new_users"`
+	CreatedOrders    int64   `json:"created_orders"`
+	CancelledOrders  int64   `json:"cancelled_orders"`
+	CancellationRate float64 `json:"cancellation_rate"` // 取消订单数 / 创建订单数
+}
+
+// StatsOverview 经营概览
+type StatsOverview struct {
+	Start       string       `json:"start"`
+	End         string       `json:"end"`
+	Granularity string       `json:"granularity"`
+	Summary     StatsPoint   `json:"summary"`
+	Series      []StatsPoint `json:"series"`
+}
+
+// SeckillStatResponse 秒杀商品统计，RemainingStock 为 Redis 中的剩余秒杀库存
+type SeckillStatResponse struct {
+	repository.SeckillStat
+	RemainingStock *int `json:"remaining_stock,omitempty"`
+}
+
+// Overview 经营概览：GMV、支付订单数、客单价、新增用户、取消率，按日/周/月汇总
+func (s *StatsService) Overview(ctx context.Context, q *StatsOverviewQuery) (*StatsOverview, error) {
+	start, end, err := parseStatsRange(&q.StatsRangeQuery)
+	if err != nil {
+		return nil, err
+	}
+	granularity := q.Granularity
+	if granularity == "" {
+		granularity = "day"
+	}
+
+	cacheKey := fmt.Sprintf("gomall:stats:overview:%s:%s:%s", start.Format(statsDateLayout), end.Format(statsDateLayout), granularity)
+	var overview StatsOverview
+	if getStatsCache(ctx, cacheKey, &overview) {
+		return &overview, nil
+	}
+
+	days, err := s.dailyStats(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	overview = StatsOverview{
+		Start:       start.Format(statsDateLayout),
+		End:         end.Format(statsDateLayout),
+		Granularity: granularity,
+		Series:      make([]StatsPoint, 0),
+	}
+	total := &StatsPoint{Period: overview.Start + " ~ " + overview.End}
+	var current *StatsPoint
+	for _, day := range days {
+		date, _ := time.ParseInLocation(statsDateLayout, day.StatDate, time.Local)
+		period := statsPeriod(date, granularity)
+		if current == nil || current.Period != period {
+			overview.Series = append(overview.Series, StatsPoint{Period: period})
+			current = &overview.Series[len(overview.Series)-1]
+		}
+		addDailyStat(current, &day)
+		addDailyStat(total, &day)
+	}
+	for i := range overview.Series {
+		finishStatsPoint(&overview.Series[i])
+	}
+	finishStatsPoint(total)
+	overview.Summary = *total
+
+	setStatsCache(ctx, cacheKey, &overview, statsCacheTTL(end))
+	return &overview, nil
+}
+
+// TopProducts 时间段内的商品销售排行
+func (s *StatsService) TopProducts(ctx context.Context, q *TopProductsQuery) ([]repository.ProductSalesStat, error) {
+	start, end, err := parseStatsRange(&q.StatsRangeQuery)
+	if err != nil {
+		return nil, err
+	}
+	by, limit := q.By, q.Limit
+	if by == "" {
+		by = "gmv"
+	}
+	if limit == 0 {
+		limit = 10
+	}
+
+	cacheKey := fmt.Sprintf("gomall:stats:top_products:%s:%s:%s:%d", start.Format(statsDateLayout), end.Format(statsDateLayout), by, limit)
+	var products []repository.ProductSalesStat
+	if getStatsCache(ctx, cacheKey, &products) {
+		return products, nil
+	}
+
+	products, err = s.statsRepo.TopProducts(start, end.AddDate(0, 0, 1), by, limit)
+	if err != nil {
+		return nil, err
+	}
+	setStatsCache(ctx, cacheKey, products, statsCacheTTL(end))
+	return products, nil
+}
+
+// Seckill 时间段内的秒杀结果，按秒杀商品汇总
+func (s *StatsService) Seckill(ctx context.Context, q *StatsRangeQuery) ([]SeckillStatResponse, error) {
+	start, end, err := parseStatsRange(q)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey := fmt.Sprintf("gomall:stats:seckill:%s:%s", start.Format(statsDateLayout), end.Format(statsDateLayout))
+	var responses []SeckillStatResponse
+	if getStatsCache(ctx, cacheKey, &responses) {
+		return responses, nil
+	}
+
+	stats, err := s.statsRepo.SeckillSummary(start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	responses = make([]SeckillStatResponse, len(stats))
+	for i := range stats {
+		responses[i].SeckillStat = stats[i]
+		if redis.Client != nil {
+			if stock, err := redis.GetStockCache(ctx, stats[i].ProductID); err == nil {
+				responses[i].RemainingStock = &stock
+			}
+		}
+	}
+	setStatsCache(ctx, cacheKey, responses, statsCacheTTL(end))
+	return responses, nil
+}
+
+// RunDailySnapshot 每天 statsSnapshotHour 点刷新最近几天的快照，阻塞运行，应在独立协程中调用
+func (s *StatsService) RunDailySnapshot() {
+	for {
+		time.Sleep(time.Until(nextDailyRun(time.Now(), statsSnapshotHour)))
+
+		if err := s.RefreshSnapshots(context.Background(), statsSnapshotDays); err != nil {
+			logger.Error("每日统计快照刷新失败", zap.Error(err))
+			continue
+		}
+		logger.Info("每日统计快照刷新完成")
+	}
+}
+
+// RefreshSnapshots 重新计算昨天起往前 days 天的快照
+func (s *StatsService) RefreshSnapshots(ctx context.Context, days int) error {
+	if redis.Client != nil {
+		locked, err := redis.Client.SetNX(ctx, statsSnapshotLockKey, 1, time.Hour).Result()
+		if err == nil && !locked {
+			return nil
+		}
+		if err == nil {
+			defer redis.Client.Del(ctx, statsSnapshotLockKey)
+		}
+	}
+
+	today := startOfDay(time.Now())
+	for i := 1; i <= days; i++ {
+		day := today.AddDate(0, 0, -i)
+		stat, err := s.statsRepo.ComputeDay(day.Format(statsDateLayout), day, day.AddDate(0, 0, 1))
+		if err != nil {
+			return err
+		}
+		if err := s.statsRepo.UpsertDailyStat(stat); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dailyStats 获取 [start, end] 内每一天的统计
+// 历史日期优先读快照，缺失时实时计算并补写；当天始终实时计算
+func (s *StatsService) dailyStats(start, end time.Time) ([]model.DailyStat, error) {
+	snapshots, err := s.statsRepo.ListDailyStats(start.Format(statsDateLayout), end.Format(statsDateLayout))
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]model.DailyStat, len(snapshots))
+	for _, stat := range snapshots {
+		byDate[stat.StatDate] = stat
+	}
+
+	today := startOfDay(time.Now())
+	var days []model.DailyStat
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(statsDateLayout)
+		if stat, ok := byDate[date]; ok && day.Before(today) {
+			days = append(days, stat)
+			continue
+		}
+
+		stat, err := s.statsRepo.ComputeDay(date, day, day.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, err
+		}
+		if day.Before(today) {
+			if err := s.statsRepo.UpsertDailyStat(stat); err != nil {
+				logger.Warn("补写每日统计快照失败", zap.String("date", date), zap.Error(err))
+			}
+		}
+		days = append(days, *stat)
+	}
+	return days, nil
+}
+
+// parseStatsRange 解析统计日期范围，返回起止日期的零点；未来的日期截止到今天
+func parseStatsRange(q *StatsRangeQuery) (time.Time, time.Time, error) {
+	today := startOfDay(time.Now())
+	end := today
+	if q.End != "" {
+		t, err := time.ParseInLocation(statsDateLayout, q.End, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidStatsRange
+		}
+		if t.Before(today) {
+			end = t
+		}
+	}
+
+	start := end.AddDate(0, 0, -(defaultStatsDays - 1))
+	if q.Start != "" {
+		t, err := time.ParseInLocation(statsDateLayout, q.Start, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, ErrInvalidStatsRange
+		}
+		start = t
+	}
+
+	if start.After(end) {
+		return time.Time{}, time.Time{}, ErrInvalidStatsRange
+	}
+	if start.AddDate(0, 0, maxStatsDays).Before(end) {
+		return time.Time{}, time.Time{}, ErrStatsRangeTooLong
+	}
+	return start, end, nil
+}
+
+// statsPeriod 计算日期所属的统计周期
+func statsPeriod(day time.Time, granularity string) string {
+	switch granularity {
+	case "week":
+		// 周一为一周的第一天
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset).Format(statsDateLayout)
+	case "month":
+		return day.Format("2006-01")
+	default:
+		return day.Format(statsDateLayout)
+	}
+}
+
+// addDailyStat 把一天的统计累加到统计周期
+func addDailyStat(point *StatsPoint, stat *model.DailyStat) {
+	point.GMV += stat.GMV
+	point.PaidOrders += stat.PaidOrders
+	point.NewUsers += stat.NewUsers
+	point.CreatedOrders += stat.CreatedOrders
+	point.CancelledOrders += stat.CancelledOrders
+}
+
+// finishStatsPoint 计算客单价和取消率，并统一保留精度
+func finishStatsPoint(point *StatsPoint) {
+	point.GMV = roundTo(point.GMV, 2)
+	if point.PaidOrders > 0 {
+		point.AvgOrderValue = roundTo(point.GMV/float64(point.PaidOrders), 2)
+	}
+	if point.CreatedOrders > 0 {
+		point.CancellationRate = roundTo(float64(point.CancelledOrders)/float64(point.CreatedOrders), 4)
+	}
+}
+
+// roundTo 四舍五入保留 digits 位小数
+func roundTo(v float64, digits int) float64 {
+	p := math.Pow10(digits)
+	return math.Round(v*p) / p
+}
+
+// startOfDay 当地时间当天零点
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// statsCacheTTL 查询范围包含当天时缓存时间较短
+func statsCacheTTL(end time.Time) time.Duration {
+	if end.Before(startOfDay(time.Now())) {
+		return statsHistoryCacheTTL
+	}
+	return statsLiveCacheTTL
+}
+
+// getStatsCache 读取统计缓存，未命中或 Redis 不可用时返回 false
+func getStatsCache(ctx context.Context, key string, dest interface{}) bool {
+	if redis.Client == nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, statsCacheTimeout)
+	defer cancel()
+
+	data, err := redis.Client.Get(ctx, key).Bytes()
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, dest) == nil
+}
+
+// setStatsCache 写入统计缓存，失败时忽略
+func setStatsCache(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	if redis.Client == nil {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, statsCacheTimeout)
+	defer cancel()
+	redis.Client.Set(ctx, key, data, ttl)
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/redis"
+	"gomall/backend/internal/repository"
+	"gomall/backend/pkg/jwt"
+
+	"go.uber.org/zap"
+)
+
+// Token 签发、轮换与吊销
+// - 登录：签发访问Token和刷新Token，刷新Token属于新建的轮换家族（见 redis/token.go）
+// - 刷新：刷新Token只能使用一次，每次使用都换发新的 Token 对；已轮换的刷新Token再次使用时吊销整个家族
+// - 退出登录 / 删除会话：访问Token的 jti 写入 Redis 黑名单直到 Token 过期，所属会话（刷新Token家族）一并吊销（见 session.go）
+// - 退出所有设备 / 修改密码 / 调整角色 / 禁用或锁定账号：递增用户的 Token 版本，之前签发的 Token 全部失效
+// 每次请求由认证中间件调用 CheckToken 校验。黑名单读取失败时放行并记录日志；
+// Token 版本以数据库为准，Redis 只做缓存，读取失败时拒绝请求
+
+var (
+	// ErrTokenRevoked Token 已被吊销
+	ErrTokenRevoked = errors.New("Token已失效，请重新登录")
+	// ErrRevokeUnavailable Redis 不可用，无法吊销单个 Token
+	ErrRevokeUnavailable = errors.New("退出登录服务不可用")
+	// ErrRefreshTokenInvalid 刷新Token无效、过期或已吊销
+	ErrRefreshTokenInvalid = errors.New("无效的刷新令牌")
+	// ErrRefreshTokenReused 已轮换的刷新Token被再次使用，所属家族已吊销
+	ErrRefreshTokenReused = errors.New("刷新令牌已被使用，请重新登录")
+	// ErrRefreshUnavailable Redis 不可用，无法轮换刷新Token
+	ErrRefreshUnavailable = errors.New("刷新令牌服务不可用")
+)
+
+const (
+	// tokenVersionCacheTTL Token 版本缓存时间
+	tokenVersionCacheTTL = time.Hour
+	// tokenCheckTimeout 单次校验读取 Redis 的超时
+	tokenCheckTimeout = 200 * time.Millisecond
+)
+
+// CheckToken 校验 Token 是否已被吊销，吊销时返回 ErrTokenRevoked；
+// 因账号被禁用、锁定而失效时返回 ErrUserDisabled / ErrPasswordResetRequired
+func CheckToken(ctx context.Context, claims *jwt.Claims) error {
+	if redis.Client != nil && claims.ID != "" {
+		checkCtx, cancel := context.WithTimeout(ctx, tokenCheckTimeout)
+		revoked, err := redis.IsTokenInvalid(checkCtx, claims.ID)
+		cancel()
+		if err != nil {
+			logger.Warn("Token黑名单读取失败", zap.Uint("user_id", claims.UserID), zap.Error(err))
+		} else if revoked {
+			return ErrTokenRevoked
+		}
+	}
+	if redis.Client != nil && claims.Family != "" {
+		checkCtx, cancel := context.WithTimeout(ctx, tokenCheckTimeout)
+		revoked, err := redis.IsSessionRevoked(checkCtx, claims.Family)
+		cancel()
+		if err != nil {
+			logger.Warn("会话吊销状态读取失败", zap.Uint("user_id", claims.UserID), zap.Error(err))
+		} else if revoked {
+			return ErrTokenRevoked
+		}
+	}
+
+	version, err := currentTokenVersion(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrTokenRevoked
+		}
+		return err
+	}
+	if claims.Version != version {
+		// 版本不一致时才读取账号状态，正常请求不增加数据库查询
+		if user, err := repository.NewUserRepository().GetByID(claims.UserID); err == nil {
+			if statusErr := checkUserStatus(user); statusErr != nil {
+				return statusErr
+			}
+		}
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// RevokeToken 吊销单个 Token，直到其过期
+func RevokeToken(ctx context.Context, claims *jwt.Claims) error {
+	if redis.Client == nil {
+		return ErrRevokeUnavailable
+	}
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+	return redis.SetTokenCache(ctx, claims.ID, ttl)
+}
+
+// RevokeAllTokens 递增用户的 Token 版本，使该用户已签发的 Token 全部失效
+// 返回新版本，用于给当前设备签发新 Token
+func RevokeAllTokens(ctx context.Context, userID uint) (int64, error) {
+	version, err := repository.NewUserRepository().IncrTokenVersion(userID)
+	if err != nil {
+		return 0, err
+	}
+	if err := repository.NewSessionRepository().RevokeAll(userID); err != nil {
+		logger.Warn("会话吊销状态写入失败", zap.Uint("user_id", userID), zap.Error(err))
+	}
+	if redis.Client != nil {
+		if err := redis.SetTokenVersion(ctx, userID, version, tokenVersionCacheTTL); err != nil {
+			// 删除缓存兜底，下次请求从数据库读取
+			logger.Warn("Token版本缓存更新失败", zap.Uint("user_id", userID), zap.Error(err))
+			redis.Client.Del(ctx, redis.TokenVersionKey(userID))
+		}
+	}
+	return version, nil
+}
+
+// currentTokenVersion 获取用户当前的 Token 版本，优先读取缓存
+func currentTokenVersion(ctx context.Context, userID uint) (int64, error) {
+	if redis.Client != nil {
+		cacheCtx, cancel := context.WithTimeout(ctx, tokenCheckTimeout)
+		version, err := redis.GetTokenVersion(cacheCtx, userID)
+		cancel()
+		if err == nil {
+			return version, nil
+		}
+	}
+
+	version, err := repository.NewUserRepository().GetTokenVersion(userID)
+	if err != nil {
+		return 0, err
+	}
+	if redis.Client != nil {
+		redis.CacheTokenVersion(ctx, userID, version, tokenVersionCacheTTL)
+	}
+	return version, nil
+}
+
+// IssueTokenPair 为用户签发 Token 对，刷新Token属于新建的轮换家族，同时创建登录会话记录
+// mfa 表示本次登录是否通过了两步验证，写入 Token 供管理后台校验
+// Redis 不可用时只签发访问Token（RefreshToken 为空），过期后需要重新登录
+func IssueTokenPair(ctx context.Context, user *model.User, mfa bool, client ClientInfo) (*jwt.TokenPair, error) {
+	jwtUtil := jwt.NewJWT()
+	family := jwt.NewFamilyID()
+	pair, err := jwtUtil.GenerateTokenPair(user.ID, user.Username, user.Email, user.Role, user.TokenVersion, family, mfa)
+	if err != nil {
+		return nil, err
+	}
+	sessionTTL := jwtUtil.RefreshTTL()
+	if redis.Client == nil {
+		sessionTTL = jwtUtil.AccessTTL()
+	}
+	createSession(user, family, client, time.Now().Add(sessionTTL))
+	if redis.Client == nil {
+		pair.RefreshToken = ""
+		return pair, nil
+	}
+
+	refreshClaims, err := jwtUtil.ParseRefreshToken(pair.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := redis.SetRefreshFamily(ctx, family, refreshClaims.ID, jwtUtil.RefreshTTL()); err != nil {
+		logger.Warn("刷新令牌登记失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		pair.RefreshToken = ""
+	}
+	return pair, nil
+}
+
+// RotateRefreshToken 使用刷新Token换发新的 Token 对，旧的刷新Token随即失效
+// 角色和 Token 版本从数据库重新读取，两步验证状态沿用本次登录
+func RotateRefreshToken(ctx context.Context, refreshToken string) (*jwt.TokenPair, error) {
+	jwtUtil := jwt.NewJWT()
+	claims, err := jwtUtil.ParseRefreshToken(refreshToken)
+	if err != nil || claims.Family == "" {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if redis.Client == nil {
+		return nil, ErrRefreshUnavailable
+	}
+
+	// 已退出所有设备、修改过密码的刷新Token不能再使用
+	if err := CheckToken(ctx, claims); err != nil {
+		if errors.Is(err, ErrTokenRevoked) {
+			return nil, ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	user, err := repository.NewUserRepository().GetByID(claims.UserID)
+	if err != nil {
+		return nil, ErrRefreshTokenInvalid
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+	pair, err := jwtUtil.GenerateTokenPair(user.ID, user.Username, user.Email, user.Role, user.TokenVersion, claims.Family, claims.MFA)
+	if err != nil {
+		return nil, err
+	}
+	newClaims, err := jwtUtil.ParseRefreshToken(pair.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := redis.RotateRefreshFamily(ctx, claims.Family, claims.ID, newClaims.ID, jwtUtil.RefreshTTL())
+	if err != nil {
+		return nil, err
+	}
+	switch result {
+	case 1:
+		if err := repository.NewSessionRepository().Extend(claims.Family, time.Now().Add(jwtUtil.RefreshTTL())); err != nil {
+			logger.Warn("会话过期时间更新失败", zap.Uint("user_id", claims.UserID), zap.Error(err))
+		}
+		return pair, nil
+	case -1:
+		logger.Warn("检测到刷新令牌重复使用，已吊销该登录的全部刷新令牌",
+			zap.Uint("user_id", claims.UserID), zap.String("family", claims.Family))
+		if err := EndSession(ctx, claims.Family); err != nil {
+			logger.Warn("会话吊销失败", zap.String("family", claims.Family), zap.Error(err))
+		}
+		return nil, ErrRefreshTokenReused
+	default:
+		return nil, ErrRefreshTokenInvalid
+	}
+}
+
+// RevokeRefreshToken 吊销刷新Token所属的家族，只处理属于 userID 的有效刷新Token
+func RevokeRefreshToken(ctx context.Context, userID uint, refreshToken string) error {
+	claims, err := jwt.NewJWT().ParseRefreshToken(refreshToken)
+	if err != nil || claims.UserID != userID || claims.Family == "" {
+		return nil
+	}
+	if redis.Client == nil {
+		return ErrRevokeUnavailable
+	}
+	return redis.DeleteRefreshFamily(ctx, claims.Family)
+}
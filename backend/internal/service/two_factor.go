@@ -0,0 +1,441 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gomall/backend/internal/config"
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/metrics"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/redis"
+	"gomall/backend/internal/repository"
+	"gomall/backend/pkg/jwt"
+	"gomall/backend/pkg/password"
+	"gomall/backend/pkg/totp"
+
+	"go.uber.org/zap"
+)
+
+// 两步验证（TOTP，RFC 6238）
+// 绑定：Setup 生成密钥和 otpauth:// 链接，用户扫码后提交一次验证码启用，同时生成一次性备用码。
+// 登录：已启用两步验证的账号通过密码或短信验证码后只拿到预认证Token（有效期 5 分钟），
+// 提交动态验证码或备用码后才签发 Token 对，Token 中带 mfa 标记。
+// 每个时间步的验证码只能使用一次；验证码错误计入登录失败次数（见 login_guard.go）。
+// security.require_admin_2fa 开启时，后台角色的 Token 必须带 mfa 标记才能访问管理接口
+
+var (
+	// ErrTwoFactorAlreadyEnabled 已启用两步验证
+	ErrTwoFactorAlreadyEnabled = errors.New("已启用两步验证")
+	// ErrTwoFactorNotEnabled 未启用两步验证
+	ErrTwoFactorNotEnabled = errors.New("未启用两步验证")
+	// ErrTwoFactorNotSetup 尚未生成两步验证密钥
+	ErrTwoFactorNotSetup = errors.New("请先获取两步验证密钥")
+	// ErrTwoFactorCodeInvalid 验证码错误或已使用
+	ErrTwoFactorCodeInvalid = errors.New("两步验证码错误或已使用")
+	// ErrTwoFactorSessionInvalid 预认证Token无效、已使用或已过期
+	ErrTwoFactorSessionInvalid = errors.New("登录已失效，请重新登录")
+	// ErrTwoFactorRequired 当前账号必须启用两步验证
+	ErrTwoFactorRequired = errors.New("管理账号必须启用两步验证")
+)
+
+const (
+	// preAuthTTL 预认证Token有效期
+	preAuthTTL = 5 * time.Minute
+	// totpSkew 允许的时钟偏差（前后各一个时间步）
+	totpSkew = 1
+	// backupCodeCount 每次生成的备用码数量
+	backupCodeCount = 10
+)
+
+// twoFactorSettings 两步验证相关配置
+type twoFactorSettings struct {
+	issuer       string
+	requireAdmin bool
+}
+
+// loadTwoFactorSettings 读取 security 配置，未配置时使用默认值
+func loadTwoFactorSettings() twoFactorSettings {
+	settings := twoFactorSettings{issuer: "GoMall"}
+	securityConfig := config.GetSecurity()
+	if securityConfig == nil {
+		return settings
+	}
+	if issuer := os.ExpandEnv(securityConfig.GetString("totp_issuer")); issuer != "" {
+		settings.issuer = issuer
+	}
+	settings.requireAdmin = securityConfig.GetBool("require_admin_2fa")
+	return settings
+}
+
+// RequiresTwoFactor 角色访问管理后台时是否必须通过两步验证
+func RequiresTwoFactor(role int) bool {
+	if role == 0 || role == model.RoleUser {
+		return false
+	}
+	return loadTwoFactorSettings().requireAdmin
+}
+
+// LoginResult 登录结果
+// 账号启用了两步验证时 TokenPair 为空，TwoFactor 中为预认证Token，需调用 TwoFactorService.VerifyLogin 完成登录
+type LoginResult struct {
+	TokenPair *jwt.TokenPair
+	User      *UserResponse
+	TwoFactor *TwoFactorChallenge
+}
+
+// TwoFactorChallenge 两步验证登录挑战
+type TwoFactorChallenge struct {
+	PreAuthToken string `json:"pre_auth_token"`
+	ExpiresIn    int    `json:"expires_in"` // 过期时间（秒）
+}
+
+// TwoFactorStatus 两步验证状态
+type TwoFactorStatus struct {
+	Enabled              bool       `json:"enabled"`
+	Required             bool       `json:"required"` // 当前账号是否被强制要求启用
+	EnabledAt            *time.Time `json:"enabled_at,omitempty"`
+	BackupCodesRemaining int64      `json:"backup_codes_remaining"`
+}
+
+// TwoFactorSetupResponse 绑定两步验证时返回的密钥
+type TwoFactorSetupResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"` // otpauth:// 链接，前端渲染成二维码
+}
+
+// TwoFactorEnableResponse 启用两步验证结果
+type TwoFactorEnableResponse struct {
+	BackupCodes []string       `json:"backup_codes"` // 只返回这一次，请提示用户妥善保存
+	TokenPair   *jwt.TokenPair `json:"-"`
+}
+
+// TwoFactorCodeRequest 提交动态验证码
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,max=20"`
+}
+
+// DisableTwoFactorRequest 关闭两步验证请求
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required,max=20"` // 动态验证码或备用码
+}
+
+// TwoFactorLoginRequest 两步验证登录请求
+type TwoFactorLoginRequest struct {
+	PreAuthToken string `json:"pre_auth_token" binding:"required"`
+	Code         string `json:"code" binding:"required,max=20"` // 动态验证码或备用码
+}
+
+// TwoFactorService 两步验证服务
+type TwoFactorService struct {
+	userRepo *repository.UserRepository
+	tfaRepo  *repository.TwoFactorRepository
+}
+
+// NewTwoFactorService 创建两步验证服务
+func NewTwoFactorService() *TwoFactorService {
+	return &TwoFactorService{
+		userRepo: repository.NewUserRepository(),
+		tfaRepo:  repository.NewTwoFactorRepository(),
+	}
+}
+
+// Status 获取两步验证状态
+func (s *TwoFactorService) Status(userID uint) (*TwoFactorStatus, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	status := &TwoFactorStatus{Required: RequiresTwoFactor(user.Role)}
+
+	record, err := s.tfaRepo.GetTOTP(userID)
+	if errors.Is(err, repository.ErrTOTPNotFound) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.EnabledAt == nil {
+		return status, nil
+	}
+	status.Enabled = true
+	status.EnabledAt = record.EnabledAt
+	if status.BackupCodesRemaining, err = s.tfaRepo.CountBackupCodes(userID); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Setup 生成新的 TOTP 密钥，之前未完成的绑定作废
+func (s *TwoFactorService) Setup(userID uint) (*TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	saved, err := s.tfaRepo.SavePendingTOTP(userID, secret)
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	return &TwoFactorSetupResponse{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(loadTwoFactorSettings().issuer, user.Username, secret),
+	}, nil
+}
+
+// Enable 校验验证码并启用两步验证，返回备用码
+// 启用后其他设备上的 Token 全部失效，当前设备换发带 mfa 标记的新 Token 对
+func (s *TwoFactorService) Enable(ctx context.Context, userID uint, code string, client ClientInfo) (*TwoFactorEnableResponse, error) {
+	record, err := s.tfaRepo.GetTOTP(userID)
+	if errors.Is(err, repository.ErrTOTPNotFound) {
+		return nil, ErrTwoFactorNotSetup
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.EnabledAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(record.Secret, normalizeTwoFactorCode(code), time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrTwoFactorCodeInvalid
+	}
+	codes, hashes, err := newBackupCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := s.tfaRepo.EnableTOTP(userID, step, hashes)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if version, err := RevokeAllTokens(ctx, userID); err != nil {
+		logger.Error("启用两步验证后Token吊销失败", zap.Uint("user_id", userID), zap.Error(err))
+	} else {
+		user.TokenVersion = version
+	}
+	tokenPair, err := IssueTokenPair(ctx, user, true, client)
+	if err != nil {
+		return nil, errors.New("Token生成失败")
+	}
+	return &TwoFactorEnableResponse{BackupCodes: codes, TokenPair: tokenPair}, nil
+}
+
+// Disable 校验密码和验证码后关闭两步验证
+// security.require_admin_2fa 开启时后台角色不能关闭
+func (s *TwoFactorService) Disable(userID uint, req *DisableTwoFactorRequest) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if RequiresTwoFactor(user.Role) {
+		return ErrTwoFactorRequired
+	}
+	if !password.CheckPassword(req.Password, user.Password) {
+		return ErrInvalidPassword
+	}
+	if err := s.verifyCode(userID, req.Code, true); err != nil {
+		return err
+	}
+	return s.tfaRepo.DeleteTOTP(userID)
+}
+
+// RegenerateBackupCodes 校验动态验证码后重新生成备用码，旧的备用码全部作废
+func (s *TwoFactorService) RegenerateBackupCodes(userID uint, code string) ([]string, error) {
+	if err := s.verifyCode(userID, code, false); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newBackupCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tfaRepo.ReplaceBackupCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// VerifyLogin 使用预认证Token和动态验证码（或备用码）完成登录
+func (s *TwoFactorService) VerifyLogin(ctx context.Context, req *TwoFactorLoginRequest, client ClientInfo) (*LoginResult, error) {
+	claims, err := jwt.ParseActionToken(req.PreAuthToken, jwt.SubjectTwoFactor)
+	if err != nil {
+		return nil, ErrTwoFactorSessionInvalid
+	}
+	if redis.Client != nil {
+		used, err := redis.IsTokenInvalid(ctx, claims.ID)
+		if err != nil {
+			logger.Warn("预认证Token状态读取失败", zap.Uint("user_id", claims.UserID), zap.Error(err))
+		} else if used {
+			return nil, ErrTwoFactorSessionInvalid
+		}
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrTwoFactorSessionInvalid
+		}
+		return nil, err
+	}
+	// 签发后修改过密码、退出过所有设备的预认证Token作废
+	if claims.Binding != strconv.FormatInt(user.TokenVersion, 10) {
+		return nil, ErrTwoFactorSessionInvalid
+	}
+
+	guard := newLoginGuard()
+	if err := guard.check(ctx, user.ID, client.IP); err != nil {
+		return nil, err
+	}
+	if err := s.verifyCode(user.ID, req.Code, true); err != nil {
+		if !errors.Is(err, ErrTwoFactorCodeInvalid) {
+			return nil, err
+		}
+		remaining, lockErr := guard.fail(ctx, user.ID, client.IP)
+		if lockErr != nil {
+			return nil, lockErr
+		}
+		if remaining > 0 {
+			return nil, fmt.Errorf("%w，还可尝试%d次", ErrTwoFactorCodeInvalid, remaining)
+		}
+		return nil, err
+	}
+	guard.succeed(ctx, user.ID)
+
+	if redis.Client != nil && claims.ExpiresAt != nil {
+		if err := redis.SetTokenCache(ctx, claims.ID, time.Until(claims.ExpiresAt.Time)); err != nil {
+			logger.Warn("预认证Token作废失败", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+	}
+
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+	tokenPair, err := IssueTokenPair(ctx, user, true, client)
+	if err != nil {
+		return nil, errors.New("Token生成失败")
+	}
+	metrics.RecordUserLogin()
+	return &LoginResult{TokenPair: tokenPair, User: newUserResponse(user)}, nil
+}
+
+// completeLogin 第一步认证（密码、短信验证码）通过后调用
+// 启用了两步验证时返回预认证Token，否则直接签发 Token 对
+func (s *TwoFactorService) completeLogin(ctx context.Context, user *model.User, client ClientInfo) (*LoginResult, error) {
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+	record, err := s.tfaRepo.GetTOTP(user.ID)
+	if err != nil && !errors.Is(err, repository.ErrTOTPNotFound) {
+		return nil, err
+	}
+	if err == nil && record.EnabledAt != nil {
+		token, err := jwt.GenerateActionToken(jwt.SubjectTwoFactor, user.ID, user.Email, strconv.FormatInt(user.TokenVersion, 10), preAuthTTL)
+		if err != nil {
+			return nil, errors.New("Token生成失败")
+		}
+		return &LoginResult{
+			User:      newUserResponse(user),
+			TwoFactor: &TwoFactorChallenge{PreAuthToken: token, ExpiresIn: int(preAuthTTL.Seconds())},
+		}, nil
+	}
+
+	tokenPair, err := IssueTokenPair(ctx, user, false, client)
+	if err != nil {
+		return nil, errors.New("Token生成失败")
+	}
+	metrics.RecordUserLogin()
+	return &LoginResult{TokenPair: tokenPair, User: newUserResponse(user)}, nil
+}
+
+// verifyCode 校验动态验证码，allowBackup 为 true 时也接受备用码；验证码和备用码都只能使用一次
+func (s *TwoFactorService) verifyCode(userID uint, code string, allowBackup bool) error {
+	record, err := s.tfaRepo.GetTOTP(userID)
+	if errors.Is(err, repository.ErrTOTPNotFound) {
+		return ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return err
+	}
+	if record.EnabledAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = normalizeTwoFactorCode(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(record.Secret, code, time.Now(), totpSkew)
+		if !ok {
+			return ErrTwoFactorCodeInvalid
+		}
+		consumed, err := s.tfaRepo.ConsumeTOTPStep(userID, step)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			return ErrTwoFactorCodeInvalid
+		}
+		return nil
+	}
+
+	if !allowBackup {
+		return ErrTwoFactorCodeInvalid
+	}
+	consumed, err := s.tfaRepo.ConsumeBackupCode(userID, hashBackupCode(userID, code))
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrTwoFactorCodeInvalid
+	}
+	logger.Info("使用两步验证备用码", zap.Uint("user_id", userID))
+	return nil
+}
+
+// newBackupCodes 生成备用码，返回展示给用户的明文（xxxx-xxxx）和入库的哈希
+func newBackupCodes(userID uint) ([]string, []string, error) {
+	codes := make([]string, 0, backupCodeCount)
+	hashes := make([]string, 0, backupCodeCount)
+	for i := 0; i < backupCodeCount; i++ {
+		raw, err := randomHex(4)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, raw[:4]+"-"+raw[4:])
+		hashes = append(hashes, hashBackupCode(userID, raw))
+	}
+	return codes, hashes, nil
+}
+
+// hashBackupCode 备用码哈希，混入用户ID，数据库中不保存明文
+func hashBackupCode(userID uint, code string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", userID, code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeTwoFactorCode 去掉空格和连字符并转为小写，兼容用户手动输入的格式
+func normalizeTwoFactorCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gomall/backend/internal/logger"
+	"gomall/backend/internal/model"
+	"gomall/backend/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// 后台用户管理
+// 账号状态：正常、已禁用（管理员启用后恢复）、已锁定（管理员要求重置密码，用户通过找回密码邮件重置后自动恢复）。
+// 禁用、锁定时递增 Token 版本，已签发的 Token 全部失效；之后携带旧 Token 的请求由 CheckToken 返回对应的状态错误，
+// 登录（密码、短信验证码、两步验证）在签发 Token 前调用 checkUserStatus。
+// 每次管理操作写入 admin_audit_logs，状态变更与日志在同一事务中完成
+
+var (
+	// ErrPasswordResetRequired 账号被管理员锁定，需要重置密码
+	ErrPasswordResetRequired = errors.New("账号已锁定，请通过找回密码重置密码后登录")
+	// ErrUserStatusConflict 账号当前状态不允许该操作
+	ErrUserStatusConflict = errors.New("账号当前状态不允许该操作")
+	// ErrAdminSelfOperation 不能对自己的账号执行管理操作
+	ErrAdminSelfOperation = errors.New("不能对自己的账号执行该操作")
+	// ErrAdminTargetForbidden 只有超级管理员可以管理超级管理员账号
+	ErrAdminTargetForbidden = errors.New("无权操作超级管理员账号")
+)
+
+// checkUserStatus 账号不是正常状态时返回对应的错误
+func checkUserStatus(user *model.User) error {
+	switch user.Status {
+	case model.UserStatusDisabled:
+		return ErrUserDisabled
+	case model.UserStatusLocked:
+		return ErrPasswordResetRequired
+	default:
+		return nil
+	}
+}
+
+// AdminOperator 执行管理操作的管理员，写入审计日志
+type AdminOperator struct {
+	UserID uint
+	Role   int
+	IP     string
+}
+
+// AdminUserStatusRequest 禁用、启用、重置密码请求
+type AdminUserStatusRequest struct {
+	Reason string `json:"reason" binding:"max=200"`
+}
+
+// AdminUserResponse 后台用户信息
+type AdminUserResponse struct {
+	UserResponse
+	CreatedAt string `json:"created_at"`
+}
+
+// UserAdminService 后台用户管理服务
+type UserAdminService struct {
+	userRepo  *repository.UserRepository
+	auditRepo *repository.AuditLogRepository
+}
+
+// NewUserAdminService 创建后台用户管理服务
+func NewUserAdminService() *UserAdminService {
+	return &UserAdminService{
+		userRepo:  repository.NewUserRepository(),
+		auditRepo: repository.NewAuditLogRepository(),
+	}
+}
+
+// ListUsers 分页查询用户
+func (s *UserAdminService) ListUsers(query repository.UserQuery, page, pageSize int) ([]AdminUserResponse, int64, error) {
+	users, total, err := s.userRepo.Search(query, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]AdminUserResponse, 0, len(users))
+	for i := range users {
+		result = append(result, AdminUserResponse{
+			UserResponse: *newUserResponse(&users[i]),
+			CreatedAt:    users[i].CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return result, total, nil
+}
+
+// UserOrders 查询指定用户的订单
+func (s *UserAdminService) UserOrders(userID uint, page, pageSize int) ([]OrderResponse, int64, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, 0, err
+	}
+	orders, total := NewOrderService().GetOrderList(userID, page, pageSize)
+	return orders, total, nil
+}
+
+// Disable 禁用账号，该用户已签发的 Token 全部失效
+func (s *UserAdminService) Disable(ctx context.Context, op AdminOperator, userID uint, reason string) error {
+	return s.changeStatus(ctx, op, userID,
+		[]int{model.UserStatusActive, model.UserStatusLocked}, model.UserStatusDisabled,
+		model.AuditActionUserDisable, reason)
+}
+
+// Enable 启用被禁用或锁定的账号
+func (s *UserAdminService) Enable(ctx context.Context, op AdminOperator, userID uint, reason string) error {
+	return s.changeStatus(ctx, op, userID,
+		[]int{model.UserStatusDisabled, model.UserStatusLocked}, model.UserStatusActive,
+		model.AuditActionUserEnable, reason)
+}
+
+// ForcePasswordReset 锁定账号并发送重置密码邮件，用户重置密码后账号恢复正常
+// 返回邮件是否已发送；手机号注册的占位邮箱无法接收邮件，需要用户联系客服后由管理员启用
+func (s *UserAdminService) ForcePasswordReset(ctx context.Context, op AdminOperator, userID uint, reason string) (bool, error) {
+	if err := s.changeStatus(ctx, op, userID,
+		[]int{model.UserStatusActive}, model.UserStatusLocked,
+		model.AuditActionUserResetPassword, reason); err != nil {
+		return false, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return false, err
+	}
+	if IsPlaceholderEmail(user.Email) {
+		return false, nil
+	}
+	if err := NewEmailService().sendPasswordReset(ctx, user.Email); err != nil {
+		logger.Warn("重置密码邮件发送失败", zap.Uint("user_id", userID), zap.Error(err))
+		return false, nil
+	}
+	return true, nil
+}
+
+// Unlock 解除登录失败导致的锁定并写入审计日志，返回解锁前是否处于锁定状态
+func (s *UserAdminService) Unlock(ctx context.Context, op AdminOperator, userID uint) (bool, error) {
+	wasLocked, err := UnlockAccount(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	s.audit(op, model.AuditActionUserUnlock, userID, fmt.Sprintf("was_locked=%t", wasLocked))
+	return wasLocked, nil
+}
+
+// ListAuditLogs 分页查询用户管理相关的审计日志
+func (s *UserAdminService) ListAuditLogs(query repository.AuditLogQuery, page, pageSize int) ([]model.AdminAuditLog, int64, error) {
+	query.TargetType = model.AuditTargetUser
+	return s.auditRepo.List(query, page, pageSize)
+}
+
+// changeStatus 校验操作权限后变更账号状态，状态变更与审计日志在同一事务中写入
+func (s *UserAdminService) changeStatus(ctx context.Context, op AdminOperator, userID uint, from []int, to int, action, reason string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.ID == op.UserID {
+		return ErrAdminSelfOperation
+	}
+	if user.Role == model.RoleAdmin && op.Role != model.RoleAdmin {
+		return ErrAdminTargetForbidden
+	}
+
+	changed, err := s.userRepo.ChangeStatus(userID, from, to, &model.AdminAuditLog{
+		OperatorID: op.UserID,
+		Action:     action,
+		TargetType: model.AuditTargetUser,
+		TargetID:   userID,
+		Detail:     statusDetail(user.Status, to, reason),
+		IP:         op.IP,
+	})
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return ErrUserStatusConflict
+	}
+
+	if to != model.UserStatusActive {
+		if _, err := RevokeAllTokens(ctx, userID); err != nil {
+			return err
+		}
+	}
+	logger.Info("后台变更用户状态", zap.Uint("operator_id", op.UserID), zap.Uint("user_id", userID),
+		zap.String("action", action), zap.Int("status", to))
+	return nil
+}
+
+// audit 写入不涉及数据库状态变更的操作日志，失败只记录日志
+func (s *UserAdminService) audit(op AdminOperator, action string, userID uint, detail string) {
+	err := s.auditRepo.Create(&model.AdminAuditLog{
+		OperatorID: op.UserID,
+		Action:     action,
+		TargetType: model.AuditTargetUser,
+		TargetID:   userID,
+		Detail:     detail,
+		IP:         op.IP,
+	})
+	if err != nil {
+		logger.Error("审计日志写入失败", zap.Uint("operator_id", op.UserID), zap.String("action", action), zap.Error(err))
+	}
+}
+
+// statusDetail 审计日志中记录的状态变更说明
+func statusDetail(from, to int, reason string) string {
+	detail := fmt.Sprintf("status %d -> %d", from, to)
+	if reason != "" {
+		detail += "; reason: " + reason
+	}
+	return detail
+}
@@ -217,15 +217,18 @@ func (s *WeChatPayService) PayNotify(ctx context.Context, data []byte) (*PayNoti
 			}, nil
 		}
 
-		// 更新订单状态为已支付
-		order.Status = 2 // 已支付
-		order.PayType = 2 // 微信支付
-		if err := s.orderRepo.Update(order); err != nil {
+		// 更新订单状态为已支付（微信支付）
+		// 微信会重复通知，只有首次把待支付订单改为已支付时才累计销量
+		paid, err := s.orderRepo.MarkPaid(order, 2, time.Now())
+		if err != nil {
 			return &PayNotifyResponse{
 				ReturnCode: "FAIL",
 				ReturnMsg:  "订单更新失败",
 			}, nil
 		}
+		if paid {
+			recordOrderSales(repository.NewProductRepository(), order)
+		}
 	}
 
 	return &PayNotifyResponse{
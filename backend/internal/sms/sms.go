@@ -0,0 +1,96 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"gomall/backend/internal/config"
+	"gomall/backend/internal/logger"
+
+	"go.uber.org/zap"
+)
+
+// SmsSender 短信发送接口
+// 内置 ConsoleSender（写入日志，开发环境使用）和 MockSender（保存在内存中，供测试读取），
+// 接入短信服务商时实现该接口并在 Init 中注册
+type SmsSender interface {
+	Send(ctx context.Context, phone, content string) error
+}
+
+// 发送方式，对应 sms.driver
+const (
+	DriverConsole = "console"
+	DriverMock    = "mock"
+)
+
+// Default 全局短信发送器，Init 之前为 ConsoleSender
+var Default SmsSender = NewConsoleSender()
+
+// Init 按 sms.driver 初始化全局短信发送器，未配置时使用 ConsoleSender
+func Init() error {
+	driver := ""
+	if smsConfig := config.GetSms(); smsConfig != nil {
+		driver = strings.ToLower(smsConfig.GetString("driver"))
+	}
+
+	switch driver {
+	case "", DriverConsole:
+		Default = NewConsoleSender()
+	case DriverMock:
+		Default = NewMockSender()
+	default:
+		return fmt.Errorf("不支持的短信发送方式: %s", driver)
+	}
+	return nil
+}
+
+// Send 使用全局短信发送器发送短信
+func Send(ctx context.Context, phone, content string) error {
+	return Default.Send(ctx, phone, content)
+}
+
+// ConsoleSender 只把短信内容写入日志，不实际发送
+type ConsoleSender struct{}
+
+// NewConsoleSender 创建日志短信发送器
+func NewConsoleSender() *ConsoleSender {
+	return &ConsoleSender{}
+}
+
+// Send 记录短信内容
+func (s *ConsoleSender) Send(ctx context.Context, phone, content string) error {
+	logger.Info("短信（未实际发送）", zap.String("phone", phone), zap.String("content", content))
+	return nil
+}
+
+// MockSender 将短信保存在内存中
+type MockSender struct {
+	mu       sync.Mutex
+	messages map[string][]string
+}
+
+// NewMockSender 创建内存短信发送器
+func NewMockSender() *MockSender {
+	return &MockSender{messages: make(map[string][]string)}
+}
+
+// Send 保存短信内容
+func (s *MockSender) Send(ctx context.Context, phone, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages[phone] = append(s.messages[phone], content)
+	return nil
+}
+
+// Last 返回发送到 phone 的最后一条短信
+func (s *MockSender) Last(phone string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := s.messages[phone]
+	if len(messages) == 0 {
+		return "", false
+	}
+	return messages[len(messages)-1], true
+}
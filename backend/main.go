@@ -33,15 +33,19 @@ import (
 	"os/signal"    // 信号处理
 	"syscall"       // 系统调用信号常量
 
+	"gomall/backend/internal/cache"      // 二级缓存（本地LRU + Redis）
 	"gomall/backend/internal/config"     // 配置管理模块
 	"gomall/backend/internal/database"    // 数据库连接模块
 	"gomall/backend/internal/logger"      // 日志模块
+	"gomall/backend/internal/mailer"      // 邮件发送
 	"gomall/backend/internal/middleware"  // HTTP中间件
 	"gomall/backend/internal/rabbitmq"    // RabbitMQ消息队列
 	redispkg "gomall/backend/internal/redis" // Redis缓存（重命名避免冲突）
 	"gomall/backend/internal/router"      // 路由配置
 	"gomall/backend/internal/service"     // 业务逻辑层
+	"gomall/backend/internal/sms"         // 短信发送
 	"gomall/backend/internal/tracing"    // 链路追踪
+	"gomall/backend/pkg/jwt"             // JWT签名密钥
 
 	"github.com/gin-gonic/gin"   // Gin Web框架
 	"go.uber.org/zap"           // Uber Zap日志库
@@ -104,6 +108,21 @@ func main() {
 	// zap.String 记录字符串类型的字段
 	logger.Info("日志系统初始化成功", zap.String("env", *env))
 
+	// 加载 JWT 签名密钥，密钥配置错误时直接退出
+	if err := jwt.LoadKeys(); err != nil {
+		logger.Fatal("JWT密钥加载失败", zap.Error(err))
+	}
+
+	// 初始化邮件发送器（邮箱验证、找回密码），配置错误时直接退出
+	if err := mailer.Init(); err != nil {
+		logger.Fatal("邮件发送器初始化失败", zap.Error(err))
+	}
+
+	// 初始化短信发送器（验证码登录、绑定手机）
+	if err := sms.Init(); err != nil {
+		logger.Fatal("短信发送器初始化失败", zap.Error(err))
+	}
+
 	// ==================== 第五步：初始化数据库 ====================
 	// MySQL + GORM 作为主数据库
 	logger.Info("正在连接数据库...")
@@ -131,6 +150,9 @@ func main() {
 	} else {
 		defer redispkg.Close()
 		logger.Info("Redis连接成功")
+
+		// 订阅缓存失效广播，多实例部署时同步清理本地缓存
+		go cache.StartInvalidationListener(context.Background())
 	}
 
 	// ==================== 第七步：初始化RabbitMQ（可选）====================
@@ -208,6 +230,28 @@ func main() {
 		seckillSvc.ProcessSeckillOrders()
 	}()
 
+	// 根据商品表构建布隆过滤器，拦截不存在的商品ID
+	go func() {
+		count, err := service.NewProductService().RebuildBloom(context.Background())
+		if err != nil {
+			logger.Warn("商品布隆过滤器构建失败", zap.Error(err))
+			return
+		}
+		logger.Info("商品布隆过滤器构建完成", zap.Int("count", count))
+	}()
+
+	// 启动商品定时上下架协程
+	go service.NewProductService().RunScheduler()
+
+	// 启动商品销量榜每日重建协程
+	go service.NewProductService().RunRankRebuilder()
+
+	// 启动商品推荐每日计算协程
+	go service.NewRecommendService().RunDaily()
+
+	// 启动每日经营统计快照协程
+	go service.NewStatsService().RunDailySnapshot()
+
 	// 启动订单消费者协程
 	// 这个协程负责从RabbitMQ队列中消费订单消息
 	go func() {
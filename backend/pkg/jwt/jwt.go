@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -15,17 +17,33 @@ var (
 	ErrInvalidToken = errors.New("token无效")
 )
 
+// Token 类型，写入 Subject
+const (
+	SubjectAccess  = "access"
+	SubjectRefresh = "refresh"
+
+	// 一次性操作Token，通过邮件发送
+	SubjectEmailVerify   = "email_verify"
+	SubjectPasswordReset = "password_reset"
+
+	// 两步验证登录的预认证Token，只能用于提交动态验证码
+	SubjectTwoFactor = "2fa_pending"
+)
+
 // Claims JWT载荷
 type Claims struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Role     int    `json:"role"` // 角色ID，对应 model.User.Role
+	Version  int64  `json:"ver"`  // 签发时用户的 Token 版本，版本递增后旧 Token 全部失效
+	Family   string `json:"fam,omitempty"` // 所属的登录会话（刷新Token轮换家族），同一次登录签发的 Token 共用
+	MFA      bool   `json:"mfa,omitempty"` // 本次登录是否通过了两步验证
 	jwt.RegisteredClaims
 }
 
 // JWT JWT工具类
 type JWT struct {
-	secretKey       []byte
 	expireHours     int
 	refreshHours    int  // refresh token 过期时间（小时）
 }
@@ -45,14 +63,14 @@ func NewJWT() *JWT {
 	}
 
 	return &JWT{
-		secretKey:    []byte(jwtConfig.GetString("secret")),
 		expireHours:  expireHours,
 		refreshHours: refreshHours,
 	}
 }
 
 // GenerateToken 生成访问Token（短有效期）
-func (j *JWT) GenerateToken(userID uint, username, email string) (string, error) {
+// family 为所属登录会话，删除会话时该会话的访问Token一并失效
+func (j *JWT) GenerateToken(userID uint, username, email string, role int, version int64, family string, mfa bool) (string, error) {
 	nowTime := time.Now()
 	expireTime := nowTime.Add(time.Duration(j.expireHours) * time.Hour)
 
@@ -60,20 +78,25 @@ func (j *JWT) GenerateToken(userID uint, username, email string) (string, error)
 		UserID:   userID,
 		Username: username,
 		Email:    email,
+		Role:     role,
+		Version:  version,
+		Family:   family,
+		MFA:      mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newTokenID(),
 			ExpiresAt: jwt.NewNumericDate(expireTime),
 			IssuedAt:  jwt.NewNumericDate(nowTime),
 			Issuer:    "gomall",
-			Subject:   "access",
+			Subject:   SubjectAccess,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secretKey)
+	return sign(claims)
 }
 
 // GenerateRefreshToken 生成刷新Token（长有效期）
-func (j *JWT) GenerateRefreshToken(userID uint, username, email string) (string, error) {
+// family 为轮换家族ID，登录时用 NewFamilyID 生成，轮换时沿用；mfa 表示本次登录是否通过了两步验证，轮换时沿用
+func (j *JWT) GenerateRefreshToken(userID uint, username, email string, role int, version int64, family string, mfa bool) (string, error) {
 	nowTime := time.Now()
 	expireTime := nowTime.Add(time.Duration(j.refreshHours) * time.Hour)
 
@@ -81,32 +104,37 @@ func (j *JWT) GenerateRefreshToken(userID uint, username, email string) (string,
 		UserID:   userID,
 		Username: username,
 		Email:    email,
+		Role:     role,
+		Version:  version,
+		Family:   family,
+		MFA:      mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newTokenID(),
 			ExpiresAt: jwt.NewNumericDate(expireTime),
 			IssuedAt:  jwt.NewNumericDate(nowTime),
 			Issuer:    "gomall",
-			Subject:   "refresh",
+			Subject:   SubjectRefresh,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secretKey)
+	return sign(claims)
 }
 
-// GenerateTokenPair 生成Token对（access + refresh）
+// TokenPair Token对（access + refresh）
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	ExpiresIn   int    `json:"expires_in"` // 过期时间（秒）
 }
 
-func (j *JWT) GenerateTokenPair(userID uint, username, email string) (*TokenPair, error) {
-	accessToken, err := j.GenerateToken(userID, username, email)
+// GenerateTokenPair 生成Token对（access + refresh）
+func (j *JWT) GenerateTokenPair(userID uint, username, email string, role int, version int64, family string, mfa bool) (*TokenPair, error) {
+	accessToken, err := j.GenerateToken(userID, username, email, role, version, family, mfa)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := j.GenerateRefreshToken(userID, username, email)
+	refreshToken, err := j.GenerateRefreshToken(userID, username, email, role, version, family, mfa)
 	if err != nil {
 		return nil, err
 	}
@@ -118,11 +146,35 @@ func (j *JWT) GenerateTokenPair(userID uint, username, email string) (*TokenPair
 	}, nil
 }
 
-// ParseToken 解析Token
+// AccessTTL 访问Token有效期
+func (j *JWT) AccessTTL() time.Duration {
+	return time.Duration(j.expireHours) * time.Hour
+}
+
+// RefreshTTL 刷新Token有效期
+func (j *JWT) RefreshTTL() time.Duration {
+	return time.Duration(j.refreshHours) * time.Hour
+}
+
+// ParseToken 解析访问Token，刷新Token不能用于访问接口
 func (j *JWT) ParseToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return j.secretKey, nil
-	})
+	return j.parse(tokenString, SubjectAccess)
+}
+
+// ParseRefreshToken 解析刷新Token，访问Token不能用于刷新
+func (j *JWT) ParseRefreshToken(tokenString string) (*Claims, error) {
+	return j.parse(tokenString, SubjectRefresh)
+}
+
+// parse 解析Token并校验类型
+func (j *JWT) parse(tokenString, subject string) (*Claims, error) {
+	ks, err := currentKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	// 按 kid 选择验签密钥
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, ks.verifyKey)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -132,19 +184,81 @@ func (j *JWT) ParseToken(tokenString string) (*Claims, error) {
 	}
 
 	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
+	if !ok || !token.Valid || claims.Subject != subject {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// ActionClaims 一次性操作Token载荷（邮箱验证、重置密码、两步验证登录）
+// Binding 为签发时用户状态的摘要，状态变化（邮箱已验证、密码已修改）后 Token 随之失效，从而只能使用一次
+type ActionClaims struct {
+	UserID  uint   `json:"user_id"`
+	Email   string `json:"email"`
+	Binding string `json:"bind,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// GenerateActionToken 生成一次性操作Token，subject 为 SubjectEmailVerify / SubjectPasswordReset / SubjectTwoFactor
+func GenerateActionToken(subject string, userID uint, email, binding string, ttl time.Duration) (string, error) {
+	nowTime := time.Now()
+	claims := ActionClaims{
+		UserID:  userID,
+		Email:   email,
+		Binding: binding,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newTokenID(),
+			ExpiresAt: jwt.NewNumericDate(nowTime.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(nowTime),
+			Issuer:    "gomall",
+			Subject:   subject,
+		},
+	}
+
+	return sign(claims)
+}
+
+// ParseActionToken 解析一次性操作Token并校验类型
+func ParseActionToken(tokenString, subject string) (*ActionClaims, error) {
+	ks, err := currentKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &ActionClaims{}, ks.verifyKey)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*ActionClaims)
+	if !ok || !token.Valid || claims.Subject != subject {
 		return nil, ErrInvalidToken
 	}
 
 	return claims, nil
 }
 
-// RefreshToken 刷新Token
-func (j *JWT) RefreshToken(tokenString string) (string, error) {
-	claims, err := j.ParseToken(tokenString)
+// sign 使用当前签名密钥签名
+func sign(claims jwt.Claims) (string, error) {
+	ks, err := currentKeys()
 	if err != nil {
 		return "", err
 	}
+	return ks.sign(claims)
+}
+
+// NewFamilyID 生成刷新Token轮换家族ID
+func NewFamilyID() string {
+	return newTokenID()
+}
 
-	return j.GenerateToken(claims.UserID, claims.Username, claims.Email)
+// newTokenID 生成 Token 唯一标识（jti），用于退出登录时吊销单个 Token
+func newTokenID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
 }
@@ -0,0 +1,249 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gomall/backend/internal/config"
+	"gomall/backend/internal/security"
+)
+
+// 签名密钥
+// 未配置 jwt.keys 时使用 HS256 + jwt.secret（单体部署，兼容旧版本）。
+// 配置 jwt.keys 后使用非对称签名，算法由密钥类型决定（RSA -> RS256，Ed25519 -> EdDSA）：
+// - jwt.signing_key_id 指定签名用的密钥（默认第一个带私钥的密钥），Token 头部写入 kid
+// - 其余密钥只用于验签，轮换时先加入新密钥、切换签名密钥，旧 Token 全部过期后再移除旧密钥
+// - 同时配置了 jwt.secret 时，没有 kid 的旧 HS256 Token 仍可验签，迁移完成后删除 secret 即可
+// 公钥通过 /.well-known/jwks.json 发布，其他服务只需公钥即可验签
+
+// ErrUnknownKey Token 的 kid 没有对应的验签密钥
+var ErrUnknownKey = errors.New("未知的签名密钥")
+
+// signingKey 签名/验签密钥
+type signingKey struct {
+	id         string
+	method     jwt.SigningMethod
+	privateKey interface{} // 签名用，只用于验签的密钥为 nil
+	publicKey  interface{} // 验签用
+}
+
+// keySet 当前进程使用的密钥集合
+type keySet struct {
+	signing *signingKey
+	byID    map[string]*signingKey
+}
+
+// keyConfig jwt.keys 配置项
+type keyConfig struct {
+	ID             string `mapstructure:"id"`
+	PrivateKeyPath string `mapstructure:"private_key_path"`
+	PublicKeyPath  string `mapstructure:"public_key_path"`
+}
+
+var (
+	keysOnce   sync.Once
+	loadedKeys *keySet
+	keysErr    error
+)
+
+// LoadKeys 加载签名密钥，应在启动时调用以便尽早发现配置错误
+// 未调用时在第一次签发或解析 Token 时加载
+func LoadKeys() error {
+	_, err := currentKeys()
+	return err
+}
+
+// currentKeys 获取已加载的密钥集合
+func currentKeys() (*keySet, error) {
+	keysOnce.Do(func() {
+		loadedKeys, keysErr = loadKeySet()
+	})
+	return loadedKeys, keysErr
+}
+
+// loadKeySet 根据配置加载密钥集合
+func loadKeySet() (*keySet, error) {
+	jwtConfig := config.GetJWT()
+
+	var configs []keyConfig
+	if err := jwtConfig.UnmarshalKey("keys", &configs); err != nil {
+		return nil, fmt.Errorf("解析 jwt.keys 失败: %w", err)
+	}
+	// 兼容旧配置：只配置了 private_key_path
+	if len(configs) == 0 && jwtConfig.GetString("private_key_path") != "" {
+		configs = append(configs, keyConfig{PrivateKeyPath: jwtConfig.GetString("private_key_path")})
+	}
+
+	ks := &keySet{byID: make(map[string]*signingKey)}
+	secret := jwtConfig.GetString("secret")
+	if secret != "" || len(configs) == 0 {
+		// 复用 security 包的密钥加载逻辑：支持环境变量覆盖，未配置时生成随机密钥并告警
+		secretConfig := &security.JWTConfig{Secret: secret}
+		if err := security.LoadJWTSecret(secretConfig); err != nil {
+			return nil, err
+		}
+		ks.byID[""] = &signingKey{
+			method:     jwt.SigningMethodHS256,
+			privateKey: []byte(secretConfig.Secret),
+			publicKey:  []byte(secretConfig.Secret),
+		}
+	}
+	if len(configs) == 0 {
+		ks.signing = ks.byID[""]
+		return ks, nil
+	}
+
+	signingID := jwtConfig.GetString("signing_key_id")
+	for _, kc := range configs {
+		key, err := loadKey(kc)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := ks.byID[key.id]; exists {
+			return nil, fmt.Errorf("JWT密钥ID重复: %s", key.id)
+		}
+		ks.byID[key.id] = key
+
+		if ks.signing == nil && key.privateKey != nil && (signingID == "" || key.id == signingID) {
+			ks.signing = key
+		}
+	}
+	if ks.signing == nil {
+		return nil, fmt.Errorf("未找到签名密钥 %q（签名密钥需要配置 private_key_path）", signingID)
+	}
+	return ks, nil
+}
+
+// loadKey 从 PEM 文件加载 RSA 或 Ed25519 密钥
+// 私钥支持 PKCS#1 / PKCS#8，公钥支持 PKIX 公钥和 X.509 证书（security.GenerateRSAKeyPair 生成的格式）
+func loadKey(kc keyConfig) (*signingKey, error) {
+	key := &signingKey{id: kc.ID}
+
+	if kc.PrivateKeyPath != "" {
+		data, err := os.ReadFile(kc.PrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("读取JWT私钥失败: %w", err)
+		}
+		if rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			key.method, key.privateKey, key.publicKey = jwt.SigningMethodRS256, rsaKey, &rsaKey.PublicKey
+		} else if edKey, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			key.method, key.privateKey, key.publicKey = jwt.SigningMethodEdDSA, edKey, edKey.(ed25519.PrivateKey).Public()
+		} else {
+			return nil, fmt.Errorf("JWT私钥 %s 不是 RSA 或 Ed25519 私钥", kc.PrivateKeyPath)
+		}
+	} else if kc.PublicKeyPath != "" {
+		data, err := os.ReadFile(kc.PublicKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("读取JWT公钥失败: %w", err)
+		}
+		if rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+			key.method, key.publicKey = jwt.SigningMethodRS256, rsaKey
+		} else if edKey, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+			key.method, key.publicKey = jwt.SigningMethodEdDSA, edKey
+		} else {
+			return nil, fmt.Errorf("JWT公钥 %s 不是 RSA 或 Ed25519 公钥", kc.PublicKeyPath)
+		}
+	} else {
+		return nil, errors.New("jwt.keys 的每一项都需要配置 private_key_path 或 public_key_path")
+	}
+
+	if key.id == "" {
+		der, err := x509.MarshalPKIXPublicKey(key.publicKey)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(der)
+		key.id = hex.EncodeToString(sum[:8])
+	}
+	return key, nil
+}
+
+// verifyKey 按 Token 头部的 kid 选择验签密钥，并要求签名算法与密钥类型一致
+func (ks *keySet) verifyKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.byID[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("签名算法 %s 与密钥不匹配", token.Method.Alg())
+	}
+	return key.publicKey, nil
+}
+
+// sign 使用当前签名密钥签名
+func (ks *keySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.method, claims)
+	if ks.signing.id != "" {
+		token.Header["kid"] = ks.signing.id
+	}
+	return token.SignedString(ks.signing.privateKey)
+}
+
+// JWK JSON Web Key（RFC 7517）
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA 模数
+	E   string `json:"e,omitempty"`   // RSA 指数
+	Crv string `json:"crv,omitempty"` // OKP 曲线
+	X   string `json:"x,omitempty"`   // OKP 公钥
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS 导出全部非对称验签公钥，HS256 密钥不会导出
+func PublicJWKS() (*JWKS, error) {
+	ks, err := currentKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(ks.byID))
+	for id := range ks.byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jwks := &JWKS{Keys: []JWK{}}
+	for _, id := range ids {
+		key := ks.byID[id]
+		switch pub := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.id,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.id,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return jwks, nil
+}
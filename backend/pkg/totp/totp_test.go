@@ -0,0 +1,108 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret RFC 6238 附录 B 的 SHA-1 密钥 "12345678901234567890" 的 Base32 编码
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// RFC 6238 附录 B 的 SHA-1 测试向量，8 位验证码取后 6 位
+func TestCodeRFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},          // 94287082
+		{1111111109, "081804"},  // 07081804
+		{1111111111, "050471"},  // 14050471
+		{1234567890, "005924"},  // 89005924
+		{2000000000, "279037"},  // 69279037
+		{20000000000, "353130"}, // 65353130
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(%d) error: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeNormalizesSecret(t *testing.T) {
+	want, _ := Code(rfcSecret, 1)
+	got, err := Code(" "+strings.ToLower(rfcSecret)+" ", 1)
+	if err != nil || got != want {
+		t.Fatalf("Code with lower-case secret = %q, %v; want %q", got, err, want)
+	}
+}
+
+func TestCodeInvalidSecret(t *testing.T) {
+	if _, err := Code("not-base32!", 1); err != ErrInvalidSecret {
+		t.Fatalf("Code error = %v, want ErrInvalidSecret", err)
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	tests := []struct {
+		name   string
+		step   int64
+		skew   int
+		wantOK bool
+	}{
+		{"current step", current, 0, true},
+		{"previous step without skew", current - 1, 0, false},
+		{"previous step within skew", current - 1, 1, true},
+		{"next step within skew", current + 1, 1, true},
+		{"two steps behind", current - 2, 1, false},
+		{"two steps ahead", current + 2, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _ := Code(rfcSecret, tt.step)
+			step, ok := Validate(rfcSecret, code, now, tt.skew)
+			if ok != tt.wantOK {
+				t.Fatalf("Validate ok = %v, want %v", ok, tt.wantOK)
+			}
+			// 返回匹配的时间步，调用方据此拒绝重放
+			if ok && step != tt.step {
+				t.Fatalf("Validate step = %d, want %d", step, tt.step)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsMalformedCode(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "94287082"} {
+		if _, ok := Validate(rfcSecret, code, now, 1); ok {
+			t.Errorf("Validate(%q) ok = true, want false", code)
+		}
+	}
+}
+
+// 同一验证码在相邻时间步内再次提交时返回同一时间步，
+// 服务端只接受大于上次已用时间步的验证码（ConsumeTOTPStep），重放会被拒绝
+func TestValidateReplayReturnsSameStep(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, _ := Code(rfcSecret, Step(now))
+
+	first, ok := Validate(rfcSecret, code, now, 1)
+	if !ok {
+		t.Fatal("first Validate failed")
+	}
+	second, ok := Validate(rfcSecret, code, now.Add(Period*time.Second), 1)
+	if !ok {
+		t.Fatal("replayed code within skew should still match")
+	}
+	if second != first {
+		t.Fatalf("replayed step = %d, want %d", second, first)
+	}
+}
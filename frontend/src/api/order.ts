@@ -4,6 +4,7 @@ import { ApiResponse } from './types';
 export interface Order {
   id: number;
   order_no: string;
+  shop_id: number;
   product_id: number;
   product_name: string;
   product_image: string;
@@ -12,6 +13,22 @@ export interface Order {
   status: number;
   pay_type: number;
   created_at: string;
+  order_type: number; // 1 普通 2 秒杀 3 合并订单（购物车结算）
+  parent_id: number;
+  discount_amount: number;
+  parent_order_no?: string; // 店铺子订单所属的合并订单，支付和取消使用合并订单
+  children?: Order[]; // 合并订单的店铺子订单
+  items?: OrderItem[]; // 店铺子订单的商品明细
+}
+
+export interface OrderItem {
+  product_id: number;
+  product_name: string;
+  product_image: string;
+  price: number;
+  quantity: number;
+  discount_amount: number;
+  pay_amount: number;
 }
 
 export interface CreateOrderParams {
@@ -23,7 +40,7 @@ export const orderApi = {
   create: (data: CreateOrderParams) =>
     api.post<ApiResponse<Order>>('/order', data),
   checkout: () =>
-    api.post<ApiResponse<Order[]>>('/order/checkout'),
+    api.post<ApiResponse<Order>>('/order/checkout'),
   getList: () =>
     api.get<ApiResponse<{ list: Order[]; total: number }>>('/order'),
   getDetail: (order_no: string) =>
@@ -3,6 +3,7 @@ import { ApiResponse, PaginatedResponse } from './types';
 
 export interface Product {
   id: number;
+  shop_id: number; // 0 为平台自营
   name: string;
   description: string;
   price: number;
@@ -18,6 +19,7 @@ export interface ProductListParams {
   page_size?: number;
   category?: string;
   keyword?: string;
+  shop_id?: number;
 }
 
 export const productApi = {
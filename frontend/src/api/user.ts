@@ -5,9 +5,14 @@ import { ApiResponse } from './types';
 export interface User {
   id: number;
   username: string;
+  nickname: string;
+  avatar: string;
   email: string;
   phone: string;
   role: number;
+  status: number; // 1 正常 2 已禁用 3 已锁定（需重置密码）
+  email_verified: boolean;
+  phone_verified: boolean;
   created_at: string;
 }
 
@@ -28,11 +33,100 @@ export interface ChangePasswordParams {
   new_password: string;
 }
 
+export interface PhoneCodeParams {
+  phone: string;
+  code: string;
+}
+
+export interface ResetPasswordParams {
+  token: string;
+  new_password: string;
+}
+
+// refresh_token 每次刷新后都会换新，旧的不能再使用
+export interface TokenPair {
+  token: string;
+  refresh_token: string;
+  expires_in: number;
+}
+
+// 账号启用了两步验证时，登录接口只返回预认证Token，需再调用 loginTwoFactor 提交动态验证码
+export interface TwoFactorChallenge {
+  two_factor_required: true;
+  pre_auth_token: string;
+  expires_in: number;
+}
+
+export type LoginResult = (TokenPair & { user: User; two_factor_required?: undefined }) | TwoFactorChallenge;
+
+export interface TwoFactorStatus {
+  enabled: boolean;
+  required: boolean;
+  enabled_at?: string;
+  backup_codes_remaining: number;
+}
+
+export interface TwoFactorSetup {
+  secret: string;
+  uri: string; // otpauth:// 链接，渲染为二维码
+}
+
+export interface Session {
+  id: number;
+  device: string;
+  user_agent: string;
+  ip: string;
+  created_at: string;
+  last_seen_at: string;
+  expires_at: string;
+  current: boolean;
+}
+
+export interface UpdateProfileParams {
+  nickname?: string;
+  avatar?: string; // 上传接口返回的地址
+  email?: string;
+  password?: string; // 修改邮箱时需要当前密码
+  phone?: string;
+  phone_code?: string;
+}
+
+export interface DeleteAccountParams {
+  password?: string;
+  sms_code?: string; // 未提供密码时使用已绑定手机号的登录验证码
+  two_factor_code?: string;
+}
+
 export const userApi = {
-  login: (data: LoginParams) => api.post<ApiResponse<{ token: string; user: User }>>('/user/login', data),
-  register: (data: RegisterParams) => api.post<ApiResponse<{ token: string; user: User }>>('/user/register', data),
+  login: (data: LoginParams) => api.post<ApiResponse<LoginResult>>('/user/login', data),
+  loginTwoFactor: (preAuthToken: string, code: string) =>
+    api.post<ApiResponse<TokenPair & { user: User }>>('/user/login/2fa', { pre_auth_token: preAuthToken, code }),
+  register: (data: RegisterParams) => api.post<ApiResponse<TokenPair & { user: User }>>('/user/register', data),
   getProfile: () => api.get<ApiResponse<User>>('/user/profile'),
-  changePassword: (data: ChangePasswordParams) => api.post<ApiResponse<null>>('/auth/change-password', data),
-  logout: () => api.post<ApiResponse<null>>('/auth/logout'),
-  refreshToken: () => api.post<ApiResponse<{ token: string }>>('/auth/refresh-token'),
+  updateProfile: (data: UpdateProfileParams) => api.put<ApiResponse<User>>('/user/profile', data),
+  deleteAccount: (data: DeleteAccountParams) => api.delete<ApiResponse<null>>('/user/account', { data }),
+  sendLoginCode: (phone: string) => api.post<ApiResponse<null>>('/user/sms/code', { phone }),
+  loginByCode: (data: PhoneCodeParams) =>
+    api.post<ApiResponse<LoginResult & { created: boolean }>>('/user/sms/login', data),
+  sendBindCode: (phone: string) => api.post<ApiResponse<null>>('/user/phone/code', { phone }),
+  bindPhone: (data: PhoneCodeParams) => api.put<ApiResponse<User>>('/user/phone', data),
+  getSessions: () => api.get<ApiResponse<Session[]>>('/user/sessions'),
+  deleteSession: (id: number) => api.delete<ApiResponse<null>>(`/user/sessions/${id}`),
+  changePassword: (data: ChangePasswordParams) => api.post<ApiResponse<TokenPair>>('/auth/change-password', data),
+  logout: (refreshToken?: string) => api.post<ApiResponse<null>>('/auth/logout', { refresh_token: refreshToken }),
+  logoutAll: () => api.post<ApiResponse<null>>('/auth/logout-all'),
+  sendVerificationEmail: () => api.post<ApiResponse<null>>('/auth/email/verification'),
+  verifyEmail: (token: string) => api.post<ApiResponse<null>>('/auth/email/verify', { token }),
+  forgotPassword: (email: string) => api.post<ApiResponse<null>>('/auth/password/forgot', { email }),
+  resetPassword: (data: ResetPasswordParams) => api.post<ApiResponse<null>>('/auth/password/reset', data),
+  getTwoFactorStatus: () => api.get<ApiResponse<TwoFactorStatus>>('/auth/2fa'),
+  setupTwoFactor: () => api.post<ApiResponse<TwoFactorSetup>>('/auth/2fa/setup'),
+  enableTwoFactor: (code: string) =>
+    api.post<ApiResponse<TokenPair & { backup_codes: string[] }>>('/auth/2fa/enable', { code }),
+  disableTwoFactor: (password: string, code: string) =>
+    api.post<ApiResponse<null>>('/auth/2fa/disable', { password, code }),
+  regenerateBackupCodes: (code: string) =>
+    api.post<ApiResponse<{ backup_codes: string[] }>>('/auth/2fa/backup-codes', { code }),
+  refreshToken: (refreshToken: string) =>
+    api.post<ApiResponse<TokenPair>>('/auth/refresh-token', { refresh_token: refreshToken }),
 };
@@ -71,7 +71,7 @@ export default function Cart() {
     try {
       const res = await import('../api/order').then(m => m.orderApi.checkout()) as any;
       if (res.code === 0) {
-        toast.success(`结算成功，生成 ${res.data.length} 个订单`, { id: toastId });
+        toast.success(`结算成功，共 ${res.data.children?.length ?? 1} 个店铺订单`, { id: toastId });
         clearCart(); // Local clear
         navigate('/orders');
       } else {
@@ -14,6 +14,9 @@ export default function Login() {
     username: '',
     password: '',
   });
+  // 账号启用了两步验证时，第一步登录返回预认证Token
+  const [preAuthToken, setPreAuthToken] = useState('');
+  const [twoFactorCode, setTwoFactorCode] = useState('');
 
   const handleSubmit = async (e: React.FormEvent) => {
     e.preventDefault();
@@ -22,6 +25,11 @@ export default function Login() {
     if (isSubmitting) return;
     setIsSubmitting(true);
 
+    if (preAuthToken) {
+      await submitTwoFactor();
+      return;
+    }
+
     if (!formData.username || !formData.password) {
       setIsSubmitting(false);
       toast.error('请填写完整的登录信息');
@@ -36,7 +44,10 @@ export default function Login() {
       console.log('登录响应数据:', responseData);
 
       if (responseData.code === 0) {
-        if (responseData.data?.user && responseData.data?.token) {
+        if (responseData.data?.two_factor_required) {
+          setPreAuthToken(responseData.data.pre_auth_token);
+          toast('请输入验证器中的动态验证码');
+        } else if (responseData.data?.user && responseData.data?.token) {
           setAuth(responseData.data.user, responseData.data.token);
           toast.success('登录成功！');
           navigate('/');
@@ -56,6 +67,38 @@ export default function Login() {
     }
   };
 
+  const submitTwoFactor = async () => {
+    if (!twoFactorCode) {
+      setIsSubmitting(false);
+      toast.error('请输入动态验证码或备用码');
+      return;
+    }
+
+    setLoading(true);
+    try {
+      const responseData = await userApi.loginTwoFactor(preAuthToken, twoFactorCode);
+      if (responseData.code === 0 && responseData.data) {
+        setAuth(responseData.data.user, responseData.data.token);
+        toast.success('登录成功！');
+        navigate('/');
+      } else {
+        toast.error(responseData.message || '验证失败');
+      }
+    } catch (error: unknown) {
+      const err = error as Error;
+      toast.error(err.message || '验证失败，请稍后重试');
+    } finally {
+      setIsSubmitting(false);
+      setLoading(false);
+    }
+  };
+
+  // 预认证Token过期后回到第一步重新输入密码
+  const resetTwoFactor = () => {
+    setPreAuthToken('');
+    setTwoFactorCode('');
+  };
+
   const handleChange = (e: React.ChangeEvent<HTMLInputElement>) => {
     setFormData({ ...formData, [e.target.name]: e.target.value });
   };
@@ -69,40 +112,62 @@ export default function Login() {
         </div>
 
         <form onSubmit={handleSubmit} className={styles.form}>
-          <div className="input-group">
-            <label htmlFor="username">用户名</label>
-            <input
-              type="text"
-              id="username"
-              name="username"
-              placeholder="请输入用户名"
-              value={formData.username}
-              onChange={handleChange}
-            />
-          </div>
-
-          <div className="input-group">
-            <label htmlFor="password">密码</label>
-            <input
-              type="password"
-              id="password"
-              name="password"
-              placeholder="请输入密码"
-              value={formData.password}
-              onChange={handleChange}
-            />
-          </div>
+          {preAuthToken ? (
+            <div className="input-group">
+              <label htmlFor="twoFactorCode">两步验证码</label>
+              <input
+                type="text"
+                id="twoFactorCode"
+                name="twoFactorCode"
+                placeholder="请输入 6 位动态验证码或备用码"
+                autoComplete="one-time-code"
+                value={twoFactorCode}
+                onChange={(e) => setTwoFactorCode(e.target.value)}
+              />
+            </div>
+          ) : (
+            <>
+              <div className="input-group">
+                <label htmlFor="username">用户名</label>
+                <input
+                  type="text"
+                  id="username"
+                  name="username"
+                  placeholder="请输入用户名"
+                  value={formData.username}
+                  onChange={handleChange}
+                />
+              </div>
+
+              <div className="input-group">
+                <label htmlFor="password">密码</label>
+                <input
+                  type="password"
+                  id="password"
+                  name="password"
+                  placeholder="请输入密码"
+                  value={formData.password}
+                  onChange={handleChange}
+                />
+              </div>
+            </>
+          )}
 
           <button
             type="submit"
             className={`btn btn-primary w-full ${styles.submit}`}
             disabled={loading || isSubmitting}
           >
-            {loading || isSubmitting ? '登录中...' : '登录'}
+            {loading || isSubmitting ? '登录中...' : preAuthToken ? '验证' : '登录'}
           </button>
         </form>
 
         <div className={styles.footer}>
+          {preAuthToken && (
+            <p>
+              验证超时？<a onClick={resetTwoFactor}>重新登录</a>
+            </p>
+          )}
           <p>
             还没有账号？<Link to="/register">立即注册</Link>
           </p>